@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GlobalUnifiIPPoolSpec defines the desired state of GlobalUnifiIPPool.
+type GlobalUnifiIPPoolSpec struct {
+	// UnifiIPPoolSpec holds the pool configuration shared with UnifiIPPool
+	// InstanceRef.Namespace is required since the pool is cluster-scoped
+	UnifiIPPoolSpec `json:",inline"`
+
+	// AllowedNamespaces lists the namespaces whose claims may allocate from the pool
+	// If both AllowedNamespaces and NamespaceSelector are empty, all namespaces are allowed
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// NamespaceSelector selects namespaces whose claims may allocate from the pool
+	// A namespace is allowed if it is listed in AllowedNamespaces or matches the selector
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=globalunifiippools,scope=Cluster,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Network",type="string",JSONPath=".status.networkInfo.name",description="Unifi network name"
+// +kubebuilder:printcolumn:name="CIDR",type="string",JSONPath=".spec.subnets[0].cidr",description="Network CIDR"
+// +kubebuilder:printcolumn:name="Used",type="integer",JSONPath=".status.addresses.used",description="Allocated IPs"
+// +kubebuilder:printcolumn:name="Free",type="integer",JSONPath=".status.addresses.free",description="Available IPs"
+// +kubebuilder:printcolumn:name="Utilization",type="string",JSONPath=".status.capacity.utilizationPercent",description="Pool utilization %"
+// +kubebuilder:printcolumn:name="Synced",type="string",JSONPath=".status.conditions[?(@.type=='NetworkSynced')].status",description="Network sync status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time since creation"
+
+// GlobalUnifiIPPool is the Schema for the globalunifiippools API.
+// It is a cluster-scoped pool that claims from any allowed namespace can reference.
+type GlobalUnifiIPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GlobalUnifiIPPoolSpec `json:"spec"`
+	Status UnifiIPPoolStatus     `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GlobalUnifiIPPoolList contains a list of GlobalUnifiIPPool.
+type GlobalUnifiIPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GlobalUnifiIPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GlobalUnifiIPPool{}, &GlobalUnifiIPPoolList{})
+}
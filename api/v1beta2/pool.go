@@ -0,0 +1,77 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// UnifiIPPoolKind is the kind of the namespaced pool.
+	UnifiIPPoolKind = "UnifiIPPool"
+
+	// GlobalUnifiIPPoolKind is the kind of the cluster-scoped pool.
+	GlobalUnifiIPPoolKind = "GlobalUnifiIPPool"
+)
+
+// GenericUnifiIPPool is implemented by UnifiIPPool and GlobalUnifiIPPool so that
+// allocation and status handling can be shared between both kinds.
+// +kubebuilder:object:generate=false
+type GenericUnifiIPPool interface {
+	client.Object
+	// PoolKind returns the kind used in IPAddressClaim pool references.
+	PoolKind() string
+	// PoolSpec returns the pool configuration.
+	PoolSpec() *UnifiIPPoolSpec
+	// PoolStatus returns the pool status.
+	PoolStatus() *UnifiIPPoolStatus
+}
+
+var (
+	_ GenericUnifiIPPool = &UnifiIPPool{}
+	_ GenericUnifiIPPool = &GlobalUnifiIPPool{}
+)
+
+// PoolKind implements GenericUnifiIPPool.
+func (p *UnifiIPPool) PoolKind() string {
+	return UnifiIPPoolKind
+}
+
+// PoolSpec implements GenericUnifiIPPool.
+func (p *UnifiIPPool) PoolSpec() *UnifiIPPoolSpec {
+	return &p.Spec
+}
+
+// PoolStatus implements GenericUnifiIPPool.
+func (p *UnifiIPPool) PoolStatus() *UnifiIPPoolStatus {
+	return &p.Status
+}
+
+// PoolKind implements GenericUnifiIPPool.
+func (p *GlobalUnifiIPPool) PoolKind() string {
+	return GlobalUnifiIPPoolKind
+}
+
+// PoolSpec implements GenericUnifiIPPool.
+func (p *GlobalUnifiIPPool) PoolSpec() *UnifiIPPoolSpec {
+	return &p.Spec.UnifiIPPoolSpec
+}
+
+// PoolStatus implements GenericUnifiIPPool.
+func (p *GlobalUnifiIPPool) PoolStatus() *UnifiIPPoolStatus {
+	return &p.Status
+}
@@ -40,6 +40,11 @@ type UnifiInstanceSpec struct {
 	// Insecure allows insecure HTTPS connections (skip TLS verification)
 	// +optional.
 	Insecure *bool `json:"insecure,omitempty"`
+
+	// DryRun makes the provider compute allocations and repairs without writing to
+	// the Unifi controller. Suppressed writes are logged and listed in status.plannedActions
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty"`
 }
 
 // UnifiInstanceStatus defines the observed state of UnifiInstance.
@@ -63,6 +68,39 @@ type UnifiInstanceStatus struct {
 	// FailureMessage provides details about any failure
 	// +optional.
 	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// DryRun is true while writes to the Unifi controller are suppressed, either by
+	// Spec.DryRun or by the manager's --dry-run flag
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// PlannedActions lists the most recent writes suppressed in dry-run mode, oldest first
+	// +optional
+	PlannedActions []PlannedUnifiAction `json:"plannedActions,omitempty"`
+}
+
+// PlannedUnifiAction is a write to the Unifi controller suppressed in dry-run mode.
+type PlannedUnifiAction struct {
+	// Time is when the write was planned.
+	Time metav1.Time `json:"time"`
+
+	// Operation is the Unifi API call that was skipped, e.g. CreateUser.
+	Operation string `json:"operation"`
+
+	// MACAddress is the MAC of the Unifi client the write applied to.
+	MACAddress string `json:"macAddress"`
+
+	// Address is the fixed IP the write would have set.
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// NetworkID is the Unifi network the write would have set.
+	// +optional
+	NetworkID string `json:"networkID,omitempty"`
+
+	// Hostname is the hostname the write would have set.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
 }
 
 // +kubebuilder:object:root=true
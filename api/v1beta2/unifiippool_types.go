@@ -41,6 +41,7 @@ type UnifiIPPoolSpec struct {
 	// PreAllocations maps IPAddressClaim names to specific IP addresses
 	// Used for static IP assignment and IP reuse across machine recreation
 	// Takes priority over dynamic allocation
+	// Keys of the form namespace/name match a single namespace and take precedence
 	// Example: {"cluster-control-plane-0": "10.1.40.10"}
 	// +optional
 	PreAllocations map[string]string `json:"preAllocations,omitempty"`
@@ -61,8 +62,217 @@ type UnifiIPPoolSpec struct {
 	// Can be overridden per subnet
 	// +optional
 	DNSServers []string `json:"dnsServers,omitempty"`
+
+	// UserGroup is the name or ID of a Unifi user group applied to every
+	// reservation created from this pool (e.g. to enforce a bandwidth profile)
+	// Resolved and validated against the Unifi controller during pool sync
+	// +optional
+	UserGroup string `json:"userGroup,omitempty"`
+
+	// AllocationStrategy selects how a free address is picked for dynamic allocation
+	// Defaults to FirstFree
+	// +kubebuilder:default=FirstFree
+	// +optional
+	AllocationStrategy AllocationStrategy `json:"allocationStrategy,omitempty"`
+
+	// SpreadClustersAcrossSubnets prefers the subnets holding the fewest addresses of
+	// the claim's cluster, so the machines of a cluster land in different failure domains
+	// The allocation strategy picks the address within the preferred subnets
+	// +optional
+	SpreadClustersAcrossSubnets bool `json:"spreadClustersAcrossSubnets,omitempty"`
+
+	// QuarantinePeriod keeps released addresses out of allocation for this long, so
+	// neighbours with stale ARP entries don't reach a new machine on the old address
+	// Addresses listed in PreAllocations are exempt
+	// +optional
+	QuarantinePeriod *metav1.Duration `json:"quarantinePeriod,omitempty"`
+
+	// Stickiness lets a replacement claim inherit the address of the claim it replaces
+	// Claims are matched by a sticky key taken from the claim's
+	// unifi.ipam.cluster.x-k8s.io/sticky-key annotation or from its labels
+	// +optional
+	Stickiness *StickinessSpec `json:"stickiness,omitempty"`
+
+	// Quotas limit how many addresses claims may hold from the pool
+	// +optional
+	Quotas *QuotaSpec `json:"quotas,omitempty"`
+
+	// Headroom keeps addresses free for claims matching a selector, e.g. control plane
+	// claims, so other claims cannot take the last addresses needed for remediation
+	// +optional
+	Headroom *HeadroomSpec `json:"headroom,omitempty"`
+
+	// UtilizationThresholds override the manager's utilization thresholds for this pool
+	// +optional
+	UtilizationThresholds *UtilizationThresholds `json:"utilizationThresholds,omitempty"`
+
+	// SyncInterval is how often the pool is synced with its Unifi network
+	// Defaults to the manager's --pool-sync-interval; requeues are jittered
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+
+	// Adoption lets claims adopt fixed IPs already reserved in Unifi on the pool's
+	// network instead of allocating new addresses, e.g. for VMs migrated to CAPI.
+	// Claims select a reservation with the unifi.ipam.cluster.x-k8s.io/adopt-mac or
+	// unifi.ipam.cluster.x-k8s.io/adopt-hostname annotation
+	// +optional
+	Adoption *AdoptionSpec `json:"adoption,omitempty"`
+
+	// ReservationSync configures how the Unifi reservations of the pool's addresses are
+	// kept in line with their IPAddresses. Mismatches are only reported by default
+	// +optional
+	ReservationSync *ReservationSyncSpec `json:"reservationSync,omitempty"`
+}
+
+// ReservationSyncSpec configures the repair of Unifi reservations changed outside the provider.
+type ReservationSyncSpec struct {
+	// CorrectMismatches recreates missing reservations and resets reservations whose
+	// fixed IP was changed or disabled, or that were moved to another network, to their
+	// IPAddress. Mismatches are only reported in status otherwise, and fixed IPs held by
+	// another Unifi client are never changed
+	// +optional
+	CorrectMismatches bool `json:"correctMismatches,omitempty"`
+}
+
+// ReservationMismatchReason tells how a Unifi reservation differs from its IPAddress.
+// +kubebuilder:validation:Enum=Missing;IPChanged;FixedIPDisabled;NetworkChanged;UserGroupChanged;IPConflict
+type ReservationMismatchReason string
+
+const (
+	// ReservationMissing means the Unifi client of the address no longer exists.
+	ReservationMissing ReservationMismatchReason = "Missing"
+
+	// ReservationIPChanged means the Unifi client has a different fixed IP.
+	ReservationIPChanged ReservationMismatchReason = "IPChanged"
+
+	// ReservationFixedIPDisabled means the Unifi client no longer uses a fixed IP.
+	ReservationFixedIPDisabled ReservationMismatchReason = "FixedIPDisabled"
+
+	// ReservationNetworkChanged means the Unifi client was moved to another network.
+	ReservationNetworkChanged ReservationMismatchReason = "NetworkChanged"
+
+	// ReservationUserGroupChanged means the Unifi client is not in the pool's user group.
+	ReservationUserGroupChanged ReservationMismatchReason = "UserGroupChanged"
+
+	// ReservationIPConflict means another Unifi client holds the fixed IP of the address.
+	ReservationIPConflict ReservationMismatchReason = "IPConflict"
+)
+
+// AdoptionSpec configures how claims are matched to existing Unifi fixed IPs.
+type AdoptionSpec struct {
+	// MatchHostname also adopts the fixed IP of a Unifi client whose hostname or
+	// name equals the name of the claim or of one of its owners
+	// +optional
+	MatchHostname bool `json:"matchHostname,omitempty"`
 }
 
+// UtilizationThresholds are the utilization percentages at which a pool reports
+// high utilization and near exhaustion.
+type UtilizationThresholds struct {
+	// Warning is the utilization percentage at which Status.Capacity.HighUtilization is set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Warning *int32 `json:"warning,omitempty"`
+
+	// Critical is the utilization percentage at which the NearlyExhausted condition turns
+	// True with reason CriticalUtilization
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Critical *int32 `json:"critical,omitempty"`
+}
+
+// HeadroomSpec reserves free addresses for claims matching a label selector.
+type HeadroomSpec struct {
+	// Reserved is the number of free addresses only matching claims may allocate
+	// +kubebuilder:validation:Minimum=0
+	Reserved int32 `json:"reserved"`
+
+	// Selector matches the labels of the claims that may allocate the reserved addresses
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// QuotaSpec limits the addresses held per consumer of a pool.
+// A claim exceeding any quota is not allocated an address.
+type QuotaSpec struct {
+	// MaxPerCluster is the maximum number of addresses a CAPI cluster may hold
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPerCluster *int32 `json:"maxPerCluster,omitempty"`
+
+	// MaxPerNamespace is the maximum number of addresses claims of a namespace may hold
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPerNamespace *int32 `json:"maxPerNamespace,omitempty"`
+
+	// Selectors limit the addresses held by all claims matching a label selector
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Selectors []SelectorQuota `json:"selectors,omitempty"`
+}
+
+// SelectorQuota limits the addresses held by claims matching a label selector.
+type SelectorQuota struct {
+	// Name identifies the quota in the pool status
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Selector matches the labels of the claims counted against the quota
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Max is the maximum number of addresses the matching claims may hold
+	// +kubebuilder:validation:Minimum=0
+	Max int32 `json:"max"`
+}
+
+// QuotaType is the kind of consumer a quota applies to.
+// +kubebuilder:validation:Enum=Cluster;Namespace;Selector
+type QuotaType string
+
+const (
+	// QuotaTypeCluster counts the addresses of a CAPI cluster.
+	QuotaTypeCluster QuotaType = "Cluster"
+	// QuotaTypeNamespace counts the addresses of a namespace.
+	QuotaTypeNamespace QuotaType = "Namespace"
+	// QuotaTypeSelector counts the addresses of the claims matching a selector quota.
+	QuotaTypeSelector QuotaType = "Selector"
+)
+
+// StickinessSpec configures how claims are matched to the addresses of the claims they replace.
+type StickinessSpec struct {
+	// LabelKeys are claim label keys whose values, joined in order, form the sticky key
+	// of claims without the sticky-key annotation (e.g. cluster name and role)
+	// Claims missing any of the labels get no sticky key
+	// +optional
+	LabelKeys []string `json:"labelKeys,omitempty"`
+
+	// LeaseDuration is how long a released address stays reserved for its sticky key
+	// Defaults to 1h
+	// +optional
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+}
+
+// AllocationStrategy selects how dynamic allocation picks a free address.
+// +kubebuilder:validation:Enum=FirstFree;LastFree;Random;LeastUsedSubnet
+type AllocationStrategy string
+
+const (
+	// AllocationStrategyFirstFree picks the lowest free address of the pool.
+	AllocationStrategyFirstFree AllocationStrategy = "FirstFree"
+
+	// AllocationStrategyLastFree picks the highest free address of the pool.
+	AllocationStrategyLastFree AllocationStrategy = "LastFree"
+
+	// AllocationStrategyRandom picks a random free address of the pool.
+	AllocationStrategyRandom AllocationStrategy = "Random"
+
+	// AllocationStrategyLeastUsedSubnet picks the lowest free address of the subnet
+	// with the fewest addresses in use, so allocations even out across the subnets.
+	AllocationStrategyLeastUsedSubnet AllocationStrategy = "LeastUsedSubnet"
+)
+
 // SubnetSpec defines a subnet configuration.
 // Supports either CIDR notation OR Start/End IP range (mutually exclusive).
 type SubnetSpec struct {
@@ -96,7 +306,7 @@ type SubnetSpec struct {
 	Prefix *int32 `json:"prefix,omitempty"`
 
 	// ExcludeRanges is a list of IP ranges to exclude from allocation
-	// Format: "start-end" (e.g., "192.168.1.1-192.168.1.10")
+	// Entries are a single IP, a CIDR or a "start-end" range (e.g., "192.168.1.1-192.168.1.10")
 	// +optional
 	ExcludeRanges []string `json:"excludeRanges,omitempty"`
 
@@ -108,16 +318,36 @@ type SubnetSpec struct {
 // UnifiIPPoolStatus defines the observed state of UnifiIPPool.
 type UnifiIPPoolStatus struct {
 	// Allocations tracks current IP assignments (claim name → IP address)
+	// GlobalUnifiIPPools key them by namespace/claim name
 	// Automatically populated by watching IPAddress resources
 	// Can be copied to Spec.PreAllocations before cluster upgrades for IP reuse
 	// +optional
 	Allocations map[string]string `json:"allocations,omitempty"`
 
+	// ReleasedAddresses lists released addresses still in quarantine
+	// Populated when Spec.QuarantinePeriod is set
+	// +optional
+	ReleasedAddresses []ReleasedAddress `json:"releasedAddresses,omitempty"`
+
+	// Leases binds addresses to the sticky keys of the claims holding them
+	// Leases of released addresses expire after Spec.Stickiness.LeaseDuration
+	// +optional
+	Leases []AddressLease `json:"leases,omitempty"`
+
+	// QuotaUsage lists the addresses held per consumer for each configured quota
+	// +optional
+	QuotaUsage []QuotaUsage `json:"quotaUsage,omitempty"`
+
 	// DiscoveredNetworkID is the auto-discovered Unifi network ID
 	// Populated by matching configured subnets to Unifi network ranges
 	// +optional
 	DiscoveredNetworkID string `json:"discoveredNetworkID,omitempty"`
 
+	// UserGroupID is the Unifi user group ID resolved from Spec.UserGroup
+	// Applied to the Unifi user of every reservation created from this pool
+	// +optional
+	UserGroupID string `json:"userGroupID,omitempty"`
+
 	// Addresses provides summary statistics about address allocation
 	// +optional
 	Addresses *IPAddressStatusSummary `json:"addresses,omitempty"`
@@ -126,6 +356,16 @@ type UnifiIPPoolStatus struct {
 	// +optional
 	Capacity *PoolCapacity `json:"capacity,omitempty"`
 
+	// ReservationMismatches lists the addresses whose Unifi reservation differs from
+	// the IPAddress and was not repaired during the last sync
+	// +optional
+	ReservationMismatches []ReservationMismatch `json:"reservationMismatches,omitempty"`
+
+	// UsageHistory samples the number of used addresses over time
+	// Bounded in size and used to forecast Capacity.ExhaustedAt
+	// +optional
+	UsageHistory []UsageSample `json:"usageHistory,omitempty"`
+
 	// NetworkInfo contains information about the Unifi network
 	// +optional
 	NetworkInfo *NetworkInfo `json:"networkInfo,omitempty"`
@@ -148,6 +388,53 @@ type UnifiIPPoolStatus struct {
 	ObservedNetworkConfiguration *ObservedNetworkConfig `json:"observedNetworkConfig,omitempty"`
 }
 
+// ReleasedAddress is an address that was released and is in quarantine.
+type ReleasedAddress struct {
+	// Address is the released IP address.
+	Address string `json:"address"`
+
+	// ClaimName is the name of the claim that held the address.
+	// +optional.
+	ClaimName string `json:"claimName,omitempty"`
+
+	// ReleasedAt is when the release was observed.
+	ReleasedAt metav1.Time `json:"releasedAt"`
+}
+
+// QuotaUsage reports the addresses held by one quota consumer.
+type QuotaUsage struct {
+	// Type is the kind of consumer.
+	Type QuotaType `json:"type"`
+
+	// Name identifies the consumer: namespace/cluster for clusters, the namespace
+	// for namespaces and the quota name for selector quotas.
+	Name string `json:"name"`
+
+	// Used is the number of addresses held by the consumer.
+	Used int32 `json:"used"`
+
+	// Limit is the quota of the consumer.
+	Limit int32 `json:"limit"`
+}
+
+// AddressLease binds an address to a sticky key.
+type AddressLease struct {
+	// Key is the sticky key of the claims the address is reserved for.
+	Key string `json:"key"`
+
+	// Address is the leased IP address.
+	Address string `json:"address"`
+
+	// ClaimName is the name of the claim that last held the address.
+	// +optional.
+	ClaimName string `json:"claimName,omitempty"`
+
+	// ExpiresAt is when the lease of a released address expires.
+	// Unset while a claim holds the address.
+	// +optional.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
 // ObservedNetworkConfig represents the network configuration observed from Unifi.
 // This is used to detect configuration drift.
 type ObservedNetworkConfig struct {
@@ -194,6 +481,28 @@ type IPAddressStatusSummary struct {
 	// OutOfRange is the number of addresses allocated outside the pool's range.
 	// +optional.
 	OutOfRange *int32 `json:"outOfRange,omitempty"`
+
+	// Subnets breaks the address statistics down per configured subnet.
+	// +optional.
+	Subnets []SubnetAddressStatus `json:"subnets,omitempty"`
+}
+
+// SubnetAddressStatus provides address statistics for a single subnet of a pool.
+type SubnetAddressStatus struct {
+	// Subnet is the CIDR or start-end range of the subnet.
+	Subnet string `json:"subnet"`
+
+	// Total is the number of allocatable addresses in the subnet.
+	// +optional.
+	Total *int32 `json:"total,omitempty"`
+
+	// Used is the number of addresses of the subnet currently allocated.
+	// +optional.
+	Used *int32 `json:"used,omitempty"`
+
+	// Free is the number of addresses of the subnet available for allocation.
+	// +optional.
+	Free *int32 `json:"free,omitempty"`
 }
 
 // PoolCapacity provides pool utilization metrics.
@@ -203,13 +512,50 @@ type PoolCapacity struct {
 	UtilizationPercent *int32 `json:"utilizationPercent,omitempty"`
 
 	// ExhaustedAt is the projected time when the pool will be exhausted
-	// based on current allocation rate (if available)
+	// based on the allocation rate in Status.UsageHistory (if usage is growing)
 	// +optional.
 	ExhaustedAt *metav1.Time `json:"exhaustedAt,omitempty"`
 
-	// HighUtilization indicates if the pool is nearing capacity (>80%)
+	// HighUtilization indicates if the pool has reached its warning utilization threshold
 	// +optional.
 	HighUtilization *bool `json:"highUtilization,omitempty"`
+
+	// HeadroomReserved is the number of free addresses held back for headroom claims.
+	// +optional.
+	HeadroomReserved *int32 `json:"headroomReserved,omitempty"`
+
+	// GeneralFree is the number of free addresses available to all claims.
+	// +optional.
+	GeneralFree *int32 `json:"generalFree,omitempty"`
+}
+
+// UsageSample is the number of used addresses of a pool at a point in time.
+type UsageSample struct {
+	// Time is when the sample was taken.
+	Time metav1.Time `json:"time"`
+
+	// Used is the number of addresses in use at that time.
+	Used int32 `json:"used"`
+}
+
+// ReservationMismatch is an address whose Unifi reservation differs from its IPAddress.
+type ReservationMismatch struct {
+	// Address is the IP address of the IPAddress.
+	Address string `json:"address"`
+
+	// ClaimName is the name of the claim holding the address.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// MACAddress is the MAC of the Unifi client holding the reservation.
+	MACAddress string `json:"macAddress"`
+
+	// Reason tells how the reservation differs.
+	Reason ReservationMismatchReason `json:"reason"`
+
+	// Observed is the fixed IP, network ID, user group ID or conflicting MAC found in Unifi, if any.
+	// +optional
+	Observed string `json:"observed,omitempty"`
 }
 
 // NetworkInfo contains details about the Unifi network.
@@ -267,6 +613,10 @@ type AllocatedIP struct {
 	// +optional.
 	MacAddress string `json:"macAddress,omitempty"`
 
+	// StickyKey is the sticky key of the claim holding the address
+	// +optional.
+	StickyKey string `json:"stickyKey,omitempty"`
+
 	// AllocatedAt is when this IP was allocated
 	// +optional.
 	AllocatedAt *metav1.Time `json:"allocatedAt,omitempty"`
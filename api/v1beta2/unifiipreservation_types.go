@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UnifiIPReservationSpec defines the desired state of UnifiIPReservation.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type UnifiIPReservationSpec struct {
+	// PoolRef references the UnifiIPPool in the same namespace to reserve from
+	// +kubebuilder:validation:Required
+	PoolRef corev1.LocalObjectReference `json:"poolRef"`
+
+	// Address requests a specific IP address from the pool
+	// If empty, the next free address is reserved
+	// +kubebuilder:validation:Pattern=`^([0-9]{1,3}\.){3}[0-9]{1,3}$`
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Hostname is registered with the Unifi reservation (defaults to the reservation name)
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// MACAddress binds the Unifi reservation to a real device
+	// If empty, a deterministic locally administered MAC is generated
+	// +kubebuilder:validation:Pattern=`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`
+	// +optional
+	MACAddress string `json:"macAddress,omitempty"`
+}
+
+// UnifiIPReservationStatus defines the observed state of UnifiIPReservation.
+type UnifiIPReservationStatus struct {
+	// Ready indicates whether the address is reserved in Unifi.
+	// +optional.
+	Ready *bool `json:"ready,omitempty"`
+
+	// Address is the reserved IP address
+	// +optional.
+	Address string `json:"address,omitempty"`
+
+	// Prefix is the network prefix length of the reserved address
+	// +optional.
+	Prefix *int32 `json:"prefix,omitempty"`
+
+	// Gateway is the gateway of the subnet the address was reserved from
+	// +optional.
+	Gateway string `json:"gateway,omitempty"`
+
+	// MACAddress is the MAC address the Unifi reservation is bound to
+	// +optional.
+	MACAddress string `json:"macAddress,omitempty"`
+
+	// Conditions define the current state of the UnifiIPReservation using metav1.Conditions
+	// +optional.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=unifiipreservations,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Pool",type="string",JSONPath=".spec.poolRef.name",description="UnifiIPPool the address is reserved from"
+// +kubebuilder:printcolumn:name="Address",type="string",JSONPath=".status.address",description="Reserved IP address"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Reservation status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time since creation"
+
+// UnifiIPReservation is the Schema for the unifiipreservations API.
+// It reserves an address from a UnifiIPPool for consumers outside of Cluster API,
+// such as load balancer VIPs or appliances.
+type UnifiIPReservation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UnifiIPReservationSpec   `json:"spec"`
+	Status UnifiIPReservationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// UnifiIPReservationList contains a list of UnifiIPReservation.
+type UnifiIPReservationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UnifiIPReservation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&UnifiIPReservation{}, &UnifiIPReservationList{})
+}
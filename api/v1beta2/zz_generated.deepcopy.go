@@ -25,6 +25,40 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressLease) DeepCopyInto(out *AddressLease) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressLease.
+func (in *AddressLease) DeepCopy() *AddressLease {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressLease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptionSpec) DeepCopyInto(out *AdoptionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptionSpec.
+func (in *AdoptionSpec) DeepCopy() *AdoptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AllocatedIP) DeepCopyInto(out *AllocatedIP) {
 	*out = *in
@@ -89,6 +123,107 @@ func (in *DHCPRangeConfig) DeepCopy() *DHCPRangeConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalUnifiIPPool) DeepCopyInto(out *GlobalUnifiIPPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalUnifiIPPool.
+func (in *GlobalUnifiIPPool) DeepCopy() *GlobalUnifiIPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalUnifiIPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlobalUnifiIPPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalUnifiIPPoolList) DeepCopyInto(out *GlobalUnifiIPPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GlobalUnifiIPPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalUnifiIPPoolList.
+func (in *GlobalUnifiIPPoolList) DeepCopy() *GlobalUnifiIPPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalUnifiIPPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlobalUnifiIPPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalUnifiIPPoolSpec) DeepCopyInto(out *GlobalUnifiIPPoolSpec) {
+	*out = *in
+	in.UnifiIPPoolSpec.DeepCopyInto(&out.UnifiIPPoolSpec)
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalUnifiIPPoolSpec.
+func (in *GlobalUnifiIPPoolSpec) DeepCopy() *GlobalUnifiIPPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalUnifiIPPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeadroomSpec) DeepCopyInto(out *HeadroomSpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeadroomSpec.
+func (in *HeadroomSpec) DeepCopy() *HeadroomSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HeadroomSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPAddressStatusSummary) DeepCopyInto(out *IPAddressStatusSummary) {
 	*out = *in
@@ -112,6 +247,13 @@ func (in *IPAddressStatusSummary) DeepCopyInto(out *IPAddressStatusSummary) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]SubnetAddressStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAddressStatusSummary.
@@ -174,6 +316,22 @@ func (in *ObservedNetworkConfig) DeepCopy() *ObservedNetworkConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedUnifiAction) DeepCopyInto(out *PlannedUnifiAction) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedUnifiAction.
+func (in *PlannedUnifiAction) DeepCopy() *PlannedUnifiAction {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedUnifiAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolCapacity) DeepCopyInto(out *PoolCapacity) {
 	*out = *in
@@ -191,6 +349,16 @@ func (in *PoolCapacity) DeepCopyInto(out *PoolCapacity) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.HeadroomReserved != nil {
+		in, out := &in.HeadroomReserved, &out.HeadroomReserved
+		*out = new(int32)
+		**out = **in
+	}
+	if in.GeneralFree != nil {
+		in, out := &in.GeneralFree, &out.GeneralFree
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolCapacity.
@@ -203,6 +371,170 @@ func (in *PoolCapacity) DeepCopy() *PoolCapacity {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaSpec) DeepCopyInto(out *QuotaSpec) {
+	*out = *in
+	if in.MaxPerCluster != nil {
+		in, out := &in.MaxPerCluster, &out.MaxPerCluster
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPerNamespace != nil {
+		in, out := &in.MaxPerNamespace, &out.MaxPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Selectors != nil {
+		in, out := &in.Selectors, &out.Selectors
+		*out = make([]SelectorQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaSpec.
+func (in *QuotaSpec) DeepCopy() *QuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaUsage) DeepCopyInto(out *QuotaUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaUsage.
+func (in *QuotaUsage) DeepCopy() *QuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleasedAddress) DeepCopyInto(out *ReleasedAddress) {
+	*out = *in
+	in.ReleasedAt.DeepCopyInto(&out.ReleasedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleasedAddress.
+func (in *ReleasedAddress) DeepCopy() *ReleasedAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleasedAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservationMismatch) DeepCopyInto(out *ReservationMismatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationMismatch.
+func (in *ReservationMismatch) DeepCopy() *ReservationMismatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservationMismatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservationSyncSpec) DeepCopyInto(out *ReservationSyncSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationSyncSpec.
+func (in *ReservationSyncSpec) DeepCopy() *ReservationSyncSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservationSyncSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectorQuota) DeepCopyInto(out *SelectorQuota) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectorQuota.
+func (in *SelectorQuota) DeepCopy() *SelectorQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectorQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StickinessSpec) DeepCopyInto(out *StickinessSpec) {
+	*out = *in
+	if in.LabelKeys != nil {
+		in, out := &in.LabelKeys, &out.LabelKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LeaseDuration != nil {
+		in, out := &in.LeaseDuration, &out.LeaseDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StickinessSpec.
+func (in *StickinessSpec) DeepCopy() *StickinessSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StickinessSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetAddressStatus) DeepCopyInto(out *SubnetAddressStatus) {
+	*out = *in
+	if in.Total != nil {
+		in, out := &in.Total, &out.Total
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Free != nil {
+		in, out := &in.Free, &out.Free
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetAddressStatus.
+func (in *SubnetAddressStatus) DeepCopy() *SubnetAddressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetAddressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
 	*out = *in
@@ -320,6 +652,46 @@ func (in *UnifiIPPoolSpec) DeepCopyInto(out *UnifiIPPoolSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.QuarantinePeriod != nil {
+		in, out := &in.QuarantinePeriod, &out.QuarantinePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Stickiness != nil {
+		in, out := &in.Stickiness, &out.Stickiness
+		*out = new(StickinessSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(QuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headroom != nil {
+		in, out := &in.Headroom, &out.Headroom
+		*out = new(HeadroomSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UtilizationThresholds != nil {
+		in, out := &in.UtilizationThresholds, &out.UtilizationThresholds
+		*out = new(UtilizationThresholds)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(AdoptionSpec)
+		**out = **in
+	}
+	if in.ReservationSync != nil {
+		in, out := &in.ReservationSync, &out.ReservationSync
+		*out = new(ReservationSyncSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnifiIPPoolSpec.
@@ -342,6 +714,25 @@ func (in *UnifiIPPoolStatus) DeepCopyInto(out *UnifiIPPoolStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.ReleasedAddresses != nil {
+		in, out := &in.ReleasedAddresses, &out.ReleasedAddresses
+		*out = make([]ReleasedAddress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Leases != nil {
+		in, out := &in.Leases, &out.Leases
+		*out = make([]AddressLease, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QuotaUsage != nil {
+		in, out := &in.QuotaUsage, &out.QuotaUsage
+		*out = make([]QuotaUsage, len(*in))
+		copy(*out, *in)
+	}
 	if in.Addresses != nil {
 		in, out := &in.Addresses, &out.Addresses
 		*out = new(IPAddressStatusSummary)
@@ -352,6 +743,18 @@ func (in *UnifiIPPoolStatus) DeepCopyInto(out *UnifiIPPoolStatus) {
 		*out = new(PoolCapacity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ReservationMismatches != nil {
+		in, out := &in.ReservationMismatches, &out.ReservationMismatches
+		*out = make([]ReservationMismatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsageHistory != nil {
+		in, out := &in.UsageHistory, &out.UsageHistory
+		*out = make([]UsageSample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.NetworkInfo != nil {
 		in, out := &in.NetworkInfo, &out.NetworkInfo
 		*out = new(NetworkInfo)
@@ -390,6 +793,113 @@ func (in *UnifiIPPoolStatus) DeepCopy() *UnifiIPPoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnifiIPReservation) DeepCopyInto(out *UnifiIPReservation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnifiIPReservation.
+func (in *UnifiIPReservation) DeepCopy() *UnifiIPReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(UnifiIPReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UnifiIPReservation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnifiIPReservationList) DeepCopyInto(out *UnifiIPReservationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]UnifiIPReservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnifiIPReservationList.
+func (in *UnifiIPReservationList) DeepCopy() *UnifiIPReservationList {
+	if in == nil {
+		return nil
+	}
+	out := new(UnifiIPReservationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UnifiIPReservationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnifiIPReservationSpec) DeepCopyInto(out *UnifiIPReservationSpec) {
+	*out = *in
+	out.PoolRef = in.PoolRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnifiIPReservationSpec.
+func (in *UnifiIPReservationSpec) DeepCopy() *UnifiIPReservationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UnifiIPReservationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnifiIPReservationStatus) DeepCopyInto(out *UnifiIPReservationStatus) {
+	*out = *in
+	if in.Ready != nil {
+		in, out := &in.Ready, &out.Ready
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnifiIPReservationStatus.
+func (in *UnifiIPReservationStatus) DeepCopy() *UnifiIPReservationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UnifiIPReservationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnifiInstance) DeepCopyInto(out *UnifiInstance) {
 	*out = *in
@@ -463,6 +973,11 @@ func (in *UnifiInstanceSpec) DeepCopyInto(out *UnifiInstanceSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnifiInstanceSpec.
@@ -504,6 +1019,13 @@ func (in *UnifiInstanceStatus) DeepCopyInto(out *UnifiInstanceStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PlannedActions != nil {
+		in, out := &in.PlannedActions, &out.PlannedActions
+		*out = make([]PlannedUnifiAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnifiInstanceStatus.
@@ -515,3 +1037,44 @@ func (in *UnifiInstanceStatus) DeepCopy() *UnifiInstanceStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageSample) DeepCopyInto(out *UsageSample) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageSample.
+func (in *UsageSample) DeepCopy() *UsageSample {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageSample)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UtilizationThresholds) DeepCopyInto(out *UtilizationThresholds) {
+	*out = *in
+	if in.Warning != nil {
+		in, out := &in.Warning, &out.Warning
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Critical != nil {
+		in, out := &in.Critical, &out.Critical
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UtilizationThresholds.
+func (in *UtilizationThresholds) DeepCopy() *UtilizationThresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(UtilizationThresholds)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -33,8 +34,12 @@ import (
 
 	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/controllers"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/health"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/webhooks"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/ipamutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/tracing"
 
 	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
@@ -60,6 +65,25 @@ type managerConfig struct {
 	webhookCertDir       string
 	enableLeaderElection bool
 	watchFilterValue     string
+	warningUtilization   int
+	criticalUtilization  int
+	poolSyncInterval     time.Duration
+	poolSyncJitter       float64
+	tracingEndpoint      string
+	tracingInsecure      bool
+	tracingSampleRatio   float64
+	unifiReadiness       string
+	dryRun               bool
+}
+
+// poolDefaults returns the settings applied to pools that do not configure them.
+func (c *managerConfig) poolDefaults() poolutil.PoolDefaults {
+	return poolutil.PoolDefaults{
+		WarningUtilization:  int32(c.warningUtilization),  //nolint:gosec // G115: percentage flag
+		CriticalUtilization: int32(c.criticalUtilization), //nolint:gosec // G115: percentage flag
+		SyncInterval:        c.poolSyncInterval,
+		SyncJitter:          c.poolSyncJitter,
+	}
 }
 
 func parseFlags() *managerConfig {
@@ -80,6 +104,29 @@ func parseFlags() *managerConfig {
 		"Label value that the controller watches to reconcile cluster-api objects. "+
 			"Label key is always "+clusterv1beta2.WatchLabel+". If unspecified, the controller watches for all cluster-api objects.")
 
+	flag.IntVar(&config.warningUtilization, "pool-warning-utilization", int(poolutil.DefaultWarningUtilization),
+		"Utilization percentage at which pools without utilizationThresholds report high utilization.")
+	flag.IntVar(&config.criticalUtilization, "pool-critical-utilization", int(poolutil.DefaultCriticalUtilization),
+		"Utilization percentage at which pools without utilizationThresholds are reported as nearly exhausted.")
+	flag.DurationVar(&config.poolSyncInterval, "pool-sync-interval", poolutil.DefaultSyncInterval,
+		"How often pools without syncInterval are synced with Unifi.")
+	flag.Float64Var(&config.poolSyncJitter, "pool-sync-jitter", poolutil.DefaultSyncJitter,
+		"Maximum fraction of the sync interval added to each pool sync. 0 disables jitter.")
+
+	flag.StringVar(&config.tracingEndpoint, "tracing-endpoint", "",
+		"The host:port of an OTLP gRPC collector to export traces to. Tracing is disabled if empty.")
+	flag.BoolVar(&config.tracingInsecure, "tracing-insecure", false, "Connect to the OTLP collector without TLS.")
+	flag.Float64Var(&config.tracingSampleRatio, "tracing-sample-ratio", 1,
+		"Fraction of claim and pool reconciles that are traced (0-1).")
+
+	flag.StringVar(&config.unifiReadiness, "unifi-readiness", string(health.ReadinessInformational),
+		"Whether unreachable Unifi controllers fail the readiness probe: "+
+			"\"informational\" only logs them, \"gating\" reports the pod as not ready.")
+
+	flag.BoolVar(&config.dryRun, "dry-run", false,
+		"Compute allocations and repairs without writing to any Unifi controller. "+
+			"Suppressed writes are logged and listed in the status of each UnifiInstance.")
+
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -92,6 +139,19 @@ func parseFlags() *managerConfig {
 func main() {
 	config := parseFlags()
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Endpoint:    config.tracingEndpoint,
+		Insecure:    config.tracingInsecure,
+		SampleRatio: config.tracingSampleRatio,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	if config.tracingEndpoint != "" {
+		setupLog.Info("exporting traces", "endpoint", config.tracingEndpoint)
+	}
+
 	// Build manager options
 	mgrOptions := ctrl.Options{
 		Scheme:                  scheme,
@@ -125,11 +185,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	if config.dryRun {
+		setupLog.Info("dry-run mode enabled - no writes will be made to Unifi controllers")
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	// Flush spans still buffered by the exporter.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "failed to flush traces")
+	}
 }
 
 func setupControllers(mgr ctrl.Manager, config *managerConfig, ctx context.Context) error {
@@ -137,16 +208,48 @@ func setupControllers(mgr ctrl.Manager, config *managerConfig, ctx context.Conte
 	if err := (&controllers.UnifiInstanceReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		DryRun: config.dryRun,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create controller UnifiInstance: %w", err)
 	}
 
 	// Setup UnifiIPPool controller.
 	if err := (&controllers.UnifiIPPoolReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorder("unifiippool-controller"),
+		Defaults: config.poolDefaults(),
+		DryRun:   config.dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller UnifiIPPool: %w", err)
+	}
+
+	// Setup GlobalUnifiIPPool controller for cluster-scoped pools.
+	if err := (&controllers.GlobalUnifiIPPoolReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorder("globalunifiippool-controller"),
+		Defaults: config.poolDefaults(),
+		DryRun:   config.dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller GlobalUnifiIPPool: %w", err)
+	}
+
+	// Setup UnifiIPReservation controller for non-CAPI consumers.
+	if err := (&controllers.UnifiIPReservationReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		DryRun: config.dryRun,
 	}).SetupWithManager(mgr); err != nil {
-		return fmt.Errorf("unable to create controller UnifiIPPool: %w", err)
+		return fmt.Errorf("unable to create controller UnifiIPReservation: %w", err)
+	}
+
+	// Setup control plane VIP controller for annotated Clusters.
+	if err := (&controllers.ClusterVIPReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller ClusterVIP: %w", err)
 	}
 
 	// Setup IPAddressClaim controller with UnifiProviderAdapter.
@@ -154,7 +257,12 @@ func setupControllers(mgr ctrl.Manager, config *managerConfig, ctx context.Conte
 		Client:           mgr.GetClient(),
 		Scheme:           mgr.GetScheme(),
 		WatchFilterValue: config.watchFilterValue,
-		Adapter:          &controllers.UnifiProviderAdapter{Client: mgr.GetClient()},
+		Adapter: &controllers.UnifiProviderAdapter{
+			Client:   mgr.GetClient(),
+			Recorder: mgr.GetEventRecorder("ipaddressclaim-controller"),
+			DryRun:   config.dryRun,
+		},
+		Recorder: mgr.GetEventRecorder("ipaddressclaim-controller"),
 	}).SetupWithManager(ctx, mgr); err != nil {
 		return fmt.Errorf("unable to create controller IPAddressClaim: %w", err)
 	}
@@ -165,6 +273,9 @@ func setupControllers(mgr ctrl.Manager, config *managerConfig, ctx context.Conte
 		if err := (&webhooks.UnifiIPPoolWebhook{}).SetupWebhookWithManager(mgr); err != nil {
 			return fmt.Errorf("unable to create webhook UnifiIPPool: %w", err)
 		}
+		if err := (&webhooks.GlobalUnifiIPPoolWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create webhook GlobalUnifiIPPool: %w", err)
+		}
 		if err := (&webhooks.UnifiInstanceWebhook{}).SetupWebhookWithManager(mgr); err != nil {
 			return fmt.Errorf("unable to create webhook UnifiInstance: %w", err)
 		}
@@ -178,5 +289,25 @@ func setupControllers(mgr ctrl.Manager, config *managerConfig, ctx context.Conte
 		return fmt.Errorf("unable to set up ready check: %w", err)
 	}
 
+	readinessMode, err := health.ParseReadinessMode(config.unifiReadiness)
+	if err != nil {
+		return err
+	}
+	unifiChecker := health.UnifiChecker(unifi.DefaultHealthTracker, readinessMode, ctrl.Log.WithName("readiness"))
+	if err := mgr.AddReadyzCheck("unifi", unifiChecker); err != nil {
+		return fmt.Errorf("unable to set up Unifi ready check: %w", err)
+	}
+	if config.enableWebhook {
+		// Fails until the webhook server serves its certificate.
+		if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			return fmt.Errorf("unable to set up webhook ready check: %w", err)
+		}
+	}
+
+	if err := mgr.AddMetricsServerExtraHandler(health.DebugPath,
+		health.DebugHandler(mgr.GetClient(), unifi.DefaultHealthTracker)); err != nil {
+		return fmt.Errorf("unable to set up %s endpoint: %w", health.DebugPath, err)
+	}
+
 	return nil
 }
@@ -0,0 +1,283 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+	"sigs.k8s.io/cluster-api/util/annotations"
+)
+
+const (
+	// VIPPoolAnnotation is set on a Cluster to reserve a control plane VIP from
+	// the named UnifiIPPool in the Cluster's namespace.
+	VIPPoolAnnotation = "unifi.ipam.cluster.x-k8s.io/vip-pool"
+
+	// VIPPortAnnotation optionally overrides the port published in the Cluster's
+	// control plane endpoint (defaults to DefaultVIPPort).
+	VIPPortAnnotation = "unifi.ipam.cluster.x-k8s.io/vip-port"
+
+	// VIPAddressAnnotation is set by the controller to the reserved VIP so that
+	// kube-vip/keepalived templates can consume it.
+	VIPAddressAnnotation = "unifi.ipam.cluster.x-k8s.io/vip-address"
+
+	// DefaultVIPPort is the API server port published with the VIP.
+	DefaultVIPPort = 6443
+
+	// requestedIPAnnotation is the claim annotation used to request a specific IP.
+	requestedIPAnnotation = "ipAddress"
+
+	vipClaimSuffix = "-control-plane-vip"
+)
+
+// ClusterVIPReconciler reserves control plane VIPs for annotated CAPI Clusters.
+// The VIP is requested through a regular IPAddressClaim owned by the Cluster, so
+// allocation and the Unifi reservation follow the same path as machine addresses.
+type ClusterVIPReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddressclaims,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile ensures the VIP claim for a Cluster and publishes the allocated address.
+func (r *ClusterVIPReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	cluster := &clusterv1beta2.Cluster{}
+	if err := r.Get(ctx, req.NamespacedName, cluster); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !cluster.DeletionTimestamp.IsZero() {
+		// The VIP claim is owned by the Cluster and is garbage collected with it.
+		return ctrl.Result{}, nil
+	}
+
+	if annotations.IsPaused(cluster, cluster) {
+		logger.V(1).Info("cluster is paused, skipping VIP reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	poolName := cluster.Annotations[VIPPoolAnnotation]
+	if poolName == "" {
+		return ctrl.Result{}, r.deleteVIPClaim(ctx, cluster, logger)
+	}
+
+	claim, err := r.ensureVIPClaim(ctx, cluster, poolName, logger)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if claim.Status.AddressRef.Name == "" {
+		logger.V(1).Info("waiting for VIP to be allocated", "claim", claim.Name)
+		return ctrl.Result{}, nil
+	}
+
+	address := &ipamv1beta2.IPAddress{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: claim.Namespace, Name: claim.Status.AddressRef.Name}, address); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	return ctrl.Result{}, r.publishVIP(ctx, cluster, address.Spec.Address, logger)
+}
+
+// ensureVIPClaim creates the VIP claim for the cluster if it does not exist yet.
+func (r *ClusterVIPReconciler) ensureVIPClaim(ctx context.Context, cluster *clusterv1beta2.Cluster, poolName string, logger logr.Logger) (*ipamv1beta2.IPAddressClaim, error) {
+	claim := &ipamv1beta2.IPAddressClaim{}
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: VIPClaimName(cluster.Name)}
+
+	err := r.Get(ctx, key, claim)
+	if err == nil {
+		if claim.Spec.PoolRef.Name != poolName {
+			// Claims are immutable and changing the pool would change the VIP.
+			logger.Info("VIP claim references a different pool than the cluster annotation, delete the claim to move the VIP",
+				"claim", claim.Name, "claimPool", claim.Spec.PoolRef.Name, "annotationPool", poolName)
+		}
+		return claim, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get VIP claim: %w", err)
+	}
+
+	claim = &ipamv1beta2.IPAddressClaim{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels: map[string]string{
+				clusterv1beta2.ClusterNameLabel: cluster.Name,
+			},
+		},
+		Spec: ipamv1beta2.IPAddressClaimSpec{
+			ClusterName: cluster.Name,
+			PoolRef: ipamv1beta2.IPPoolReference{
+				APIGroup: v1beta2.GroupVersion.Group,
+				Kind:     v1beta2.UnifiIPPoolKind,
+				Name:     poolName,
+			},
+		},
+	}
+
+	// Reserve a hand-picked endpoint so it can no longer collide with the pool.
+	if requested := requestedVIP(cluster); requested != "" {
+		claim.Annotations = map[string]string{requestedIPAnnotation: requested}
+	}
+
+	if err := controllerutil.SetControllerReference(cluster, claim, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner reference on VIP claim: %w", err)
+	}
+
+	if err := r.Create(ctx, claim); err != nil {
+		return nil, fmt.Errorf("failed to create VIP claim: %w", err)
+	}
+
+	logger.Info("created control plane VIP claim", "claim", claim.Name, "pool", poolName)
+	return claim, nil
+}
+
+// deleteVIPClaim removes the VIP claim once the cluster is no longer annotated and
+// withdraws the VIP published on the cluster.
+func (r *ClusterVIPReconciler) deleteVIPClaim(ctx context.Context, cluster *clusterv1beta2.Cluster, logger logr.Logger) error {
+	claim := &ipamv1beta2.IPAddressClaim{}
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: VIPClaimName(cluster.Name)}
+	err := r.Get(ctx, key, claim)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	case !metav1.IsControlledBy(claim, cluster):
+		return nil
+	default:
+		logger.Info("deleting control plane VIP claim", "claim", claim.Name)
+		if err := r.Delete(ctx, claim); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return r.unpublishVIP(ctx, cluster, logger)
+}
+
+// unpublishVIP removes the VIP annotation from the cluster and clears the control
+// plane endpoint if it was filled with the VIP.
+func (r *ClusterVIPReconciler) unpublishVIP(ctx context.Context, cluster *clusterv1beta2.Cluster, logger logr.Logger) error {
+	vip, ok := cluster.Annotations[VIPAddressAnnotation]
+	if !ok {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(cluster.DeepCopy())
+	delete(cluster.Annotations, VIPAddressAnnotation)
+	if vip != "" && cluster.Spec.ControlPlaneEndpoint.Host == vip {
+		cluster.Spec.ControlPlaneEndpoint = clusterv1beta2.APIEndpoint{}
+	}
+
+	if err := r.Patch(ctx, cluster, patchBase); err != nil {
+		return fmt.Errorf("failed to withdraw VIP from cluster: %w", err)
+	}
+
+	logger.Info("withdrew control plane VIP", "vip", vip)
+	return nil
+}
+
+// publishVIP records the VIP on the cluster and fills an empty control plane endpoint.
+func (r *ClusterVIPReconciler) publishVIP(ctx context.Context, cluster *clusterv1beta2.Cluster, vip string, logger logr.Logger) error {
+	if vip == "" {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(cluster.DeepCopy())
+	changed := false
+
+	if cluster.Annotations[VIPAddressAnnotation] != vip {
+		if cluster.Annotations == nil {
+			cluster.Annotations = make(map[string]string)
+		}
+		cluster.Annotations[VIPAddressAnnotation] = vip
+		changed = true
+	}
+
+	if cluster.Spec.ControlPlaneEndpoint.Host == "" {
+		cluster.Spec.ControlPlaneEndpoint.Host = vip
+		cluster.Spec.ControlPlaneEndpoint.Port = vipPort(cluster)
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := r.Patch(ctx, cluster, patchBase); err != nil {
+		return fmt.Errorf("failed to publish VIP on cluster: %w", err)
+	}
+
+	logger.Info("published control plane VIP", "vip", vip)
+	return nil
+}
+
+// VIPClaimName returns the name of the IPAddressClaim holding a cluster's VIP.
+func VIPClaimName(clusterName string) string {
+	return clusterName + vipClaimSuffix
+}
+
+// requestedVIP returns the cluster's pre-set control plane host if it is an IP address.
+func requestedVIP(cluster *clusterv1beta2.Cluster) string {
+	host := cluster.Spec.ControlPlaneEndpoint.Host
+	if host == "" {
+		return ""
+	}
+	if _, err := netip.ParseAddr(host); err != nil {
+		return ""
+	}
+	return host
+}
+
+// vipPort returns the API server port to publish with the VIP.
+func vipPort(cluster *clusterv1beta2.Cluster) int32 {
+	if value, ok := cluster.Annotations[VIPPortAnnotation]; ok {
+		if port, err := strconv.ParseInt(value, 10, 32); err == nil && port > 0 && port <= 65535 {
+			return int32(port)
+		}
+	}
+	return DefaultVIPPort
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterVIPReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clustervip").
+		For(&clusterv1beta2.Cluster{}).
+		Owns(&ipamv1beta2.IPAddressClaim{}).
+		Complete(r)
+}
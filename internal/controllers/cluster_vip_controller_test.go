@@ -0,0 +1,151 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func TestRequestedVIP(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{
+			name: "no endpoint",
+			host: "",
+			want: "",
+		},
+		{
+			name: "IP endpoint",
+			host: "10.1.40.5",
+			want: "10.1.40.5",
+		},
+		{
+			name: "hostname endpoint",
+			host: "api.example.com",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &clusterv1beta2.Cluster{}
+			cluster.Spec.ControlPlaneEndpoint.Host = tt.host
+			if got := requestedVIP(cluster); got != tt.want {
+				t.Errorf("requestedVIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVIPPort(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        int32
+	}{
+		{
+			name: "default port",
+			want: DefaultVIPPort,
+		},
+		{
+			name:        "custom port",
+			annotations: map[string]string{VIPPortAnnotation: "8443"},
+			want:        8443,
+		},
+		{
+			name:        "invalid port",
+			annotations: map[string]string{VIPPortAnnotation: "not-a-port"},
+			want:        DefaultVIPPort,
+		},
+		{
+			name:        "out of range port",
+			annotations: map[string]string{VIPPortAnnotation: "70000"},
+			want:        DefaultVIPPort,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &clusterv1beta2.Cluster{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := vipPort(cluster); got != tt.want {
+				t.Errorf("vipPort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterVIPReconciler_removedAnnotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := ipamv1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &clusterv1beta2.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "workload",
+			UID:         "workload-uid",
+			Annotations: map[string]string{VIPAddressAnnotation: "10.1.40.5"},
+		},
+		Spec: clusterv1beta2.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1beta2.APIEndpoint{Host: "10.1.40.5", Port: DefaultVIPPort},
+		},
+	}
+	claim := &ipamv1beta2.IPAddressClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: VIPClaimName(cluster.Name)},
+	}
+	if err := controllerutil.SetControllerReference(cluster, claim, scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, claim).Build()
+	r := &ClusterVIPReconciler{Client: c, Scheme: scheme}
+	key := types.NamespacedName{Namespace: "default", Name: "workload"}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: claim.Name}, claim); !apierrors.IsNotFound(err) {
+		t.Errorf("VIP claim still exists, get error = %v", err)
+	}
+	got := &clusterv1beta2.Cluster{}
+	if err := c.Get(context.Background(), key, got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.Annotations[VIPAddressAnnotation]; ok {
+		t.Errorf("cluster still has the %s annotation", VIPAddressAnnotation)
+	}
+	if got.Spec.ControlPlaneEndpoint.IsValid() {
+		t.Errorf("control plane endpoint = %v, want it cleared", got.Spec.ControlPlaneEndpoint)
+	}
+}
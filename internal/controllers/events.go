@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/ipamutil"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+
+// Event reasons recorded on claims and their IPAddresses. Pool events use the reason of the condition they report.
+const (
+	EventReasonPreAllocationReused     = "PreAllocationReused"
+	EventReasonRequestedIPHonored      = "RequestedIPHonored"
+	EventReasonStickyLeaseReused       = "StickyLeaseReused"
+	EventReasonAddressAllocated        = "AddressAllocated"
+	EventReasonUnifiReservationAdopted = "UnifiReservationAdopted"
+	EventReasonUnifiReservationCreated = "UnifiReservationCreated"
+	EventReasonUnifiReservationDeleted = "UnifiReservationDeleted"
+	EventReasonAddressReleased         = "AddressReleased"
+
+	// Warning reasons of failed allocations.
+	EventReasonRequestedIPRejected = "RequestedIPRejected"
+	EventReasonPoolExhausted       = "PoolExhausted"
+	EventReasonQuotaExceeded       = "QuotaExceeded"
+)
+
+// recordEvent emits an event if a recorder is configured.
+func recordEvent(recorder events.EventRecorder, regarding, related runtime.Object, eventtype, reason, action, note string, args ...any) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(regarding, related, eventtype, reason, action, note, args...)
+}
+
+// recordConditionWarning emits a warning event when a pool condition enters the
+// given abnormal status or changes its reason while in it.
+func recordConditionWarning(recorder events.EventRecorder, pool v1beta2.GenericUnifiIPPool, condition metav1.Condition, abnormal metav1.ConditionStatus) {
+	if condition.Status != abnormal {
+		return
+	}
+	previous := meta.FindStatusCondition(pool.PoolStatus().Conditions, condition.Type)
+	if previous != nil && previous.Status == condition.Status && previous.Reason == condition.Reason {
+		return
+	}
+	recordEvent(recorder, pool, nil, corev1.EventTypeWarning, condition.Reason, condition.Type, "%s", condition.Message)
+}
+
+// allocationFailureEventReason returns the warning reason of a failed allocation,
+// defaulting to the claim's Ready reason.
+func allocationFailureEventReason(claimErr *ipamutil.ClaimError) string {
+	switch claimErr.Reason {
+	case ipamutil.RequestedIPConflictReason:
+		return EventReasonRequestedIPRejected
+	case ipamv1beta2.IPAddressClaimReadyPoolExhaustedReason:
+		return EventReasonPoolExhausted
+	case ReasonQuotaExceeded:
+		return EventReasonQuotaExceeded
+	default:
+		return claimErr.Reason
+	}
+}
+
+// allocationEventReason returns the event reason describing how an address was chosen.
+func allocationEventReason(source unifi.AllocationSource) string {
+	switch source {
+	case unifi.AllocationSourcePreAllocation:
+		return EventReasonPreAllocationReused
+	case unifi.AllocationSourceRequested:
+		return EventReasonRequestedIPHonored
+	case unifi.AllocationSourceStickyLease:
+		return EventReasonStickyLeaseReused
+	case unifi.AllocationSourceAdopted:
+		return EventReasonUnifiReservationAdopted
+	default:
+		return EventReasonAddressAllocated
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/ipamutil"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func Test_recordConditionWarning(t *testing.T) {
+	exhausted := metav1.Condition{
+		Type:    ConditionExhausted,
+		Status:  metav1.ConditionTrue,
+		Reason:  "PoolExhausted",
+		Message: "Pool has no available capacity",
+	}
+
+	tests := []struct {
+		name      string
+		previous  []metav1.Condition
+		condition metav1.Condition
+		want      string
+	}{
+		{
+			name:      "condition becomes abnormal",
+			condition: exhausted,
+			want:      "Warning PoolExhausted Pool has no available capacity",
+		},
+		{
+			name: "reason changes",
+			previous: []metav1.Condition{{
+				Type: ConditionExhausted, Status: metav1.ConditionTrue, Reason: "HeadroomOnly",
+			}},
+			condition: exhausted,
+			want:      "Warning PoolExhausted Pool has no available capacity",
+		},
+		{
+			name: "message with a percent sign",
+			condition: metav1.Condition{
+				Type: ConditionNearlyExhausted, Status: metav1.ConditionTrue, Reason: "CriticalUtilization",
+				Message: "Pool is 85% utilized - approaching exhaustion",
+			},
+			want: "Warning CriticalUtilization Pool is 85% utilized - approaching exhaustion",
+		},
+		{
+			name:      "condition stays abnormal",
+			previous:  []metav1.Condition{exhausted},
+			condition: exhausted,
+		},
+		{
+			name: "condition is normal",
+			condition: metav1.Condition{
+				Type: ConditionExhausted, Status: metav1.ConditionFalse, Reason: "CapacityAvailable",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := events.NewFakeRecorder(1)
+			pool := &v1beta2.UnifiIPPool{Status: v1beta2.UnifiIPPoolStatus{Conditions: tt.previous}}
+
+			recordConditionWarning(recorder, pool, tt.condition, metav1.ConditionTrue)
+
+			var got string
+			select {
+			case got = <-recorder.Events:
+			default:
+			}
+			if got != tt.want {
+				t.Errorf("recordConditionWarning() event = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_allocationFailureEventReason(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   string
+	}{
+		{reason: ipamutil.RequestedIPConflictReason, want: EventReasonRequestedIPRejected},
+		{reason: ipamv1beta2.IPAddressClaimReadyPoolExhaustedReason, want: EventReasonPoolExhausted},
+		{reason: ReasonQuotaExceeded, want: EventReasonQuotaExceeded},
+		{reason: ipamutil.BackendUnavailableReason, want: ipamutil.BackendUnavailableReason},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			claimErr := ipamutil.NewClaimError(tt.reason, 0, errors.New("failed"))
+			if got := allocationFailureEventReason(claimErr); got != tt.want {
+				t.Errorf("allocationFailureEventReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiClaimHandler_AddressCreated(t *testing.T) {
+	recorder := events.NewFakeRecorder(1)
+	h := &UnifiClaimHandler{
+		recorder: recorder,
+		claim:    &ipamv1beta2.IPAddressClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"}},
+		pool:     &v1beta2.UnifiIPPool{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pool"}},
+		allocation: &unifi.IPAllocation{
+			IPAddress: "10.1.40.10",
+			Source:    unifi.AllocationSourceStickyLease,
+		},
+	}
+	address := &ipamv1beta2.IPAddress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"}}
+	poolutil.SetAddressMAC(address, "02:00:00:00:00:01")
+
+	h.AddressCreated(context.Background(), address)
+
+	want := "Normal StickyLeaseReused Allocated 10.1.40.10 to claim web-0 from UnifiIPPool pool (StickyLease) for MAC 02:00:00:00:00:01"
+	select {
+	case got := <-recorder.Events:
+		if got != want {
+			t.Errorf("AddressCreated() event = %q, want %q", got, want)
+		}
+	default:
+		t.Errorf("AddressCreated() recorded no event, want %q", want)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// GlobalUnifiIPPoolReconciler reconciles a GlobalUnifiIPPool object.
+// Pool status and Unifi sync are shared with UnifiIPPoolReconciler.
+type GlobalUnifiIPPoolReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+
+	// Defaults apply to pools that do not set utilization thresholds or a sync interval.
+	Defaults poolutil.PoolDefaults
+
+	// DryRun suppresses writes to all Unifi instances.
+	DryRun bool
+}
+
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=globalunifiippools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=globalunifiippools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=globalunifiippools/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddresses,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop.
+func (r *GlobalUnifiIPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	pool := &v1beta2.GlobalUnifiIPPool{}
+	if err := r.Get(ctx, req.NamespacedName, pool); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "unable to fetch GlobalUnifiIPPool")
+		return ctrl.Result{}, err
+	}
+
+	poolReconciler := &UnifiIPPoolReconciler{Client: r.Client, Scheme: r.Scheme, Recorder: r.Recorder, Defaults: r.Defaults, DryRun: r.DryRun}
+	return poolReconciler.reconcilePool(ctx, pool, logger)
+}
+
+// ipAddressToGlobalUnifiIPPool maps IPAddress events to GlobalUnifiIPPool reconcile requests.
+func (r *GlobalUnifiIPPoolReconciler) ipAddressToGlobalUnifiIPPool(_ context.Context, obj client.Object) []ctrl.Request {
+	address, ok := obj.(*ipamv1beta2.IPAddress)
+	if !ok {
+		return nil
+	}
+
+	// Only reconcile if the address references a GlobalUnifiIPPool.
+	if address.Spec.PoolRef.Kind != v1beta2.GlobalUnifiIPPoolKind ||
+		address.Spec.PoolRef.APIGroup != v1beta2.GroupVersion.Group {
+		return nil
+	}
+
+	return []ctrl.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name: address.Spec.PoolRef.Name,
+			},
+		},
+	}
+}
+
+// namespaceAllowed reports whether claims from the namespace may allocate from the pool.
+// A pool without an allow-list or selector is open to every namespace.
+func namespaceAllowed(pool *v1beta2.GlobalUnifiIPPool, namespace *corev1.Namespace) (bool, error) {
+	if len(pool.Spec.AllowedNamespaces) == 0 && pool.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+
+	if slices.Contains(pool.Spec.AllowedNamespaces, namespace.Name) {
+		return true, nil
+	}
+
+	if pool.Spec.NamespaceSelector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(pool.Spec.NamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GlobalUnifiIPPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta2.GlobalUnifiIPPool{}).
+		Watches(
+			&ipamv1beta2.IPAddress{},
+			handler.EnqueueRequestsFromMapFunc(r.ipAddressToGlobalUnifiIPPool),
+		).
+		Complete(r)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func TestNamespaceAllowed(t *testing.T) {
+	tenantNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tenant": "true"}},
+	}
+	otherNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-b"},
+	}
+	tenantSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}}
+
+	tests := []struct {
+		name      string
+		spec      v1beta2.GlobalUnifiIPPoolSpec
+		namespace *corev1.Namespace
+		want      bool
+	}{
+		{
+			name:      "no restrictions",
+			namespace: otherNamespace,
+			want:      true,
+		},
+		{
+			name:      "listed namespace",
+			spec:      v1beta2.GlobalUnifiIPPoolSpec{AllowedNamespaces: []string{"team-b"}},
+			namespace: otherNamespace,
+			want:      true,
+		},
+		{
+			name:      "unlisted namespace",
+			spec:      v1beta2.GlobalUnifiIPPoolSpec{AllowedNamespaces: []string{"team-a"}},
+			namespace: otherNamespace,
+			want:      false,
+		},
+		{
+			name:      "selector match",
+			spec:      v1beta2.GlobalUnifiIPPoolSpec{NamespaceSelector: tenantSelector},
+			namespace: tenantNamespace,
+			want:      true,
+		},
+		{
+			name:      "selector mismatch",
+			spec:      v1beta2.GlobalUnifiIPPoolSpec{NamespaceSelector: tenantSelector},
+			namespace: otherNamespace,
+			want:      false,
+		},
+		{
+			name: "listed or selected",
+			spec: v1beta2.GlobalUnifiIPPoolSpec{
+				AllowedNamespaces: []string{"team-b"},
+				NamespaceSelector: tenantSelector,
+			},
+			namespace: otherNamespace,
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &v1beta2.GlobalUnifiIPPool{Spec: tt.spec}
+			got, err := namespaceAllowed(pool, tt.namespace)
+			if err != nil {
+				t.Fatalf("namespaceAllowed() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("namespaceAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,13 +18,17 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,26 +37,55 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/metrics"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/ipamutil"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/predicates"
 
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
 )
 
-const unifiIPPoolKind = "UnifiIPPool"
-
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddressclaims,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddressclaims/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddressclaims/finalizers,verbs=update
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddresses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddresses/finalizers,verbs=update
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=globalunifiippools,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+const (
+	// ConditionQuotaSatisfied reports whether the claim fits the quotas of its pool.
+	ConditionQuotaSatisfied = "QuotaSatisfied"
+
+	// ReasonQuotaExceeded is the claim's Ready reason when it exceeds a quota of its pool.
+	ReasonQuotaExceeded = "QuotaExceeded"
+
+	// ReasonStickyLeasePending is the claim's Ready reason while the address leased to
+	// its sticky key is still held by an IPAddress being deleted.
+	ReasonStickyLeasePending = "StickyLeasePending"
+
+	// ReasonNamespaceNotAllowed is the claim's Ready reason when its namespace may not
+	// claim from the referenced GlobalUnifiIPPool.
+	ReasonNamespaceNotAllowed = "NamespaceNotAllowed"
+
+	// Backoffs of claims that failed to allocate, by Ready reason.
+	poolExhaustedRequeueAfter       = time.Minute
+	quotaExceededRequeueAfter       = time.Minute
+	requestedIPConflictRequeueAfter = 5 * time.Minute
+	poolNotReadyRequeueAfter        = 30 * time.Second
+	stickyLeasePendingRequeueAfter  = 10 * time.Second
+)
 
 // UnifiProviderAdapter implements the ipamutil.ProviderAdapter interface.
 type UnifiProviderAdapter struct {
 	client.Client
+	Recorder events.EventRecorder
+
+	// DryRun suppresses writes to all Unifi instances.
+	DryRun bool
 }
 
 var _ ipamutil.ProviderAdapter = &UnifiProviderAdapter{}
@@ -60,11 +93,20 @@ var _ ipamutil.ProviderAdapter = &UnifiProviderAdapter{}
 // UnifiClaimHandler implements the ipamutil.ClaimHandler interface.
 type UnifiClaimHandler struct {
 	client.Client
-	claim *ipamv1beta2.IPAddressClaim
-	pool  *v1beta2.UnifiIPPool
+	recorder events.EventRecorder
+	dryRun   bool
+	claim    *ipamv1beta2.IPAddressClaim
+	pool     v1beta2.GenericUnifiIPPool
+
+	// allocation is the allocation made by EnsureAddress, recorded on the IPAddress
+	// once it is created.
+	allocation *unifi.IPAllocation
 }
 
-var _ ipamutil.ClaimHandler = &UnifiClaimHandler{}
+var (
+	_ ipamutil.ClaimHandler    = &UnifiClaimHandler{}
+	_ ipamutil.AddressObserver = &UnifiClaimHandler{}
+)
 
 // SetupWithManager sets up the controller with the Manager.
 func (a *UnifiProviderAdapter) SetupWithManager(_ context.Context, b *ctrl.Builder) error {
@@ -83,6 +125,14 @@ func (a *UnifiProviderAdapter) SetupWithManager(_ context.Context, b *ctrl.Build
 				predicates.PoolNoLongerEmpty(),
 			),
 		).
+		Watches(
+			&v1beta2.GlobalUnifiIPPool{},
+			handler.EnqueueRequestsFromMapFunc(a.unifiIPPoolToIPClaims),
+			builder.WithPredicates(
+				predicates.ResourceTransitionedToUnpaused(),
+				predicates.PoolNoLongerEmpty(),
+			),
+		).
 		Owns(&ipamv1beta2.IPAddress{})
 
 	return nil
@@ -92,23 +142,24 @@ func (a *UnifiProviderAdapter) ToAdapter() ipamutil.ProviderAdapter {
 	return a
 }
 
-// unifiIPPoolToIPClaims maps UnifiIPPool events to IPAddressClaim reconcile requests.
+// unifiIPPoolToIPClaims maps UnifiIPPool and GlobalUnifiIPPool events to IPAddressClaim reconcile requests.
 func (a *UnifiProviderAdapter) unifiIPPoolToIPClaims(ctx context.Context, obj client.Object) []reconcile.Request {
-	pool, ok := obj.(*v1beta2.UnifiIPPool)
+	pool, ok := obj.(v1beta2.GenericUnifiIPPool)
 	if !ok {
 		return nil
 	}
 
-	// List all claims in the same namespace that reference this pool.
+	// List all claims that can reference this pool: the pool's namespace for
+	// UnifiIPPools, every namespace for GlobalUnifiIPPools.
 	claimList := &ipamv1beta2.IPAddressClaimList{}
-	if err := a.List(ctx, claimList, client.InNamespace(pool.Namespace)); err != nil {
+	if err := a.List(ctx, claimList, client.InNamespace(pool.GetNamespace())); err != nil {
 		return nil
 	}
 
 	requests := make([]reconcile.Request, 0)
 	for _, claim := range claimList.Items {
-		if claim.Spec.PoolRef.Name == pool.Name &&
-			claim.Spec.PoolRef.Kind == unifiIPPoolKind &&
+		if claim.Spec.PoolRef.Name == pool.GetName() &&
+			claim.Spec.PoolRef.Kind == pool.PoolKind() &&
 			claim.Spec.PoolRef.APIGroup == v1beta2.GroupVersion.Group {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
@@ -125,53 +176,165 @@ func (a *UnifiProviderAdapter) unifiIPPoolToIPClaims(ctx context.Context, obj cl
 // ClaimHandlerFor returns a ClaimHandler for the given claim.
 func (a *UnifiProviderAdapter) ClaimHandlerFor(_ client.Client, claim *ipamv1beta2.IPAddressClaim) ipamutil.ClaimHandler {
 	return &UnifiClaimHandler{
-		Client: a.Client,
-		claim:  claim,
+		Client:   a.Client,
+		recorder: a.Recorder,
+		dryRun:   a.DryRun,
+		claim:    claim,
 	}
 }
 
-// FetchPool fetches the UnifiIPPool referenced by the claim.
+// FetchPool fetches the UnifiIPPool or GlobalUnifiIPPool referenced by the claim.
 func (h *UnifiClaimHandler) FetchPool(ctx context.Context) (client.Object, *ctrl.Result, error) {
+	pool, err := h.getPool(ctx)
+	if err != nil || pool == nil {
+		return nil, nil, err
+	}
+
+	if globalPool, ok := pool.(*v1beta2.GlobalUnifiIPPool); ok {
+		allowed, err := h.namespaceAllowed(ctx, globalPool)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !allowed {
+			return nil, nil, ipamutil.NewClaimError(ReasonNamespaceNotAllowed, 0,
+				fmt.Errorf("namespace %s is not allowed to claim from GlobalUnifiIPPool %s", h.claim.Namespace, globalPool.Name))
+		}
+	}
+
+	h.pool = pool
+	return pool, nil, nil
+}
+
+// getPool fetches the pool referenced by the claim, returning nil if it doesn't exist.
+func (h *UnifiClaimHandler) getPool(ctx context.Context) (v1beta2.GenericUnifiIPPool, error) {
 	logger := ctrl.LoggerFrom(ctx)
 
-	pool := &v1beta2.UnifiIPPool{}
+	var pool v1beta2.GenericUnifiIPPool
 	poolKey := types.NamespacedName{
-		Name:      h.claim.Spec.PoolRef.Name,
-		Namespace: h.claim.Namespace,
+		Name: h.claim.Spec.PoolRef.Name,
+	}
+	switch h.claim.Spec.PoolRef.Kind {
+	case v1beta2.GlobalUnifiIPPoolKind:
+		pool = &v1beta2.GlobalUnifiIPPool{}
+	default:
+		pool = &v1beta2.UnifiIPPool{}
+		poolKey.Namespace = h.claim.Namespace
 	}
 
 	if err := h.Get(ctx, poolKey, pool); err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Error(err, "pool not found", "pool", poolKey)
-			return nil, nil, nil
+			return nil, nil
 		}
-		return nil, nil, fmt.Errorf("failed to fetch pool: %w", err)
+		return nil, fmt.Errorf("failed to fetch pool: %w", err)
 	}
 
-	h.pool = pool
-	return pool, nil, nil
+	return pool, nil
+}
+
+// namespaceAllowed checks the claim's namespace against the pool's allow-list and selector.
+func (h *UnifiClaimHandler) namespaceAllowed(ctx context.Context, pool *v1beta2.GlobalUnifiIPPool) (bool, error) {
+	namespace := &corev1.Namespace{}
+	namespace.Name = h.claim.Namespace
+	if pool.Spec.NamespaceSelector != nil {
+		if err := h.Get(ctx, types.NamespacedName{Name: h.claim.Namespace}, namespace); err != nil {
+			return false, fmt.Errorf("failed to get namespace %s: %w", h.claim.Namespace, err)
+		}
+	}
+
+	return namespaceAllowed(pool, namespace)
 }
 
 // EnsureAddress ensures that the IPAddress is allocated with a valid address.
+// Allocation failures are returned as ipamutil.ClaimErrors explaining the failure.
 func (h *UnifiClaimHandler) EnsureAddress(ctx context.Context, address *ipamv1beta2.IPAddress) (*ctrl.Result, error) {
+	res, err := h.ensureAddress(ctx, address)
+	if err = claimError(err); err != nil {
+		metrics.RecordAllocationFailure(poolMetricsRef(h.pool), failureReason(err))
+		var claimErr *ipamutil.ClaimError
+		if errors.As(err, &claimErr) {
+			recordEvent(h.recorder, h.claim, h.pool, corev1.EventTypeWarning, allocationFailureEventReason(claimErr), "Allocate",
+				"Failed to allocate address from %s %s: %s", h.pool.PoolKind(), h.pool.GetName(), err.Error())
+		}
+	}
+	return res, err
+}
+
+// AddressCreated records the allocation on the IPAddress created for the claim.
+func (h *UnifiClaimHandler) AddressCreated(_ context.Context, address *ipamv1beta2.IPAddress) {
+	if h.allocation == nil {
+		return
+	}
+	recordEvent(h.recorder, address, h.claim, corev1.EventTypeNormal, allocationEventReason(h.allocation.Source), "Allocate",
+		"Allocated %s to claim %s from %s %s (%s) for MAC %s", h.allocation.IPAddress, h.claim.Name,
+		h.pool.PoolKind(), h.pool.GetName(), h.allocation.Source, poolutil.AddressMAC(address))
+}
+
+func (h *UnifiClaimHandler) ensureAddress(ctx context.Context, address *ipamv1beta2.IPAddress) (*ctrl.Result, error) {
 	logger := ctrl.LoggerFrom(ctx)
 
-	addressesInUse, err := poolutil.ListAddressesInUse(ctx, h.Client, h.pool.Namespace,
-		h.pool.Name, unifiIPPoolKind, v1beta2.GroupVersion.Group)
+	addressesInUse, err := poolutil.ListAddressesInUse(ctx, h.Client, h.pool.GetNamespace(),
+		h.pool.GetName(), h.pool.PoolKind(), v1beta2.GroupVersion.Group)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list addresses in use: %w", err)
 	}
 
 	if h.isAddressAllocated(address, addressesInUse) {
+		h.recordLegacyMAC(address)
 		return nil, nil
 	}
 
+	if err := h.checkQuota(ctx, addressesInUse); err != nil {
+		return nil, err
+	}
+
+	// Reservations only reference pools in their own namespace.
+	var reservations []v1beta2.UnifiIPReservation
+	if h.pool.GetNamespace() != "" {
+		reservations, err = poolutil.ListReservationsInUse(ctx, h.Client, h.pool.GetNamespace(), h.pool.GetName())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	unifiClient, subnetSpec, err := h.setupAllocation(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return h.allocateIP(ctx, address, unifiClient, subnetSpec, addressesInUse, logger)
+	return h.allocateIP(ctx, address, unifiClient, subnetSpec, addressesInUse, reservedAddresses(reservations, ""), logger)
+}
+
+// checkQuota verifies that allocating an address to the claim stays within the
+// quotas of the pool and records the outcome in the claim's QuotaSatisfied condition.
+func (h *UnifiClaimHandler) checkQuota(ctx context.Context, addressesInUse []ipamv1beta2.IPAddress) error {
+	if h.pool.PoolSpec().Quotas == nil {
+		meta.RemoveStatusCondition(&h.claim.Status.Conditions, ConditionQuotaSatisfied)
+		return nil
+	}
+
+	consumers, err := quotaConsumers(ctx, h.Client, h.pool, addressesInUse)
+	if err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionQuotaSatisfied,
+		Status:             metav1.ConditionTrue,
+		Reason:             "WithinQuota",
+		Message:            "Claim is within the quotas of the pool",
+		ObservedGeneration: h.claim.Generation,
+	}
+
+	quotaErr := poolutil.CheckQuota(h.pool.PoolSpec(), consumers, claimQuotaConsumer(h.claim))
+	if quotaErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "QuotaExceeded"
+		condition.Message = quotaErr.Error()
+	}
+	meta.SetStatusCondition(&h.claim.Status.Conditions, condition)
+
+	return quotaErr
 }
 
 func (h *UnifiClaimHandler) isAddressAllocated(address *ipamv1beta2.IPAddress, addressesInUse []ipamv1beta2.IPAddress) bool {
@@ -189,90 +352,89 @@ func (h *UnifiClaimHandler) setupAllocation(ctx context.Context) (*unifi.Client,
 		return nil, nil, err
 	}
 
-	secret, err := h.getCredentialsSecret(ctx, instance)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	site := DefaultUnifiSite
-	if instance.Spec.Site != nil {
-		site = *instance.Spec.Site
+	unifiClient, err := newUnifiClient(ctx, h.Client, instance, credentialsNamespace(h.pool, instance), h.dryRun)
+	if apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("%w: %w", unifi.ErrPoolNotReady, err)
 	}
-	insecure := false
-	if instance.Spec.Insecure != nil {
-		insecure = *instance.Spec.Insecure
-	}
-
-	unifiClient, err := unifi.NewClient(unifi.Config{
-		Host:     instance.Spec.Host,
-		APIKey:   string(secret.Data["apiKey"]),
-		Site:     site,
-		Insecure: insecure,
-	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create Unifi client: %w", err)
 	}
 
-	if len(h.pool.Spec.Subnets) == 0 {
-		return nil, nil, fmt.Errorf("pool has no subnets configured")
+	if len(h.pool.PoolSpec().Subnets) == 0 {
+		return nil, nil, fmt.Errorf("%w: pool has no subnets configured", unifi.ErrPoolNotReady)
 	}
 
-	return unifiClient, &h.pool.Spec.Subnets[0], nil
+	return unifiClient, &h.pool.PoolSpec().Subnets[0], nil
 }
 
 func (h *UnifiClaimHandler) getUnifiInstance(ctx context.Context) (*v1beta2.UnifiInstance, error) {
 	instance := &v1beta2.UnifiInstance{}
 	instanceKey := types.NamespacedName{
-		Name:      h.pool.Spec.InstanceRef.Name,
-		Namespace: h.pool.Spec.InstanceRef.Namespace,
+		Name:      h.pool.PoolSpec().InstanceRef.Name,
+		Namespace: h.pool.PoolSpec().InstanceRef.Namespace,
 	}
 	if instanceKey.Namespace == "" {
-		instanceKey.Namespace = h.pool.Namespace
+		instanceKey.Namespace = h.pool.GetNamespace()
 	}
 
 	if err := h.Get(ctx, instanceKey, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: failed to fetch UnifiInstance: %w", unifi.ErrPoolNotReady, err)
+		}
 		return nil, fmt.Errorf("failed to fetch UnifiInstance: %w", err)
 	}
 
 	return instance, nil
 }
 
-func (h *UnifiClaimHandler) getCredentialsSecret(ctx context.Context, instance *v1beta2.UnifiInstance) (*corev1.Secret, error) {
-	var secret corev1.Secret
-	if err := h.Get(ctx, types.NamespacedName{
-		Name:      instance.Spec.CredentialsRef.Name,
-		Namespace: h.pool.Namespace,
-	}, &secret); err != nil {
-		return nil, fmt.Errorf("failed to get credentials secret: %w", err)
+func (h *UnifiClaimHandler) allocateIP(ctx context.Context, address *ipamv1beta2.IPAddress, unifiClient *unifi.Client, subnetSpec *v1beta2.SubnetSpec, addressesInUse []ipamv1beta2.IPAddress, reserved map[string]string, logger logr.Logger) (*ctrl.Result, error) {
+	// Addresses allocated before keep the MAC recorded on them.
+	macAddress := poolutil.AddressMAC(address)
+	var legacyMAC string
+	if macAddress == "" {
+		macAddress = generateMACAddress(h.claim.Namespace, h.claim.Name)
+		legacyMAC = h.legacyMACAddress()
+	}
+	stickyKey := poolutil.StickyKey(h.pool.PoolSpec(), h.claim.Annotations, h.claim.Labels)
+	useHeadroom, err := poolutil.UsesHeadroom(h.pool.PoolSpec(), h.claim.Labels)
+	if err != nil {
+		return nil, err
 	}
-	return &secret, nil
-}
-
-func (h *UnifiClaimHandler) allocateIP(ctx context.Context, address *ipamv1beta2.IPAddress, unifiClient *unifi.Client, subnetSpec *v1beta2.SubnetSpec, addressesInUse []ipamv1beta2.IPAddress, logger logr.Logger) (*ctrl.Result, error) {
-	macAddress := generateMACAddress(h.claim.Name)
 
 	// Use network ID from pool (either configured or discovered)
-	networkID := h.pool.Spec.NetworkID
-	if networkID == "" {
-		networkID = h.pool.Status.DiscoveredNetworkID
-	}
+	networkID := poolNetworkID(h.pool)
 	if networkID == "" {
-		return nil, fmt.Errorf("no network ID available (neither configured nor discovered)")
+		return nil, fmt.Errorf("%w: no network ID available (neither configured nor discovered)", unifi.ErrPoolNotReady)
 	}
 
 	allocation, err := unifiClient.GetOrAllocateIP(
 		ctx,
 		h.pool,
-		h.claim,
+		unifi.AllocationRequest{
+			Name:             h.claim.Name,
+			Namespace:        h.claim.Namespace,
+			RequestedIP:      h.claim.Annotations[requestedIPAnnotation],
+			MACAddress:       macAddress,
+			Hostname:         h.claim.Name,
+			ClusterName:      claimClusterName(h.claim),
+			StickyKey:        stickyKey,
+			UseHeadroom:      useHeadroom,
+			Adoption:         poolutil.AdoptionRequestFor(h.pool.PoolSpec(), h.claim.Annotations, claimNames(h.claim)),
+			Reserved:         reserved,
+			LegacyMACAddress: legacyMAC,
+		},
 		networkID,
-		macAddress,
-		h.claim.Name,
 		addressesInUse,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate IP: %w", err)
 	}
 
+	// A fixed IP kept from before the namespace seeded claim MACs stays bound to its MAC.
+	if legacyMAC != "" && allocation.MacAddress == legacyMAC {
+		macAddress = legacyMAC
+	}
+
 	address.Spec.Address = allocation.IPAddress
 	address.Spec.Gateway = allocation.Gateway
 	if allocation.Prefix > 0 {
@@ -280,31 +442,218 @@ func (h *UnifiClaimHandler) allocateIP(ctx context.Context, address *ipamv1beta2
 	}
 
 	// Store MAC address in labels for future cleanup
-	if address.Labels == nil {
-		address.Labels = make(map[string]string)
+	poolutil.SetAddressMAC(address, macAddress)
+
+	// Record the sticky key so the pool can lease the address to the claim's replacement
+	if stickyKey != "" {
+		if address.Annotations == nil {
+			address.Annotations = make(map[string]string)
+		}
+		address.Annotations[poolutil.StickyKeyAnnotation] = stickyKey
+	}
+
+	// Record the adopted client so the fixed IP is left in Unifi on release
+	if allocation.Source == unifi.AllocationSourceAdopted {
+		if address.Annotations == nil {
+			address.Annotations = make(map[string]string)
+		}
+		address.Annotations[poolutil.AdoptedMACAnnotation] = allocation.MacAddress
 	}
-	// Replace colons with dashes to comply with Kubernetes label requirements
-	address.Labels["unifi.ipam.cluster.x-k8s.io/mac"] = strings.ReplaceAll(macAddress, ":", "-")
 
 	logger.Info("allocated IP address",
 		"claim", h.claim.Name,
 		"address", allocation.IPAddress,
 		"mac", macAddress,
+		"stickyKey", stickyKey,
 		"prefix", allocation.Prefix,
-		"gateway", allocation.Gateway)
+		"gateway", allocation.Gateway,
+		"source", allocation.Source)
+
+	if allocation.Created {
+		note := "Created Unifi fixed IP %s for MAC %s"
+		if unifiClient.DryRun() {
+			note = "Dry run: would create Unifi fixed IP %s for MAC %s"
+		}
+		recordEvent(h.recorder, h.claim, h.pool, corev1.EventTypeNormal, EventReasonUnifiReservationCreated, "Allocate",
+			note, allocation.IPAddress, macAddress)
+	}
+	recordEvent(h.recorder, h.claim, h.pool, corev1.EventTypeNormal, allocationEventReason(allocation.Source), "Allocate",
+		"Allocated %s from %s %s (%s)", allocation.IPAddress, h.pool.PoolKind(), h.pool.GetName(), allocation.Source)
+	metrics.RecordAllocation(poolMetricsRef(h.pool), string(allocation.Source))
+	h.allocation = allocation
 
 	return nil, nil
 }
 
-// ReleaseAddress releases the IP address allocation.
+// ReleaseAddress releases the IP address allocation by deleting the Unifi fixed IP
+// assignment of the MAC recorded on the claim's IPAddress, which its ProtectAddress
+// finalizer keeps until the release is done. Adopted fixed IPs are left in Unifi.
+// The pool controller then quarantines the address if configured.
 func (h *UnifiClaimHandler) ReleaseAddress(ctx context.Context) (*ctrl.Result, error) {
-	// The Unifi client's ReleaseIP method is a no-op for now
-	// The IPAddress resource deletion already handles deallocation tracking.
+	logger := ctrl.LoggerFrom(ctx)
+
+	if h.pool == nil {
+		pool, err := h.getPool(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if pool == nil {
+			// Without the pool there is no instance to release against.
+			logger.Info("pool not found, skipping release of Unifi fixed IP")
+			return nil, nil
+		}
+		h.pool = pool
+	}
+
+	address := &ipamv1beta2.IPAddress{}
+	if err := h.Get(ctx, types.NamespacedName{Namespace: h.claim.Namespace, Name: h.claim.Name}, address); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("IPAddress not found, skipping release of Unifi fixed IP")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch IPAddress: %w", err)
+	}
+
+	if adoptedMAC := address.Annotations[poolutil.AdoptedMACAnnotation]; adoptedMAC != "" {
+		logger.Info("leaving adopted Unifi fixed IP in place", "mac", adoptedMAC)
+		recordEvent(h.recorder, address, h.claim, corev1.EventTypeNormal, EventReasonAddressReleased, "Release",
+			"Released %s of claim %s, leaving the adopted Unifi fixed IP of MAC %s in place",
+			address.Spec.Address, h.claim.Name, adoptedMAC)
+		metrics.RecordRelease(poolMetricsRef(h.pool))
+		return nil, nil
+	}
+
+	macAddress := poolutil.AddressMAC(address)
+	if macAddress == "" {
+		macAddress = h.legacyMACAddress()
+	}
+	if macAddress == "" {
+		logger.Info("IPAddress has no MAC recorded, skipping release of Unifi fixed IP")
+		return nil, nil
+	}
+
+	unifiClient, _, err := h.setupAllocation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unifiClient.ReleaseIP(ctx, poolNetworkID(h.pool), "", macAddress); err != nil {
+		return nil, fmt.Errorf("failed to release IP: %w", err)
+	}
+
+	logger.Info("released Unifi fixed IP", "mac", macAddress, "dryRun", unifiClient.DryRun())
+	note := "Deleted Unifi fixed IP for MAC %s"
+	if unifiClient.DryRun() {
+		note = "Dry run: would delete Unifi fixed IP for MAC %s"
+	}
+	recordEvent(h.recorder, h.claim, h.pool, corev1.EventTypeNormal, EventReasonUnifiReservationDeleted, "Release",
+		note, macAddress)
+	recordEvent(h.recorder, address, h.claim, corev1.EventTypeNormal, EventReasonAddressReleased, "Release",
+		"Released %s of claim %s to %s %s", address.Spec.Address, h.claim.Name, h.pool.PoolKind(), h.pool.GetName())
+	metrics.RecordRelease(poolMetricsRef(h.pool))
 	return nil, nil
 }
 
-// generateMACAddress generates a simple MAC address based on the claim name.
-// TODO: Use a more robust generation method in production.
-func generateMACAddress(name string) string {
-	return fmt.Sprintf("00:00:00:00:00:%02x", len(name)%256)
+// claimError classifies an allocation error for the claim's Ready condition and
+// picks the backoff of its reason. Backend errors use the controller's exponential backoff.
+func claimError(err error) error {
+	var quotaErr *poolutil.QuotaExceededError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &quotaErr):
+		return ipamutil.NewClaimError(ReasonQuotaExceeded, quotaExceededRequeueAfter, err)
+	case errors.Is(err, poolutil.ErrPoolExhausted), errors.Is(err, poolutil.ErrHeadroomReserved):
+		return ipamutil.NewClaimError(ipamv1beta2.IPAddressClaimReadyPoolExhaustedReason, poolExhaustedRequeueAfter, err)
+	case errors.Is(err, poolutil.ErrStickyLeasePending):
+		return ipamutil.NewClaimError(ReasonStickyLeasePending, stickyLeasePendingRequeueAfter, err)
+	case errors.Is(err, unifi.ErrRequestedIPConflict):
+		return ipamutil.NewClaimError(ipamutil.RequestedIPConflictReason, requestedIPConflictRequeueAfter, err)
+	case errors.Is(err, unifi.ErrPoolNotReady), errors.Is(err, unifi.ErrNetworkNotFound):
+		return ipamutil.NewClaimError(ipamv1beta2.IPAddressClaimReadyPoolNotReadyReason, poolNotReadyRequeueAfter, err)
+	case unifi.IsBackendError(err):
+		return ipamutil.NewClaimError(ipamutil.BackendUnavailableReason, 0, err)
+	default:
+		return err
+	}
+}
+
+// quotaConsumers returns the consumers of the addresses in use of a pool, taking
+// the labels from the claims holding them.
+func quotaConsumers(ctx context.Context, c client.Client, pool v1beta2.GenericUnifiIPPool, addressesInUse []ipamv1beta2.IPAddress) ([]poolutil.QuotaConsumer, error) {
+	claimList := &ipamv1beta2.IPAddressClaimList{}
+	if err := c.List(ctx, claimList, client.InNamespace(pool.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list claims: %w", err)
+	}
+
+	claims := make(map[types.NamespacedName]*ipamv1beta2.IPAddressClaim, len(claimList.Items))
+	for i := range claimList.Items {
+		claims[client.ObjectKeyFromObject(&claimList.Items[i])] = &claimList.Items[i]
+	}
+
+	consumers := make([]poolutil.QuotaConsumer, 0, len(addressesInUse))
+	for _, addr := range addressesInUse {
+		key := types.NamespacedName{Namespace: addr.Namespace, Name: addr.Spec.ClaimRef.Name}
+		if claim, ok := claims[key]; ok {
+			consumers = append(consumers, claimQuotaConsumer(claim))
+			continue
+		}
+		consumers = append(consumers, poolutil.QuotaConsumer{
+			Namespace:   addr.Namespace,
+			ClusterName: addr.Labels[clusterv1beta2.ClusterNameLabel],
+		})
+	}
+	return consumers, nil
+}
+
+// claimQuotaConsumer describes a claim for quota accounting.
+func claimQuotaConsumer(claim *ipamv1beta2.IPAddressClaim) poolutil.QuotaConsumer {
+	return poolutil.QuotaConsumer{
+		Namespace:   claim.Namespace,
+		ClusterName: claimClusterName(claim),
+		Labels:      claim.Labels,
+	}
+}
+
+// claimNames returns the names of the claim and its owners, which adoption matches
+// against Unifi client hostnames.
+func claimNames(claim *ipamv1beta2.IPAddressClaim) []string {
+	names := []string{claim.Name}
+	for _, owner := range claim.OwnerReferences {
+		names = append(names, owner.Name)
+	}
+	return names
+}
+
+// claimClusterName returns the CAPI cluster a claim belongs to.
+func claimClusterName(claim *ipamv1beta2.IPAddressClaim) string {
+	if claim.Spec.ClusterName != "" {
+		return claim.Spec.ClusterName
+	}
+	return claim.Labels[clusterv1beta2.ClusterNameLabel]
+}
+
+// generateMACAddress generates the deterministic MAC address used for a claim's Unifi reservation.
+func generateMACAddress(namespace, name string) string {
+	return unifi.ClaimMACAddress(namespace, name)
+}
+
+// legacyMACAddress returns the MAC the claim was allocated under before the namespace
+// seeded claim MACs. Only namespaced pools predate the namespace seed.
+func (h *UnifiClaimHandler) legacyMACAddress() string {
+	if h.pool.GetNamespace() == "" {
+		return ""
+	}
+	return unifi.LegacyClaimMACAddress(h.claim.Name)
+}
+
+// recordLegacyMAC records the legacy MAC on addresses allocated without a recorded
+// MAC, so reservation sync and release find their Unifi fixed IP.
+func (h *UnifiClaimHandler) recordLegacyMAC(address *ipamv1beta2.IPAddress) {
+	if poolutil.AddressMAC(address) != "" || address.Annotations[poolutil.AdoptedMACAnnotation] != "" {
+		return
+	}
+	if legacyMAC := h.legacyMACAddress(); legacyMAC != "" {
+		poolutil.SetAddressMAC(address, legacyMAC)
+	}
 }
@@ -18,16 +18,25 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/ipamutil"
 
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
 )
 
@@ -152,6 +161,35 @@ func TestUnifiClaimHandler_FetchPool(t *testing.T) {
 	}
 }
 
+func TestUnifiClaimHandler_FetchPoolNamespaceNotAllowed(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	pool := &v1beta2.GlobalUnifiIPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+		Spec:       v1beta2.GlobalUnifiIPPoolSpec{AllowedNamespaces: []string{"team-a"}},
+	}
+	h := &UnifiClaimHandler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(pool).Build(),
+		claim: &ipamv1beta2.IPAddressClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "web-0"},
+			Spec: ipamv1beta2.IPAddressClaimSpec{
+				PoolRef: ipamv1beta2.IPPoolReference{Kind: v1beta2.GlobalUnifiIPPoolKind, Name: "shared"},
+			},
+		},
+	}
+
+	got, _, err := h.FetchPool(context.Background())
+	if got != nil {
+		t.Errorf("UnifiClaimHandler.FetchPool() = %v, want no pool", got)
+	}
+	var claimErr *ipamutil.ClaimError
+	if !errors.As(err, &claimErr) || claimErr.Reason != ReasonNamespaceNotAllowed || claimErr.RequeueAfter != 0 {
+		t.Errorf("UnifiClaimHandler.FetchPool() error = %#v, want a terminal %s claim error", err, ReasonNamespaceNotAllowed)
+	}
+}
+
 func TestUnifiClaimHandler_EnsureAddress(t *testing.T) {
 	type fields struct {
 		Client client.Client
@@ -193,7 +231,7 @@ func TestUnifiClaimHandler_ReleaseAddress(t *testing.T) {
 	type fields struct {
 		Client client.Client
 		claim  *ipamv1beta2.IPAddressClaim
-		pool   *v1beta2.UnifiIPPool
+		pool   v1beta2.GenericUnifiIPPool
 	}
 	type args struct{}
 	tests := []struct {
@@ -203,7 +241,39 @@ func TestUnifiClaimHandler_ReleaseAddress(t *testing.T) {
 		want    *ctrl.Result
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "address already gone",
+			fields: fields{
+				Client: releaseTestClient(t),
+				claim:  releaseTestClaim(),
+				pool:   releaseTestPool(),
+			},
+		},
+		{
+			name: "adopted fixed IP is left in Unifi",
+			fields: fields{
+				Client: releaseTestClient(t, &ipamv1beta2.IPAddress{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   "default",
+						Name:        "web-0",
+						Labels:      map[string]string{poolutil.MACAddressLabel: "aa-bb-cc-dd-ee-ff"},
+						Annotations: map[string]string{poolutil.AdoptedMACAnnotation: "aa:bb:cc:dd:ee:ff"},
+					},
+				}),
+				claim: releaseTestClaim(),
+				pool:  releaseTestPool(),
+			},
+		},
+		{
+			name: "address of a global pool without MAC label",
+			fields: fields{
+				Client: releaseTestClient(t, &ipamv1beta2.IPAddress{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"},
+				}),
+				claim: releaseTestClaim(),
+				pool:  &v1beta2.GlobalUnifiIPPool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -224,22 +294,211 @@ func TestUnifiClaimHandler_ReleaseAddress(t *testing.T) {
 	}
 }
 
+func releaseTestClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := ipamv1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func releaseTestClaim() *ipamv1beta2.IPAddressClaim {
+	return &ipamv1beta2.IPAddressClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"}}
+}
+
+func releaseTestPool() *v1beta2.UnifiIPPool {
+	return &v1beta2.UnifiIPPool{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pool"}}
+}
+
 func Test_generateMACAddress(t *testing.T) {
 	type args struct {
-		name string
+		namespace string
+		name      string
 	}
 	tests := []struct {
 		name string
 		args args
 		want string
 	}{
-		// TODO: Add test cases.
+		{
+			name: "seeded with namespace and name",
+			args: args{namespace: "team-a", name: "worker-0"},
+			want: unifi.GenerateMACAddress("team-a/worker-0"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := generateMACAddress(tt.args.name); got != tt.want {
+			if got := generateMACAddress(tt.args.namespace, tt.args.name); got != tt.want {
 				t.Errorf("generateMACAddress() = %v, want %v", got, tt.want)
 			}
 		})
 	}
+
+	if generateMACAddress("team-a", "worker-0") == generateMACAddress("team-b", "worker-0") {
+		t.Error("claims with the same name in different namespaces got the same MAC")
+	}
+}
+
+func TestUnifiClaimHandler_recordLegacyMAC(t *testing.T) {
+	tests := []struct {
+		name    string
+		pool    v1beta2.GenericUnifiIPPool
+		address *ipamv1beta2.IPAddress
+		want    string
+	}{
+		{
+			name:    "unlabeled address of a namespaced pool",
+			pool:    releaseTestPool(),
+			address: &ipamv1beta2.IPAddress{},
+			want:    unifi.LegacyClaimMACAddress("web-0"),
+		},
+		{
+			name: "recorded MAC is kept",
+			pool: releaseTestPool(),
+			address: &ipamv1beta2.IPAddress{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{poolutil.MACAddressLabel: "aa-bb-cc-dd-ee-ff"},
+			}},
+			want: "aa:bb:cc:dd:ee:ff",
+		},
+		{
+			name: "adopted address",
+			pool: releaseTestPool(),
+			address: &ipamv1beta2.IPAddress{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{poolutil.AdoptedMACAnnotation: "aa:bb:cc:dd:ee:ff"},
+			}},
+		},
+		{
+			name:    "global pools always seeded MACs with the namespace",
+			pool:    &v1beta2.GlobalUnifiIPPool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}},
+			address: &ipamv1beta2.IPAddress{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &UnifiClaimHandler{claim: releaseTestClaim(), pool: tt.pool}
+			h.recordLegacyMAC(tt.address)
+			if got := poolutil.AddressMAC(tt.address); got != tt.want {
+				t.Errorf("recorded MAC = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_claimClusterName(t *testing.T) {
+	tests := []struct {
+		name  string
+		claim *ipamv1beta2.IPAddressClaim
+		want  string
+	}{
+		{
+			name:  "no cluster",
+			claim: &ipamv1beta2.IPAddressClaim{},
+			want:  "",
+		},
+		{
+			name: "cluster label",
+			claim: &ipamv1beta2.IPAddressClaim{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{clusterv1beta2.ClusterNameLabel: "from-label"},
+			}},
+			want: "from-label",
+		},
+		{
+			name: "spec takes precedence",
+			claim: &ipamv1beta2.IPAddressClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{clusterv1beta2.ClusterNameLabel: "from-label"},
+				},
+				Spec: ipamv1beta2.IPAddressClaimSpec{ClusterName: "from-spec"},
+			},
+			want: "from-spec",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := claimClusterName(tt.claim); got != tt.want {
+				t.Errorf("claimClusterName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_claimNames(t *testing.T) {
+	claim := &ipamv1beta2.IPAddressClaim{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-0-ip",
+		OwnerReferences: []metav1.OwnerReference{
+			{Kind: "Machine", Name: "web-0"},
+			{Kind: "VirtualMachine", Name: "web-0-vm"},
+		},
+	}}
+	want := []string{"web-0-ip", "web-0", "web-0-vm"}
+	if got := claimNames(claim); !reflect.DeepEqual(got, want) {
+		t.Errorf("claimNames() = %v, want %v", got, want)
+	}
+}
+
+func Test_claimError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantReason string
+		wantDelay  time.Duration
+	}{
+		{
+			name:       "pool exhausted",
+			err:        fmt.Errorf("failed to allocate IP: %w", poolutil.ErrPoolExhausted),
+			wantReason: ipamv1beta2.IPAddressClaimReadyPoolExhaustedReason,
+			wantDelay:  poolExhaustedRequeueAfter,
+		},
+		{
+			name:       "only headroom left",
+			err:        poolutil.ErrHeadroomReserved,
+			wantReason: ipamv1beta2.IPAddressClaimReadyPoolExhaustedReason,
+			wantDelay:  poolExhaustedRequeueAfter,
+		},
+		{
+			name:       "requested IP conflict",
+			err:        fmt.Errorf("%w: requested IP 10.0.0.5 is already assigned", unifi.ErrRequestedIPConflict),
+			wantReason: ipamutil.RequestedIPConflictReason,
+			wantDelay:  requestedIPConflictRequeueAfter,
+		},
+		{
+			name:       "sticky lease pending",
+			err:        fmt.Errorf("%w: 10.0.0.5 is held by IPAddress default/cp-0 being deleted", poolutil.ErrStickyLeasePending),
+			wantReason: ReasonStickyLeasePending,
+			wantDelay:  stickyLeasePendingRequeueAfter,
+		},
+		{
+			name:       "pool not ready",
+			err:        fmt.Errorf("%w: no network ID available", unifi.ErrPoolNotReady),
+			wantReason: ipamv1beta2.IPAddressClaimReadyPoolNotReadyReason,
+			wantDelay:  poolNotReadyRequeueAfter,
+		},
+		{
+			name:       "backend unavailable",
+			err:        fmt.Errorf("failed to list users: %w", &unifi.BackendError{Err: fmt.Errorf("connection refused")}),
+			wantReason: ipamutil.BackendUnavailableReason,
+		},
+		{
+			name:       "quota exceeded",
+			err:        &poolutil.QuotaExceededError{Type: v1beta2.QuotaTypeNamespace, Name: "default", Limit: 1},
+			wantReason: ReasonQuotaExceeded,
+			wantDelay:  quotaExceededRequeueAfter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claimErr *ipamutil.ClaimError
+			if !errors.As(claimError(tt.err), &claimErr) {
+				t.Fatalf("claimError() did not return a ClaimError")
+			}
+			if claimErr.Reason != tt.wantReason || claimErr.RequeueAfter != tt.wantDelay {
+				t.Errorf("claimError() = %s after %v, want %s after %v", claimErr.Reason, claimErr.RequeueAfter, tt.wantReason, tt.wantDelay)
+			}
+		})
+	}
+
+	if err := claimError(fmt.Errorf("boom")); err == nil || errors.As(err, new(*ipamutil.ClaimError)) {
+		t.Errorf("claimError() of an unknown error = %v, want the error unchanged", err)
+	}
 }
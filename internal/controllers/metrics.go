@@ -0,0 +1,41 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/metrics"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/ipamutil"
+)
+
+// poolMetricsRef identifies a pool in metrics.
+func poolMetricsRef(pool v1beta2.GenericUnifiIPPool) metrics.PoolRef {
+	return metrics.PoolRef{Kind: pool.PoolKind(), Namespace: pool.GetNamespace(), Name: pool.GetName()}
+}
+
+// failureReason returns the Ready reason an allocation error is reported with.
+func failureReason(err error) string {
+	var claimErr *ipamutil.ClaimError
+	if errors.As(err, &claimErr) {
+		return claimErr.Reason
+	}
+	return ipamv1beta2.IPAddressClaimReadyAllocationFailedReason
+}
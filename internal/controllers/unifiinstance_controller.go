@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -37,12 +38,19 @@ import (
 const (
 	// DefaultUnifiSite is the default Unifi site name when not specified.
 	DefaultUnifiSite = "default"
+
+	// dryRunStatusInterval is how often the writes suppressed in dry-run mode are
+	// published to the instance status.
+	dryRunStatusInterval = time.Minute
 )
 
 // UnifiInstanceReconciler reconciles a UnifiInstance object.
 type UnifiInstanceReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// DryRun suppresses writes to all Unifi instances.
+	DryRun bool
 }
 
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiinstances,verbs=get;list;watch;create;update;patch;delete
@@ -57,6 +65,9 @@ func (r *UnifiInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	instance := &v1beta2.UnifiInstance{}
 	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
 		if apierrors.IsNotFound(err) {
+			// Stop reporting the health of deleted instances.
+			unifi.DefaultHealthTracker.Forget(req.String())
+			unifi.DefaultDryRunLog.Forget(req.String())
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "unable to fetch UnifiInstance")
@@ -97,20 +108,7 @@ func (r *UnifiInstanceReconciler) getAPIKey(ctx context.Context, instance *v1bet
 }
 
 func (r *UnifiInstanceReconciler) createAndValidateClient(ctx context.Context, instance *v1beta2.UnifiInstance, apiKey string, logger logr.Logger) (*unifi.Client, error) {
-	site := DefaultUnifiSite
-	if instance.Spec.Site != nil {
-		site = *instance.Spec.Site
-	}
-	insecure := false
-	if instance.Spec.Insecure != nil {
-		insecure = *instance.Spec.Insecure
-	}
-	client, err := unifi.NewClient(unifi.Config{
-		Host:     instance.Spec.Host,
-		APIKey:   apiKey,
-		Site:     site,
-		Insecure: insecure,
-	})
+	client, err := unifi.NewClient(unifiConfig(instance, apiKey, r.DryRun))
 	if err != nil {
 		return nil, r.updateStatusError(ctx, instance, logger, "ClientCreationFailed", fmt.Sprintf("failed to create Unifi client: %v", err), err)
 	}
@@ -141,15 +139,86 @@ func (r *UnifiInstanceReconciler) updateStatusReady(ctx context.Context, instanc
 	now := metav1.Now()
 	instance.Status.LastSyncTime = &now
 
+	// Publish the writes suppressed in dry-run mode
+	key := client.ObjectKeyFromObject(instance).String()
+	instance.Status.DryRun = dryRun(r.DryRun, instance)
+	if !instance.Status.DryRun {
+		unifi.DefaultDryRunLog.Forget(key)
+	}
+	instance.Status.PlannedActions = plannedActions(unifi.DefaultDryRunLog.Actions(key))
+
 	if err := r.Status().Update(ctx, instance); err != nil {
 		logger.Error(err, "unable to update UnifiInstance status")
 		return ctrl.Result{}, err
 	}
 
 	logger.Info("successfully validated UnifiInstance", "instance", client.ObjectKeyFromObject(instance))
+	if instance.Status.DryRun {
+		return ctrl.Result{RequeueAfter: dryRunStatusInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// newUnifiClient creates a Unifi client for an instance with the API key of its
+// credentials secret, which is read from secretNamespace.
+func newUnifiClient(ctx context.Context, c client.Reader, instance *v1beta2.UnifiInstance, secretNamespace string, globalDryRun bool) (*unifi.Client, error) {
+	var secret corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{
+		Name:      instance.Spec.CredentialsRef.Name,
+		Namespace: secretNamespace,
+	}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get credentials secret: %w", err)
+	}
+
+	return unifi.NewClient(unifiConfig(instance, string(secret.Data["apiKey"]), globalDryRun))
+}
+
+// unifiConfig returns the client configuration of an instance.
+func unifiConfig(instance *v1beta2.UnifiInstance, apiKey string, globalDryRun bool) unifi.Config {
+	site := DefaultUnifiSite
+	if instance.Spec.Site != nil {
+		site = *instance.Spec.Site
+	}
+	insecure := false
+	if instance.Spec.Insecure != nil {
+		insecure = *instance.Spec.Insecure
+	}
+
+	return unifi.Config{
+		Host:     instance.Spec.Host,
+		APIKey:   apiKey,
+		Site:     site,
+		Insecure: insecure,
+		Instance: instance.Namespace + "/" + instance.Name,
+		DryRun:   dryRun(globalDryRun, instance),
+	}
+}
+
+// dryRun reports whether writes to the instance are suppressed, either for all
+// instances by the manager or for this one by its spec.
+func dryRun(global bool, instance *v1beta2.UnifiInstance) bool {
+	return global || (instance.Spec.DryRun != nil && *instance.Spec.DryRun)
+}
+
+// plannedActions converts suppressed writes to their status representation.
+func plannedActions(actions []unifi.PlannedAction) []v1beta2.PlannedUnifiAction {
+	if len(actions) == 0 {
+		return nil
+	}
+	planned := make([]v1beta2.PlannedUnifiAction, 0, len(actions))
+	for _, action := range actions {
+		planned = append(planned, v1beta2.PlannedUnifiAction{
+			Time:       metav1.NewTime(action.Time),
+			Operation:  action.Operation,
+			MACAddress: action.MAC,
+			Address:    action.IP,
+			NetworkID:  action.NetworkID,
+			Hostname:   action.Hostname,
+		})
+	}
+	return planned
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *UnifiInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
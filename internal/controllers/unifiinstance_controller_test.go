@@ -24,6 +24,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
 )
 
 func TestUnifiInstanceReconciler_Reconcile(t *testing.T) {
@@ -109,3 +111,27 @@ func Test_ptr(t *testing.T) {
 		})
 	}
 }
+
+func Test_dryRun(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name   string
+		global bool
+		spec   *bool
+		want   bool
+	}{
+		{name: "neither", want: false},
+		{name: "manager flag", global: true, want: true},
+		{name: "instance field", spec: &enabled, want: true},
+		{name: "instance cannot opt out of manager flag", global: true, spec: &disabled, want: true},
+		{name: "instance disabled", spec: &disabled, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &v1beta2.UnifiInstance{Spec: v1beta2.UnifiInstanceSpec{DryRun: tt.spec}}
+			if got := dryRun(tt.global, instance); got != tt.want {
+				t.Errorf("dryRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,15 +20,16 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"go4.org/netipx"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -36,8 +37,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/metrics"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/tracing"
 
 	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
 )
@@ -47,30 +50,48 @@ const (
 	ProtectPoolFinalizer = "ipam.cluster.x-k8s.io/ProtectPool"
 
 	// DefaultSyncInterval is how often to sync with Unifi controller.
-	DefaultSyncInterval = 10 * time.Minute
+	DefaultSyncInterval = poolutil.DefaultSyncInterval
 
 	// Condition types for UnifiIPPool status.
 	ConditionNetworkSynced = "NetworkSynced"
 	ConditionReady         = "Ready"
 	ConditionHealthy       = "Healthy"
 	ConditionExhausted     = "Exhausted"
+	ConditionUserGroup     = "UserGroupResolved"
+	ConditionOverlapping   = "Overlapping"
+
+	// ConditionNearlyExhausted is True when the pool reaches its critical utilization
+	// or the usage trend projects it to run out of addresses within
+	// poolutil.ExhaustionForecastWindow.
+	ConditionNearlyExhausted = "NearlyExhausted"
+
+	// ConditionReservationsSynced is False when the Unifi reservations of some of the
+	// pool's addresses differ from their IPAddresses and were not repaired.
+	ConditionReservationsSynced = "ReservationsSynced"
 )
 
 // UnifiIPPoolReconciler reconciles a UnifiIPPool object.
 type UnifiIPPoolReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+
+	// Defaults apply to pools that do not set utilization thresholds or a sync interval.
+	Defaults poolutil.PoolDefaults
+
+	// DryRun suppresses writes to all Unifi instances.
+	DryRun bool
 }
 
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiippools,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiippools/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiippools/finalizers,verbs=update
 // +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiipreservations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=globalunifiippools,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-//
-//nolint:cyclop // Reconciliation logic naturally has higher complexity
 func (r *UnifiIPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
@@ -83,10 +104,19 @@ func (r *UnifiIPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	if !pool.DeletionTimestamp.IsZero() {
+	return r.reconcilePool(ctx, pool, logger)
+}
+
+// reconcilePool reconciles a UnifiIPPool or GlobalUnifiIPPool.
+//
+//nolint:cyclop // Reconciliation logic naturally has higher complexity
+func (r *UnifiIPPoolReconciler) reconcilePool(ctx context.Context, pool v1beta2.GenericUnifiIPPool, logger logr.Logger) (ctrl.Result, error) {
+	if !pool.GetDeletionTimestamp().IsZero() {
 		return r.handleDeletion(ctx, pool, logger)
 	}
 
+	ctx = tracing.WithAttributes(ctx, tracing.PoolAttributes(pool.PoolKind(), pool.GetNamespace(), pool.GetName())...)
+
 	instance, err := r.getUnifiInstance(ctx, pool, logger)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -98,7 +128,7 @@ func (r *UnifiIPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	// Discover Unifi network if needed (when NetworkID not configured)
-	if pool.Spec.NetworkID == "" && pool.Status.DiscoveredNetworkID == "" {
+	if pool.PoolSpec().NetworkID == "" && pool.PoolStatus().DiscoveredNetworkID == "" {
 		if err := r.discoverNetwork(ctx, pool, instance, logger); err != nil {
 			logger.Error(err, "failed to discover Unifi network")
 			// Set condition and requeue
@@ -115,28 +145,39 @@ func (r *UnifiIPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	addressesInUse, err := poolutil.ListAddressesInUse(ctx, r.Client, pool.Namespace,
-		pool.Name, "UnifiIPPool", v1beta2.GroupVersion.Group)
+	addressesInUse, err := poolutil.ListAddressesInUse(ctx, r.Client, pool.GetNamespace(),
+		pool.GetName(), pool.PoolKind(), v1beta2.GroupVersion.Group)
 	if err != nil {
 		logger.Error(err, "unable to list addresses in use")
 		return ctrl.Result{}, err
 	}
 
+	reservations, err := r.listReservations(ctx, pool)
+	if err != nil {
+		logger.Error(err, "unable to list reservations")
+		return ctrl.Result{}, err
+	}
+
 	// Perform periodic sync with Unifi to detect configuration drift
-	if err := r.syncWithUnifi(ctx, pool, instance, logger); err != nil {
+	syncCtx, span := tracing.Start(ctx, "UnifiIPPool.Sync")
+	err = r.syncWithUnifi(syncCtx, pool, instance, addressesInUse, logger)
+	tracing.End(span, err)
+	if err != nil {
 		logger.Error(err, "failed to sync with Unifi network")
 		// Don't fail reconciliation on sync errors, but log and continue
 		// The sync will be retried on the next reconciliation
 	}
 
-	if err := r.updatePoolStatus(ctx, pool, poolIPSet, addressesInUse, logger); err != nil {
+	if err := r.updatePoolStatus(ctx, pool, poolIPSet, addressesInUse, reservations, logger); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	// Update all conditions
+	r.updateOverlapCondition(ctx, pool, logger)
 	r.updateReadyCondition(pool, instance)
 	r.updateHealthyCondition(pool)
 	r.updateExhaustedCondition(pool)
+	r.updateNearlyExhaustedCondition(pool, time.Now())
 
 	// Update status with all conditions
 	if err := r.Status().Update(ctx, pool); err != nil {
@@ -151,36 +192,44 @@ func (r *UnifiIPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return ctrl.Result{RequeueAfter: nextSync}, nil
 }
 
-func (r *UnifiIPPoolReconciler) handleDeletion(ctx context.Context, pool *v1beta2.UnifiIPPool, logger logr.Logger) (ctrl.Result, error) {
-	addressesInUse, err := poolutil.ListAddressesInUse(ctx, r.Client, pool.Namespace,
-		pool.Name, "UnifiIPPool", v1beta2.GroupVersion.Group)
+func (r *UnifiIPPoolReconciler) handleDeletion(ctx context.Context, pool v1beta2.GenericUnifiIPPool, logger logr.Logger) (ctrl.Result, error) {
+	addressesInUse, err := poolutil.ListAddressesInUse(ctx, r.Client, pool.GetNamespace(),
+		pool.GetName(), pool.PoolKind(), v1beta2.GroupVersion.Group)
 	if err != nil {
 		logger.Error(err, "unable to list addresses in use")
 		return ctrl.Result{}, err
 	}
 
-	if len(addressesInUse) == 0 {
+	reservations, err := r.listReservations(ctx, pool)
+	if err != nil {
+		logger.Error(err, "unable to list reservations")
+		return ctrl.Result{}, err
+	}
+
+	if len(addressesInUse) == 0 && len(reservations) == 0 {
 		if controllerutil.RemoveFinalizer(pool, ProtectPoolFinalizer) {
 			if err := r.Update(ctx, pool); err != nil {
 				logger.Error(err, "unable to remove finalizer")
 				return ctrl.Result{}, err
 			}
 		}
+		metrics.DeletePool(poolMetricsRef(pool))
 	} else {
-		logger.Info("pool has addresses in use, waiting for cleanup", "count", len(addressesInUse))
+		logger.Info("pool has addresses in use, waiting for cleanup",
+			"addresses", len(addressesInUse), "reservations", len(reservations))
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 	return ctrl.Result{}, nil
 }
 
-func (r *UnifiIPPoolReconciler) getUnifiInstance(ctx context.Context, pool *v1beta2.UnifiIPPool, logger logr.Logger) (*v1beta2.UnifiInstance, error) {
+func (r *UnifiIPPoolReconciler) getUnifiInstance(ctx context.Context, pool v1beta2.GenericUnifiIPPool, logger logr.Logger) (*v1beta2.UnifiInstance, error) {
 	instance := &v1beta2.UnifiInstance{}
 	instanceKey := types.NamespacedName{
-		Name:      pool.Spec.InstanceRef.Name,
-		Namespace: pool.Spec.InstanceRef.Namespace,
+		Name:      pool.PoolSpec().InstanceRef.Name,
+		Namespace: pool.PoolSpec().InstanceRef.Namespace,
 	}
 	if instanceKey.Namespace == "" {
-		instanceKey.Namespace = pool.Namespace
+		instanceKey.Namespace = pool.GetNamespace()
 	}
 
 	if err := r.Get(ctx, instanceKey, instance); err != nil {
@@ -191,14 +240,14 @@ func (r *UnifiIPPoolReconciler) getUnifiInstance(ctx context.Context, pool *v1be
 	return instance, nil
 }
 
-func (r *UnifiIPPoolReconciler) buildPoolIPSet(pool *v1beta2.UnifiIPPool, logger logr.Logger) (*netipx.IPSet, error) {
-	if len(pool.Spec.Subnets) == 0 {
+func (r *UnifiIPPoolReconciler) buildPoolIPSet(pool v1beta2.GenericUnifiIPPool, logger logr.Logger) (*netipx.IPSet, error) {
+	if len(pool.PoolSpec().Subnets) == 0 {
 		err := fmt.Errorf("pool has no subnets configured")
 		logger.Error(err, "invalid pool configuration")
 		return nil, err
 	}
 
-	poolIPSet, err := poolutil.PoolSpecToIPSet(&pool.Spec.Subnets[0])
+	poolIPSet, err := poolutil.PoolIPSet(pool.PoolSpec())
 	if err != nil {
 		logger.Error(err, "unable to convert pool spec to IPSet")
 		return nil, err
@@ -207,27 +256,69 @@ func (r *UnifiIPPoolReconciler) buildPoolIPSet(pool *v1beta2.UnifiIPPool, logger
 	return poolIPSet, nil
 }
 
-func (r *UnifiIPPoolReconciler) updatePoolStatus(ctx context.Context, pool *v1beta2.UnifiIPPool, poolIPSet *netipx.IPSet, addressesInUse []ipamv1beta2.IPAddress, logger logr.Logger) error {
+func (r *UnifiIPPoolReconciler) updatePoolStatus(ctx context.Context, pool v1beta2.GenericUnifiIPPool, poolIPSet *netipx.IPSet, addressesInUse []ipamv1beta2.IPAddress, reservations []v1beta2.UnifiIPReservation, logger logr.Logger) error {
 	// Compute basic address statistics
-	pool.Status.Addresses = poolutil.ComputePoolStatus(poolIPSet, addressesInUse, pool.Namespace)
+	pool.PoolStatus().Addresses = poolutil.ComputePoolStatus(poolIPSet, addressesInUse, reservations, pool.GetNamespace())
+	pool.PoolStatus().Addresses.Subnets = poolutil.ComputeSubnetStatus(pool.PoolSpec(), addressesInUse, reservations, pool.GetNamespace())
+	metrics.RecordPoolStatus(poolMetricsRef(pool), pool.PoolStatus().Addresses)
 
-	// Calculate capacity metrics
-	pool.Status.Capacity = r.calculateCapacityMetrics(pool.Status.Addresses)
+	// Sample usage and calculate capacity metrics including the exhaustion forecast
+	if used := pool.PoolStatus().Addresses.Used; used != nil {
+		pool.PoolStatus().UsageHistory = poolutil.RecordUsage(pool.PoolStatus().UsageHistory, *used, time.Now())
+	}
+	pool.PoolStatus().Capacity = r.calculateCapacityMetrics(pool.PoolSpec(), pool.PoolStatus().Addresses,
+		pool.PoolStatus().UsageHistory, time.Now())
 
 	// Update allocation details
-	pool.Status.AllocationDetails = r.buildAllocationDetails(addressesInUse, pool)
+	pool.PoolStatus().AllocationDetails = r.buildAllocationDetails(addressesInUse, pool)
 
-	// Update Status.Allocations map (claim name → IP address)
+	// Update Status.Allocations map (claim → IP address)
 	// This enables IP reuse workflows by providing visibility into current assignments
-	pool.Status.Allocations = make(map[string]string)
-	for _, addr := range addressesInUse {
+	previousAllocations := pool.PoolStatus().Allocations
+	pool.PoolStatus().Allocations = make(map[string]string)
+	for i := range addressesInUse {
+		addr := &addressesInUse[i]
 		if addr.Spec.ClaimRef.Name != "" && addr.Spec.Address != "" {
-			pool.Status.Allocations[addr.Spec.ClaimRef.Name] = addr.Spec.Address
+			pool.PoolStatus().Allocations[poolutil.AllocationKey(pool.GetNamespace(), addr)] = addr.Spec.Address
 		}
 	}
 
+	// Quarantine addresses that disappeared from the allocations since the last update
+	pool.PoolStatus().ReleasedAddresses = poolutil.UpdateReleasedAddresses(
+		pool.PoolStatus().ReleasedAddresses,
+		previousAllocations,
+		pool.PoolStatus().Allocations,
+		poolutil.QuarantinePeriod(pool.PoolSpec()),
+		time.Now(),
+	)
+
+	// Report the consumption of each quota
+	if pool.PoolSpec().Quotas != nil {
+		consumers, err := quotaConsumers(ctx, r.Client, pool, addressesInUse)
+		if err != nil {
+			return err
+		}
+		if pool.PoolStatus().QuotaUsage, err = poolutil.ComputeQuotaUsage(pool.PoolSpec(), consumers); err != nil {
+			return err
+		}
+	} else {
+		pool.PoolStatus().QuotaUsage = nil
+	}
+
+	// Lease addresses to the sticky keys of their claims so replacements inherit them
+	if pool.PoolSpec().Stickiness != nil {
+		pool.PoolStatus().Leases = poolutil.UpdateLeases(
+			pool.PoolStatus().Leases,
+			addressesInUse,
+			poolutil.LeaseDuration(pool.PoolSpec()),
+			time.Now(),
+		)
+	} else {
+		pool.PoolStatus().Leases = nil
+	}
+
 	// Add finalizer if addresses in use
-	if len(addressesInUse) > 0 {
+	if len(addressesInUse) > 0 || len(reservations) > 0 {
 		if controllerutil.AddFinalizer(pool, ProtectPoolFinalizer) {
 			if err := r.Update(ctx, pool); err != nil {
 				logger.Error(err, "unable to add finalizer")
@@ -237,7 +328,7 @@ func (r *UnifiIPPoolReconciler) updatePoolStatus(ctx context.Context, pool *v1be
 	}
 
 	now := metav1.Now()
-	pool.Status.LastSyncTime = &now
+	pool.PoolStatus().LastSyncTime = &now
 
 	if err := r.Status().Update(ctx, pool); err != nil {
 		logger.Error(err, "unable to update UnifiIPPool status")
@@ -246,16 +337,18 @@ func (r *UnifiIPPoolReconciler) updatePoolStatus(ctx context.Context, pool *v1be
 
 	logger.Info("successfully reconciled UnifiIPPool",
 		"pool", client.ObjectKeyFromObject(pool),
-		"total", pool.Status.Addresses.Total,
-		"used", pool.Status.Addresses.Used,
-		"free", pool.Status.Addresses.Free,
-		"utilization", pool.Status.Capacity.UtilizationPercent)
+		"total", pool.PoolStatus().Addresses.Total,
+		"used", pool.PoolStatus().Addresses.Used,
+		"free", pool.PoolStatus().Addresses.Free,
+		"utilization", pool.PoolStatus().Capacity.UtilizationPercent)
 
 	return nil
 }
 
 // calculateCapacityMetrics computes pool utilization metrics.
-func (r *UnifiIPPoolReconciler) calculateCapacityMetrics(summary *v1beta2.IPAddressStatusSummary) *v1beta2.PoolCapacity {
+// Free addresses are split into the reserved headroom and those available to all claims,
+// and the exhaustion time is projected from the usage history.
+func (r *UnifiIPPoolReconciler) calculateCapacityMetrics(spec *v1beta2.UnifiIPPoolSpec, summary *v1beta2.IPAddressStatusSummary, history []v1beta2.UsageSample, now time.Time) *v1beta2.PoolCapacity {
 	if summary == nil || summary.Total == nil || *summary.Total == 0 {
 		return &v1beta2.PoolCapacity{}
 	}
@@ -268,16 +361,29 @@ func (r *UnifiIPPoolReconciler) calculateCapacityMetrics(summary *v1beta2.IPAddr
 
 	// Calculate utilization percentage
 	utilizationPercent := (used * 100) / total
-	highUtilization := utilizationPercent >= 80
+	warning, _ := poolutil.UtilizationThresholds(spec, r.Defaults)
+	highUtilization := utilizationPercent >= warning
+
+	free := total - used
+	if summary.Free != nil {
+		free = *summary.Free
+	}
+	headroom, general := poolutil.SplitHeadroom(spec, free)
 
-	return &v1beta2.PoolCapacity{
+	capacity := &v1beta2.PoolCapacity{
 		UtilizationPercent: &utilizationPercent,
 		HighUtilization:    &highUtilization,
+		HeadroomReserved:   &headroom,
+		GeneralFree:        &general,
 	}
+	if exhaustedAt := poolutil.ForecastExhaustion(history, used, total, now); exhaustedAt != nil {
+		capacity.ExhaustedAt = &metav1.Time{Time: *exhaustedAt}
+	}
+	return capacity
 }
 
 // buildAllocationDetails creates detailed allocation information from IPAddress list.
-func (r *UnifiIPPoolReconciler) buildAllocationDetails(addressesInUse []ipamv1beta2.IPAddress, _ *v1beta2.UnifiIPPool) *v1beta2.AllocationDetails {
+func (r *UnifiIPPoolReconciler) buildAllocationDetails(addressesInUse []ipamv1beta2.IPAddress, _ v1beta2.GenericUnifiIPPool) *v1beta2.AllocationDetails {
 	if len(addressesInUse) == 0 {
 		return &v1beta2.AllocationDetails{
 			AllocatedIPs: []v1beta2.AllocatedIP{},
@@ -305,6 +411,8 @@ func (r *UnifiIPPoolReconciler) buildAllocationDetails(addressesInUse []ipamv1be
 			allocatedIP.ClusterName = clusterName
 		}
 
+		allocatedIP.StickyKey = addr.Annotations[poolutil.StickyKeyAnnotation]
+
 		// Track allocation time
 		creationTime := addr.GetCreationTimestamp()
 		allocatedIP.AllocatedAt = &creationTime
@@ -326,6 +434,24 @@ func (r *UnifiIPPoolReconciler) buildAllocationDetails(addressesInUse []ipamv1be
 	return details
 }
 
+// listReservations returns the UnifiIPReservations holding addresses from a namespaced pool.
+// Reservations can only reference pools in their own namespace, so cluster-scoped pools have none.
+func (r *UnifiIPPoolReconciler) listReservations(ctx context.Context, pool v1beta2.GenericUnifiIPPool) ([]v1beta2.UnifiIPReservation, error) {
+	if pool.GetNamespace() == "" {
+		return nil, nil
+	}
+	return poolutil.ListReservationsInUse(ctx, r.Client, pool.GetNamespace(), pool.GetName())
+}
+
+// credentialsNamespace returns the namespace holding the instance credentials for a pool.
+// Namespaced pools read the secret from their own namespace, cluster-scoped pools from the instance's.
+func credentialsNamespace(pool v1beta2.GenericUnifiIPPool, instance *v1beta2.UnifiInstance) string {
+	if pool.GetNamespace() != "" {
+		return pool.GetNamespace()
+	}
+	return instance.Namespace
+}
+
 // ipAddressToUnifiIPPool maps IPAddress events to UnifiIPPool reconcile requests.
 func (r *UnifiIPPoolReconciler) ipAddressToUnifiIPPool(_ context.Context, obj client.Object) []ctrl.Request {
 	address, ok := obj.(*ipamv1beta2.IPAddress)
@@ -334,7 +460,7 @@ func (r *UnifiIPPoolReconciler) ipAddressToUnifiIPPool(_ context.Context, obj cl
 	}
 
 	// Only reconcile if the address references a UnifiIPPool.
-	if address.Spec.PoolRef.Kind != "UnifiIPPool" ||
+	if address.Spec.PoolRef.Kind != v1beta2.UnifiIPPoolKind ||
 		address.Spec.PoolRef.APIGroup != v1beta2.GroupVersion.Group {
 		return nil
 	}
@@ -349,21 +475,38 @@ func (r *UnifiIPPoolReconciler) ipAddressToUnifiIPPool(_ context.Context, obj cl
 	}
 }
 
+// reservationToUnifiIPPool maps UnifiIPReservation events to UnifiIPPool reconcile requests.
+func (r *UnifiIPPoolReconciler) reservationToUnifiIPPool(_ context.Context, obj client.Object) []ctrl.Request {
+	reservation, ok := obj.(*v1beta2.UnifiIPReservation)
+	if !ok {
+		return nil
+	}
+
+	return []ctrl.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      reservation.Spec.PoolRef.Name,
+				Namespace: reservation.Namespace,
+			},
+		},
+	}
+}
+
 // syncWithUnifi performs periodic synchronization with Unifi network configuration.
 // This detects configuration drift and updates the pool's observed state.
 //
 //nolint:cyclop // Network sync logic requires multiple checks
-func (r *UnifiIPPoolReconciler) syncWithUnifi(ctx context.Context, pool *v1beta2.UnifiIPPool, instance *v1beta2.UnifiInstance, logger logr.Logger) error {
+func (r *UnifiIPPoolReconciler) syncWithUnifi(ctx context.Context, pool v1beta2.GenericUnifiIPPool, instance *v1beta2.UnifiInstance, addressesInUse []ipamv1beta2.IPAddress, logger logr.Logger) error {
 	// Import unifi client package
-	unifiClient, err := r.createUnifiClient(ctx, instance, pool.Namespace)
+	unifiClient, err := newUnifiClient(ctx, r.Client, instance, credentialsNamespace(pool, instance), r.DryRun)
 	if err != nil {
 		return fmt.Errorf("failed to create Unifi client: %w", err)
 	}
 
 	// Determine network ID to use (configured or discovered)
-	networkID := pool.Spec.NetworkID
+	networkID := pool.PoolSpec().NetworkID
 	if networkID == "" {
-		networkID = pool.Status.DiscoveredNetworkID
+		networkID = pool.PoolStatus().DiscoveredNetworkID
 	}
 	if networkID == "" {
 		return fmt.Errorf("no network ID available (neither configured nor discovered)")
@@ -382,38 +525,44 @@ func (r *UnifiIPPoolReconciler) syncWithUnifi(ctx context.Context, pool *v1beta2
 	}
 
 	// Update network info
-	pool.Status.NetworkInfo = &v1beta2.NetworkInfo{
-		Name:         network.Name,
+	pool.PoolStatus().NetworkInfo = &v1beta2.NetworkInfo{
+		Name:         deref(network.Name),
 		Purpose:      network.Purpose,
-		NetworkGroup: network.NetworkGroup,
+		NetworkGroup: deref(network.NetworkGroup),
 	}
 
 	// Add VLAN if configured
-	if network.VLAN != 0 && network.VLAN <= 4094 { // Valid VLAN range
-		vlan := int32(network.VLAN) // #nosec G115 - checked range
-		pool.Status.NetworkInfo.VLAN = &vlan
+	if vlanID := deref(network.VLAN); vlanID != 0 && vlanID <= 4094 { // Valid VLAN range
+		vlan := int32(vlanID) // #nosec G115 - checked range
+		pool.PoolStatus().NetworkInfo.VLAN = &vlan
 	}
 
 	// Add DHCP lease time if DHCP is enabled
-	if network.DHCPDEnabled && network.DHCPDLeaseTime > 0 && network.DHCPDLeaseTime <= 2147483647 {
-		leaseTime := int32(network.DHCPDLeaseTime) // #nosec G115 - checked range
-		pool.Status.NetworkInfo.DHCPLeaseTime = &leaseTime
+	if lease := deref(network.DHCPDLeaseTime); network.DHCPDEnabled && lease > 0 && lease <= 2147483647 {
+		leaseTime := int32(lease) // #nosec G115 - checked range
+		pool.PoolStatus().NetworkInfo.DHCPLeaseTime = &leaseTime
 	}
 
 	// Update observed network configuration
-	pool.Status.ObservedNetworkConfiguration = &v1beta2.ObservedNetworkConfig{
+	pool.PoolStatus().ObservedNetworkConfiguration = &v1beta2.ObservedNetworkConfig{
 		CIDR:        subnetSpec.CIDR,
 		Gateway:     subnetSpec.Gateway,
 		DHCPEnabled: &network.DHCPDEnabled,
 	}
 
-	if network.DHCPDEnabled && network.DHCPDStart != "" && network.DHCPDStop != "" {
-		pool.Status.ObservedNetworkConfiguration.DHCPRange = &v1beta2.DHCPRangeConfig{
-			Start: network.DHCPDStart,
-			Stop:  network.DHCPDStop,
+	if start, stop := deref(network.DHCPDStart), deref(network.DHCPDStop); network.DHCPDEnabled && start != "" && stop != "" {
+		pool.PoolStatus().ObservedNetworkConfiguration.DHCPRange = &v1beta2.DHCPRangeConfig{
+			Start: start,
+			Stop:  stop,
 		}
 	}
 
+	// Resolve the user group applied to reservations
+	r.syncUserGroup(ctx, pool, unifiClient, logger)
+
+	// Check the reservations of the pool's addresses against Unifi
+	r.syncReservations(ctx, pool, unifiClient, networkID, addressesInUse, logger)
+
 	// Detect configuration drift
 	driftDetected := r.detectConfigurationDrift(pool, subnetSpec, logger)
 
@@ -422,28 +571,127 @@ func (r *UnifiIPPoolReconciler) syncWithUnifi(ctx context.Context, pool *v1beta2
 
 	// Update last sync time
 	now := metav1.Now()
-	pool.Status.LastSyncTime = &now
+	pool.PoolStatus().LastSyncTime = &now
 
 	if err := r.Status().Update(ctx, pool); err != nil {
 		return fmt.Errorf("failed to update pool status: %w", err)
 	}
 
 	if driftDetected {
+		metrics.RecordDrift(poolMetricsRef(pool))
 		logger.Info("configuration drift detected between pool and Unifi network",
-			"pool_cidr", pool.Spec.Subnets[0].CIDR,
+			"pool_cidr", pool.PoolSpec().Subnets[0].CIDR,
 			"unifi_cidr", subnetSpec.CIDR)
 	}
 
 	return nil
 }
 
+// syncUserGroup resolves the pool's user group against Unifi and records the result in status.
+func (r *UnifiIPPoolReconciler) syncUserGroup(ctx context.Context, pool v1beta2.GenericUnifiIPPool, unifiClient *unifi.Client, logger logr.Logger) {
+	if pool.PoolSpec().UserGroup == "" {
+		pool.PoolStatus().UserGroupID = ""
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionUserGroup,
+		Status:             metav1.ConditionTrue,
+		Reason:             "UserGroupFound",
+		ObservedGeneration: pool.GetGeneration(),
+		LastTransitionTime: metav1.Now(),
+	}
+
+	group, err := unifiClient.ResolveUserGroup(ctx, pool.PoolSpec().UserGroup)
+	if err != nil {
+		logger.Error(err, "failed to resolve Unifi user group", "userGroup", pool.PoolSpec().UserGroup)
+		pool.PoolStatus().UserGroupID = ""
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "UserGroupNotFound"
+		condition.Message = fmt.Sprintf("Failed to resolve user group %q: %v", pool.PoolSpec().UserGroup, err)
+	} else {
+		pool.PoolStatus().UserGroupID = group.ID
+		condition.Message = fmt.Sprintf("Resolved user group %q to %s", group.Name, group.ID)
+	}
+
+	r.setCondition(pool, condition)
+}
+
+// syncReservations checks the Unifi reservation of every address of the pool and, if
+// the pool corrects mismatches, recreates missing ones and resets changed ones. Adopted
+// reservations belong to their Unifi client and fixed IPs held by another client are
+// not ours to take, so both are only reported. In dry-run mode repairs are only
+// planned, so the mismatches stay reported.
+func (r *UnifiIPPoolReconciler) syncReservations(ctx context.Context, pool v1beta2.GenericUnifiIPPool, unifiClient *unifi.Client, networkID string, addressesInUse []ipamv1beta2.IPAddress, logger logr.Logger) {
+	condition := metav1.Condition{
+		Type:               ConditionReservationsSynced,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ReservationsInSync",
+		ObservedGeneration: pool.GetGeneration(),
+		LastTransitionTime: metav1.Now(),
+	}
+
+	expected := unifi.ExpectedReservations(addressesInUse, pool.PoolStatus().UserGroupID)
+	drifts, err := unifiClient.CheckReservations(ctx, networkID, expected)
+	if err != nil {
+		logger.Error(err, "failed to check Unifi reservations")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReservationCheckFailed"
+		condition.Message = fmt.Sprintf("Failed to check Unifi reservations: %v", err)
+		recordConditionWarning(r.Recorder, pool, condition, metav1.ConditionFalse)
+		r.setCondition(pool, condition)
+		return
+	}
+
+	spec := pool.PoolSpec()
+	correct := spec.ReservationSync != nil && spec.ReservationSync.CorrectMismatches
+	var mismatches []v1beta2.ReservationMismatch
+	repaired := 0
+	for _, drift := range drifts {
+		if correct && !drift.Adopted && drift.Reason != v1beta2.ReservationIPConflict {
+			err := unifiClient.RepairReservation(ctx, networkID, pool.PoolStatus().UserGroupID, drift)
+			switch {
+			case err != nil:
+				logger.Error(err, "failed to repair Unifi reservation", "address", drift.Address, "mac", drift.MAC)
+			case !unifiClient.DryRun():
+				logger.Info("repaired Unifi reservation", "address", drift.Address, "mac", drift.MAC,
+					"reason", drift.Reason, "observed", drift.Observed)
+				repaired++
+				continue
+			}
+		}
+		mismatches = append(mismatches, v1beta2.ReservationMismatch{
+			Address:    drift.Address,
+			ClaimName:  drift.ClaimName,
+			MACAddress: drift.MAC,
+			Reason:     drift.Reason,
+			Observed:   drift.Observed,
+		})
+	}
+	pool.PoolStatus().ReservationMismatches = mismatches
+
+	if len(mismatches) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReservationMismatch"
+		condition.Message = fmt.Sprintf("%d of %d Unifi reservations differ from their IPAddresses", len(mismatches), len(expected))
+	} else {
+		condition.Message = fmt.Sprintf("%d Unifi reservations match their IPAddresses", len(expected))
+	}
+	if repaired > 0 {
+		condition.Message += fmt.Sprintf(", %d repaired", repaired)
+	}
+
+	recordConditionWarning(r.Recorder, pool, condition, metav1.ConditionFalse)
+	r.setCondition(pool, condition)
+}
+
 // detectConfigurationDrift compares pool configuration with Unifi network state.
-func (r *UnifiIPPoolReconciler) detectConfigurationDrift(pool *v1beta2.UnifiIPPool, unifiSpec *v1beta2.SubnetSpec, logger logr.Logger) bool {
-	if len(pool.Spec.Subnets) == 0 {
+func (r *UnifiIPPoolReconciler) detectConfigurationDrift(pool v1beta2.GenericUnifiIPPool, unifiSpec *v1beta2.SubnetSpec, logger logr.Logger) bool {
+	if len(pool.PoolSpec().Subnets) == 0 {
 		return false
 	}
 
-	poolSubnet := pool.Spec.Subnets[0]
+	poolSubnet := pool.PoolSpec().Subnets[0]
 	driftDetected := false
 
 	// Check CIDR drift
@@ -462,13 +710,13 @@ func (r *UnifiIPPoolReconciler) detectConfigurationDrift(pool *v1beta2.UnifiIPPo
 }
 
 // updateSyncCondition updates the NetworkSynced condition based on sync results.
-func (r *UnifiIPPoolReconciler) updateSyncCondition(pool *v1beta2.UnifiIPPool, driftDetected bool, syncErr error) {
+func (r *UnifiIPPoolReconciler) updateSyncCondition(pool v1beta2.GenericUnifiIPPool, driftDetected bool, syncErr error) {
 	condition := metav1.Condition{
 		Type:               ConditionNetworkSynced,
 		Status:             metav1.ConditionTrue,
 		Reason:             "SyncSucceeded",
 		Message:            "Pool configuration is synchronized with Unifi network",
-		ObservedGeneration: pool.Generation,
+		ObservedGeneration: pool.GetGeneration(),
 		LastTransitionTime: metav1.Now(),
 	}
 
@@ -482,17 +730,18 @@ func (r *UnifiIPPoolReconciler) updateSyncCondition(pool *v1beta2.UnifiIPPool, d
 		condition.Message = "Pool configuration differs from Unifi network configuration"
 	}
 
+	recordConditionWarning(r.Recorder, pool, condition, metav1.ConditionFalse)
 	r.setCondition(pool, condition)
 }
 
 // updateReadyCondition updates the Ready condition based on pool operational state.
-func (r *UnifiIPPoolReconciler) updateReadyCondition(pool *v1beta2.UnifiIPPool, instance *v1beta2.UnifiInstance) {
+func (r *UnifiIPPoolReconciler) updateReadyCondition(pool v1beta2.GenericUnifiIPPool, instance *v1beta2.UnifiInstance) {
 	condition := metav1.Condition{
 		Type:               ConditionReady,
 		Status:             metav1.ConditionTrue,
 		Reason:             "PoolReady",
 		Message:            "Pool is ready for IP allocation",
-		ObservedGeneration: pool.Generation,
+		ObservedGeneration: pool.GetGeneration(),
 		LastTransitionTime: metav1.Now(),
 	}
 
@@ -503,8 +752,15 @@ func (r *UnifiIPPoolReconciler) updateReadyCondition(pool *v1beta2.UnifiIPPool,
 		condition.Message = "Unifi instance is not ready"
 	}
 
+	// Check if the configured user group could be resolved
+	if pool.PoolSpec().UserGroup != "" && pool.PoolStatus().UserGroupID == "" {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "UserGroupNotResolved"
+		condition.Message = fmt.Sprintf("Unifi user group %q is not resolved", pool.PoolSpec().UserGroup)
+	}
+
 	// Check if pool has subnets configured
-	if len(pool.Spec.Subnets) == 0 {
+	if len(pool.PoolSpec().Subnets) == 0 {
 		condition.Status = metav1.ConditionFalse
 		condition.Reason = "NoSubnets"
 		condition.Message = "Pool has no subnets configured"
@@ -514,19 +770,20 @@ func (r *UnifiIPPoolReconciler) updateReadyCondition(pool *v1beta2.UnifiIPPool,
 }
 
 // updateHealthyCondition updates the Healthy condition based on allocation health.
-func (r *UnifiIPPoolReconciler) updateHealthyCondition(pool *v1beta2.UnifiIPPool) {
+func (r *UnifiIPPoolReconciler) updateHealthyCondition(pool v1beta2.GenericUnifiIPPool) {
 	condition := metav1.Condition{
 		Type:               ConditionHealthy,
 		Status:             metav1.ConditionTrue,
 		Reason:             "PoolHealthy",
 		Message:            "Pool is operating normally",
-		ObservedGeneration: pool.Generation,
+		ObservedGeneration: pool.GetGeneration(),
 		LastTransitionTime: metav1.Now(),
 	}
 
-	// Check for drift
-	for _, cond := range pool.Status.Conditions {
-		if cond.Type == ConditionNetworkSynced && cond.Status == metav1.ConditionFalse {
+	// Check for drift and overlapping pools
+	for _, cond := range pool.PoolStatus().Conditions {
+		if (cond.Type == ConditionNetworkSynced && cond.Status == metav1.ConditionFalse) ||
+			(cond.Type == ConditionOverlapping && cond.Status == metav1.ConditionTrue) {
 			condition.Status = metav1.ConditionFalse
 			condition.Reason = cond.Reason
 			condition.Message = fmt.Sprintf("Pool unhealthy: %s", cond.Message)
@@ -537,94 +794,142 @@ func (r *UnifiIPPoolReconciler) updateHealthyCondition(pool *v1beta2.UnifiIPPool
 	r.setCondition(pool, condition)
 }
 
+// updateOverlapCondition reports addresses shared between subnets of the pool or with
+// other pools on the same Unifi site, which the webhook rejects unless allowed.
+func (r *UnifiIPPoolReconciler) updateOverlapCondition(ctx context.Context, pool v1beta2.GenericUnifiIPPool, logger logr.Logger) {
+	condition := metav1.Condition{
+		Type:               ConditionOverlapping,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoOverlap",
+		Message:            "Pool does not overlap with other pools",
+		ObservedGeneration: pool.GetGeneration(),
+		LastTransitionTime: metav1.Now(),
+	}
+
+	subnetOverlaps, err := poolutil.FindSubnetOverlaps(pool.PoolSpec())
+	if err != nil {
+		logger.Error(err, "unable to check subnets for overlaps")
+		return
+	}
+
+	poolOverlaps, err := poolutil.FindPoolOverlaps(ctx, r.Client, pool)
+	if err != nil {
+		logger.Error(err, "unable to check for overlapping pools")
+		return
+	}
+
+	var overlaps []string
+	for _, overlap := range subnetOverlaps {
+		overlaps = append(overlaps, fmt.Sprintf("subnets %d and %d (%s)",
+			overlap.First, overlap.Second, poolutil.FormatRanges(overlap.Ranges)))
+	}
+	for _, overlap := range poolOverlaps {
+		overlaps = append(overlaps, overlap.String())
+	}
+
+	if len(overlaps) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "AddressesOverlap"
+		condition.Message = fmt.Sprintf("Addresses overlap with %s", strings.Join(overlaps, "; "))
+	}
+
+	recordConditionWarning(r.Recorder, pool, condition, metav1.ConditionTrue)
+	r.setCondition(pool, condition)
+}
+
 // updateExhaustedCondition updates the Exhausted condition based on capacity.
-func (r *UnifiIPPoolReconciler) updateExhaustedCondition(pool *v1beta2.UnifiIPPool) {
+func (r *UnifiIPPoolReconciler) updateExhaustedCondition(pool v1beta2.GenericUnifiIPPool) {
 	condition := metav1.Condition{
 		Type:               ConditionExhausted,
 		Status:             metav1.ConditionFalse,
 		Reason:             "CapacityAvailable",
 		Message:            "Pool has available capacity",
-		ObservedGeneration: pool.Generation,
+		ObservedGeneration: pool.GetGeneration(),
 		LastTransitionTime: metav1.Now(),
 	}
 
-	// Check if pool is exhausted or nearly exhausted
-	if pool.Status.Capacity != nil && pool.Status.Capacity.UtilizationPercent != nil {
-		utilization := *pool.Status.Capacity.UtilizationPercent
+	// Check if pool is exhausted, near exhaustion is reported by the NearlyExhausted condition
+	if pool.PoolStatus().Capacity != nil && pool.PoolStatus().Capacity.UtilizationPercent != nil {
+		utilization := *pool.PoolStatus().Capacity.UtilizationPercent
 		if utilization >= 100 {
 			condition.Status = metav1.ConditionTrue
 			condition.Reason = "PoolExhausted"
 			condition.Message = "Pool has no available capacity"
-		} else if utilization >= 90 {
+		} else if capacity := pool.PoolStatus().Capacity; capacity.HeadroomReserved != nil && *capacity.HeadroomReserved > 0 &&
+			capacity.GeneralFree != nil && *capacity.GeneralFree == 0 {
 			condition.Status = metav1.ConditionTrue
-			condition.Reason = "NearlyExhausted"
-			condition.Message = fmt.Sprintf("Pool is %d%% utilized - approaching exhaustion", utilization)
+			condition.Reason = "HeadroomOnly"
+			condition.Message = fmt.Sprintf("Only the %d addresses reserved as headroom are available", *capacity.HeadroomReserved)
 		}
 	}
 
+	recordConditionWarning(r.Recorder, pool, condition, metav1.ConditionTrue)
 	r.setCondition(pool, condition)
 }
 
-// setCondition updates or appends a condition to the pool status.
-func (r *UnifiIPPoolReconciler) setCondition(pool *v1beta2.UnifiIPPool, condition metav1.Condition) {
-	for i, existing := range pool.Status.Conditions {
-		if existing.Type == condition.Type {
-			pool.Status.Conditions[i] = condition
-			return
+// updateNearlyExhaustedCondition updates the NearlyExhausted condition from the
+// utilization and the projected exhaustion time of the pool.
+func (r *UnifiIPPoolReconciler) updateNearlyExhaustedCondition(pool v1beta2.GenericUnifiIPPool, now time.Time) {
+	condition := metav1.Condition{
+		Type:               ConditionNearlyExhausted,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoExhaustionForecast",
+		Message:            "Pool usage is not growing towards exhaustion",
+		ObservedGeneration: pool.GetGeneration(),
+		LastTransitionTime: metav1.Now(),
+	}
+
+	_, critical := poolutil.UtilizationThresholds(pool.PoolSpec(), r.Defaults)
+	if capacity := pool.PoolStatus().Capacity; capacity != nil && capacity.UtilizationPercent != nil && *capacity.UtilizationPercent >= critical {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "CriticalUtilization"
+		condition.Message = fmt.Sprintf("Pool is %d%% utilized - approaching exhaustion", *capacity.UtilizationPercent)
+	} else if capacity != nil && capacity.ExhaustedAt != nil {
+		exhaustedAt := capacity.ExhaustedAt.Time
+		condition.Message = fmt.Sprintf("Pool is projected to be exhausted at %s", exhaustedAt.UTC().Format(time.RFC3339))
+		if poolutil.NearlyExhausted(&exhaustedAt, now) {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "ExhaustionForecast"
+		} else {
+			condition.Reason = "ExhaustionDistant"
 		}
 	}
-	pool.Status.Conditions = append(pool.Status.Conditions, condition)
-}
 
-// calculateNextSyncInterval determines when the next sync should occur.
-func (r *UnifiIPPoolReconciler) calculateNextSyncInterval(pool *v1beta2.UnifiIPPool) time.Duration {
-	// Use default sync interval
-	// Could be made configurable via pool annotations in the future
-	return DefaultSyncInterval
+	recordConditionWarning(r.Recorder, pool, condition, metav1.ConditionTrue)
+	r.setCondition(pool, condition)
 }
 
-// createUnifiClient creates a Unifi client from instance credentials.
-func (r *UnifiIPPoolReconciler) createUnifiClient(ctx context.Context, instance *v1beta2.UnifiInstance, namespace string) (*unifi.Client, error) {
-	// Get credentials secret
-	var secret corev1.Secret
-	if err := r.Get(ctx, types.NamespacedName{
-		Name:      instance.Spec.CredentialsRef.Name,
-		Namespace: namespace,
-	}, &secret); err != nil {
-		return nil, fmt.Errorf("failed to get credentials secret: %w", err)
-	}
-
-	site := DefaultUnifiSite
-	if instance.Spec.Site != nil {
-		site = *instance.Spec.Site
-	}
-	insecure := false
-	if instance.Spec.Insecure != nil {
-		insecure = *instance.Spec.Insecure
+// setCondition updates or appends a condition to the pool status.
+func (r *UnifiIPPoolReconciler) setCondition(pool v1beta2.GenericUnifiIPPool, condition metav1.Condition) {
+	for i, existing := range pool.PoolStatus().Conditions {
+		if existing.Type == condition.Type {
+			pool.PoolStatus().Conditions[i] = condition
+			return
+		}
 	}
+	pool.PoolStatus().Conditions = append(pool.PoolStatus().Conditions, condition)
+}
 
-	return unifi.NewClient(unifi.Config{
-		Host:     instance.Spec.Host,
-		APIKey:   string(secret.Data["apiKey"]),
-		Site:     site,
-		Insecure: insecure,
-	})
+// calculateNextSyncInterval determines when the next sync should occur from the pool's
+// sync interval or the manager default, with jitter.
+func (r *UnifiIPPoolReconciler) calculateNextSyncInterval(pool v1beta2.GenericUnifiIPPool) time.Duration {
+	return poolutil.NextSyncAfter(pool.PoolSpec(), r.Defaults)
 }
 
 // discoverNetwork attempts to auto-discover the Unifi network that contains the configured subnets.
-func (r *UnifiIPPoolReconciler) discoverNetwork(ctx context.Context, pool *v1beta2.UnifiIPPool, instance *v1beta2.UnifiInstance, logger logr.Logger) error {
-	if len(pool.Spec.Subnets) == 0 {
+func (r *UnifiIPPoolReconciler) discoverNetwork(ctx context.Context, pool v1beta2.GenericUnifiIPPool, instance *v1beta2.UnifiInstance, logger logr.Logger) error {
+	if len(pool.PoolSpec().Subnets) == 0 {
 		return fmt.Errorf("no subnets configured in pool")
 	}
 
-	unifiClient, err := r.createUnifiClient(ctx, instance, pool.Namespace)
+	unifiClient, err := newUnifiClient(ctx, r.Client, instance, credentialsNamespace(pool, instance), r.DryRun)
 	if err != nil {
 		return fmt.Errorf("failed to create Unifi client: %w", err)
 	}
 
 	// Try to discover network for first subnet
 	// (In future, could validate all subnets are in same network)
-	firstSubnet := pool.Spec.Subnets[0]
+	firstSubnet := pool.PoolSpec().Subnets[0]
 
 	// Convert subnet to CIDR string if using Start/End notation
 	subnetCIDR := firstSubnet.CIDR
@@ -632,8 +937,8 @@ func (r *UnifiIPPoolReconciler) discoverNetwork(ctx context.Context, pool *v1bet
 		// For Start/End ranges, construct a CIDR from the start address and prefix
 		// Must apply network mask to get proper network address (not host address)
 		defaultPrefix := int32(24)
-		if pool.Spec.Prefix != nil {
-			defaultPrefix = *pool.Spec.Prefix
+		if pool.PoolSpec().Prefix != nil {
+			defaultPrefix = *pool.PoolSpec().Prefix
 		}
 		prefix := poolutil.GetPrefix(firstSubnet, defaultPrefix)
 		
@@ -659,20 +964,20 @@ func (r *UnifiIPPoolReconciler) discoverNetwork(ctx context.Context, pool *v1bet
 	}
 
 	// Update discovered network ID in status
-	pool.Status.DiscoveredNetworkID = network.ID
+	pool.PoolStatus().DiscoveredNetworkID = network.ID
 	logger.Info("discovered Unifi network for pool",
 		"network_id", network.ID,
-		"network_name", network.Name,
+		"network_name", deref(network.Name),
 		"subnet", subnetCIDR)
 
 	return nil
 }
 
 // updateNetworkDiscoveryCondition sets the NetworkDiscovery condition based on discovery result.
-func (r *UnifiIPPoolReconciler) updateNetworkDiscoveryCondition(pool *v1beta2.UnifiIPPool, err error) {
+func (r *UnifiIPPoolReconciler) updateNetworkDiscoveryCondition(pool v1beta2.GenericUnifiIPPool, err error) {
 	condition := metav1.Condition{
 		Type:               "NetworkDiscovered",
-		ObservedGeneration: pool.Generation,
+		ObservedGeneration: pool.GetGeneration(),
 		LastTransitionTime: metav1.Now(),
 	}
 
@@ -683,7 +988,7 @@ func (r *UnifiIPPoolReconciler) updateNetworkDiscoveryCondition(pool *v1beta2.Un
 	} else {
 		condition.Status = metav1.ConditionTrue
 		condition.Reason = "NetworkFound"
-		condition.Message = fmt.Sprintf("Discovered network: %s", pool.Status.DiscoveredNetworkID)
+		condition.Message = fmt.Sprintf("Discovered network: %s", pool.PoolStatus().DiscoveredNetworkID)
 	}
 
 	r.setCondition(pool, condition)
@@ -697,5 +1002,18 @@ func (r *UnifiIPPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&ipamv1beta2.IPAddress{},
 			handler.EnqueueRequestsFromMapFunc(r.ipAddressToUnifiIPPool),
 		).
+		Watches(
+			&v1beta2.UnifiIPReservation{},
+			handler.EnqueueRequestsFromMapFunc(r.reservationToUnifiIPPool),
+		).
 		Complete(r)
 }
+
+// deref returns the value p points to, or the zero value if p is nil.
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
@@ -20,10 +20,17 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
 )
 
 func TestUnifiIPPoolReconciler_Reconcile(t *testing.T) {
@@ -118,3 +125,69 @@ func TestUnifiIPPoolReconciler_SetupWithManager(t *testing.T) {
 		})
 	}
 }
+
+func TestUnifiIPPoolReconciler_updateNearlyExhaustedCondition(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	critical := int32(92)
+
+	tests := []struct {
+		name        string
+		capacity    *v1beta2.PoolCapacity
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+		wantWarning bool
+	}{
+		{
+			name:       "no capacity",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "NoExhaustionForecast",
+		},
+		{
+			name:       "no forecast",
+			capacity:   &v1beta2.PoolCapacity{},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "NoExhaustionForecast",
+		},
+		{
+			name:        "exhaustion within the forecast window",
+			capacity:    &v1beta2.PoolCapacity{ExhaustedAt: &metav1.Time{Time: now.Add(48 * time.Hour)}},
+			wantStatus:  metav1.ConditionTrue,
+			wantReason:  "ExhaustionForecast",
+			wantWarning: true,
+		},
+		{
+			name:        "critical utilization",
+			capacity:    &v1beta2.PoolCapacity{UtilizationPercent: &critical},
+			wantStatus:  metav1.ConditionTrue,
+			wantReason:  "CriticalUtilization",
+			wantWarning: true,
+		},
+		{
+			name:       "exhaustion beyond the forecast window",
+			capacity:   &v1beta2.PoolCapacity{ExhaustedAt: &metav1.Time{Time: now.Add(poolutil.ExhaustionForecastWindow + time.Hour)}},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "ExhaustionDistant",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := events.NewFakeRecorder(1)
+			r := &UnifiIPPoolReconciler{Recorder: recorder}
+			pool := &v1beta2.UnifiIPPool{Status: v1beta2.UnifiIPPoolStatus{Capacity: tt.capacity}}
+
+			r.updateNearlyExhaustedCondition(pool, now)
+
+			condition := meta.FindStatusCondition(pool.Status.Conditions, ConditionNearlyExhausted)
+			if condition == nil {
+				t.Fatalf("condition %s not set", ConditionNearlyExhausted)
+			}
+			if condition.Status != tt.wantStatus || condition.Reason != tt.wantReason {
+				t.Errorf("condition = %s/%s, want %s/%s", condition.Status, condition.Reason, tt.wantStatus, tt.wantReason)
+			}
+			if got := len(recorder.Events) == 1; got != tt.wantWarning {
+				t.Errorf("warning recorded = %v, want %v", got, tt.wantWarning)
+			}
+		})
+	}
+}
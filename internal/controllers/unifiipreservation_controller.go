@@ -0,0 +1,361 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+)
+
+const (
+	// ReleaseReservationFinalizer is added to UnifiIPReservation resources so the
+	// Unifi fixed IP assignment is released before the reservation is removed.
+	ReleaseReservationFinalizer = "ipam.cluster.x-k8s.io/ReleaseReservation"
+
+	// reservationMACSeedPrefix keeps generated reservation MACs apart from the
+	// MACs generated for IPAddressClaims with the same name.
+	reservationMACSeedPrefix = "unifiipreservation/"
+
+	// reservationRecheckInterval is how often ready reservations are checked
+	// against their pool, whose subnets and exclusions may change.
+	reservationRecheckInterval = 5 * time.Minute
+)
+
+// UnifiIPReservationReconciler reconciles a UnifiIPReservation object.
+type UnifiIPReservationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// DryRun suppresses writes to all Unifi instances.
+	DryRun bool
+}
+
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiipreservations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiipreservations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiipreservations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=unifiippools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile reserves an address from the referenced pool and releases it on deletion.
+func (r *UnifiIPReservationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	reservation := &v1beta2.UnifiIPReservation{}
+	if err := r.Get(ctx, req.NamespacedName, reservation); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "unable to fetch UnifiIPReservation")
+		return ctrl.Result{}, err
+	}
+
+	if !reservation.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, reservation, logger)
+	}
+
+	if controllerutil.AddFinalizer(reservation, ReleaseReservationFinalizer) {
+		if err := r.Update(ctx, reservation); err != nil {
+			logger.Error(err, "unable to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	pool := &v1beta2.UnifiIPPool{}
+	poolKey := types.NamespacedName{Namespace: reservation.Namespace, Name: reservation.Spec.PoolRef.Name}
+	if err := r.Get(ctx, poolKey, pool); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return r.setNotReady(ctx, reservation, "PoolNotFound", fmt.Sprintf("UnifiIPPool %s not found", poolKey), logger)
+	}
+
+	if !pool.DeletionTimestamp.IsZero() {
+		return r.setNotReady(ctx, reservation, "PoolDeleting", fmt.Sprintf("UnifiIPPool %s is being deleted", poolKey), logger)
+	}
+
+	// The spec is immutable, so an address that has been reserved stays reserved
+	// as long as the pool still hands it out.
+	if reservation.Status.Address != "" {
+		contained, err := poolContains(pool, reservation.Status.Address)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !contained {
+			return r.setNotReady(ctx, reservation, "AddressOutsidePool",
+				fmt.Sprintf("Reserved address %s is no longer in UnifiIPPool %s", reservation.Status.Address, poolKey), logger)
+		}
+		if reservation.Status.Ready != nil && *reservation.Status.Ready {
+			return ctrl.Result{RequeueAfter: reservationRecheckInterval}, nil
+		}
+	}
+
+	networkID := poolNetworkID(pool)
+	if networkID == "" {
+		return r.setNotReady(ctx, reservation, "NetworkNotDiscovered", "pool has no Unifi network ID yet", logger)
+	}
+
+	instance, err := r.getUnifiInstance(ctx, pool)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if instance.Status.Ready == nil || !*instance.Status.Ready {
+		return r.setNotReady(ctx, reservation, "InstanceNotReady", fmt.Sprintf("UnifiInstance %s is not ready", instance.Name), logger)
+	}
+
+	unifiClient, err := newUnifiClient(ctx, r.Client, instance, credentialsNamespace(pool, instance), r.DryRun)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create Unifi client: %w", err)
+	}
+
+	addressesInUse, err := poolutil.ListAddressesInUse(ctx, r.Client, pool.Namespace,
+		pool.Name, v1beta2.UnifiIPPoolKind, v1beta2.GroupVersion.Group)
+	if err != nil {
+		logger.Error(err, "unable to list addresses in use")
+		return ctrl.Result{}, err
+	}
+
+	reservations, err := poolutil.ListReservationsInUse(ctx, r.Client, pool.Namespace, pool.Name)
+	if err != nil {
+		logger.Error(err, "unable to list reservations in use")
+		return ctrl.Result{}, err
+	}
+
+	request := reservationRequest(reservation)
+	request.Reserved = reservedAddresses(reservations, reservation.Name)
+	allocation, err := unifiClient.GetOrAllocateIP(ctx, pool, request, networkID, addressesInUse)
+	if err == nil && reservation.Spec.Address != "" && allocation.IPAddress != reservation.Spec.Address {
+		err = fmt.Errorf("MAC %s already has fixed IP %s in Unifi", allocation.MacAddress, allocation.IPAddress)
+	}
+	if err != nil {
+		if _, statusErr := r.setNotReady(ctx, reservation, "AllocationFailed", err.Error(), logger); statusErr != nil {
+			logger.Error(statusErr, "unable to update UnifiIPReservation status")
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to reserve IP: %w", err)
+	}
+
+	ready := true
+	reservation.Status.Ready = &ready
+	reservation.Status.Address = allocation.IPAddress
+	reservation.Status.Gateway = allocation.Gateway
+	reservation.Status.MACAddress = allocation.MacAddress
+	if allocation.Prefix > 0 {
+		reservation.Status.Prefix = &allocation.Prefix
+	}
+	message := fmt.Sprintf("Reserved %s in Unifi", allocation.IPAddress)
+	if allocation.Created && unifiClient.DryRun() {
+		message = fmt.Sprintf("Dry run: would reserve %s in Unifi", allocation.IPAddress)
+	}
+	meta.SetStatusCondition(&reservation.Status.Conditions, metav1.Condition{
+		Type:               ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Reserved",
+		Message:            message,
+		ObservedGeneration: reservation.Generation,
+	})
+
+	if err := r.Status().Update(ctx, reservation); err != nil {
+		logger.Error(err, "unable to update UnifiIPReservation status")
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("reserved IP address",
+		"pool", pool.Name,
+		"address", allocation.IPAddress,
+		"mac", allocation.MacAddress)
+
+	return ctrl.Result{RequeueAfter: reservationRecheckInterval}, nil
+}
+
+// reconcileDelete releases the Unifi fixed IP assignment and removes the finalizer.
+func (r *UnifiIPReservationReconciler) reconcileDelete(ctx context.Context, reservation *v1beta2.UnifiIPReservation, logger logr.Logger) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(reservation, ReleaseReservationFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if reservation.Status.MACAddress != "" {
+		if err := r.releaseReservation(ctx, reservation, logger); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(reservation, ReleaseReservationFinalizer)
+	if err := r.Update(ctx, reservation); err != nil {
+		logger.Error(err, "unable to remove finalizer")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *UnifiIPReservationReconciler) releaseReservation(ctx context.Context, reservation *v1beta2.UnifiIPReservation, logger logr.Logger) error {
+	pool := &v1beta2.UnifiIPPool{}
+	poolKey := types.NamespacedName{Namespace: reservation.Namespace, Name: reservation.Spec.PoolRef.Name}
+	if err := r.Get(ctx, poolKey, pool); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Without the pool there is no instance to release against.
+			logger.Info("pool not found, skipping release of Unifi reservation", "pool", poolKey)
+			return nil
+		}
+		return err
+	}
+
+	instance, err := r.getUnifiInstance(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	unifiClient, err := newUnifiClient(ctx, r.Client, instance, credentialsNamespace(pool, instance), r.DryRun)
+	if err != nil {
+		return fmt.Errorf("failed to create Unifi client: %w", err)
+	}
+
+	// A MAC from the spec belongs to a real device, which keeps its Unifi client
+	// and only loses the fixed IP. Generated MACs have no device behind them.
+	if reservation.Spec.MACAddress != "" {
+		err = unifiClient.ClearFixedIP(ctx, reservation.Status.MACAddress)
+	} else {
+		err = unifiClient.ReleaseIP(ctx, poolNetworkID(pool), reservation.Status.Address, reservation.Status.MACAddress)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release IP: %w", err)
+	}
+
+	logger.Info("released IP address",
+		"address", reservation.Status.Address,
+		"mac", reservation.Status.MACAddress)
+
+	return nil
+}
+
+// setNotReady records why the reservation is not ready and requeues.
+func (r *UnifiIPReservationReconciler) setNotReady(ctx context.Context, reservation *v1beta2.UnifiIPReservation, reason, message string, logger logr.Logger) (ctrl.Result, error) {
+	logger.Info("reservation not ready", "reason", reason, "message", message)
+
+	ready := false
+	reservation.Status.Ready = &ready
+	meta.SetStatusCondition(&reservation.Status.Conditions, metav1.Condition{
+		Type:               ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: reservation.Generation,
+	})
+
+	if err := r.Status().Update(ctx, reservation); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+func (r *UnifiIPReservationReconciler) getUnifiInstance(ctx context.Context, pool *v1beta2.UnifiIPPool) (*v1beta2.UnifiInstance, error) {
+	instance := &v1beta2.UnifiInstance{}
+	instanceKey := types.NamespacedName{
+		Name:      pool.Spec.InstanceRef.Name,
+		Namespace: pool.Spec.InstanceRef.Namespace,
+	}
+	if instanceKey.Namespace == "" {
+		instanceKey.Namespace = pool.Namespace
+	}
+
+	if err := r.Get(ctx, instanceKey, instance); err != nil {
+		return nil, fmt.Errorf("failed to fetch UnifiInstance: %w", err)
+	}
+
+	return instance, nil
+}
+
+// reservationRequest builds the allocation request for a reservation.
+func reservationRequest(reservation *v1beta2.UnifiIPReservation) unifi.AllocationRequest {
+	macAddress := reservation.Spec.MACAddress
+	if macAddress == "" {
+		macAddress = unifi.GenerateMACAddress(reservationMACSeedPrefix + reservation.Namespace + "/" + reservation.Name)
+	}
+
+	hostname := reservation.Spec.Hostname
+	if hostname == "" {
+		hostname = reservation.Name
+	}
+
+	return unifi.AllocationRequest{
+		Name:        reservation.Name,
+		Namespace:   reservation.Namespace,
+		RequestedIP: reservation.Spec.Address,
+		MACAddress:  macAddress,
+		Hostname:    hostname,
+	}
+}
+
+// reservedAddresses maps the addresses of the reservations, except the one named
+// skip, to the namespace/name of their reservation.
+func reservedAddresses(reservations []v1beta2.UnifiIPReservation, skip string) map[string]string {
+	reserved := make(map[string]string, len(reservations))
+	for _, reservation := range reservations {
+		if reservation.Name == skip {
+			continue
+		}
+		reserved[reservation.Status.Address] = reservation.Namespace + "/" + reservation.Name
+	}
+	return reserved
+}
+
+// poolContains reports whether the pool may still hand out the address.
+func poolContains(pool v1beta2.GenericUnifiIPPool, address string) (bool, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false, nil
+	}
+	allocator, err := poolutil.NewAllocator(pool.PoolSpec(), nil)
+	if err != nil {
+		return false, err
+	}
+	return allocator.Contains(addr), nil
+}
+
+// poolNetworkID returns the configured or discovered Unifi network ID of a pool.
+func poolNetworkID(pool v1beta2.GenericUnifiIPPool) string {
+	if pool.PoolSpec().NetworkID != "" {
+		return pool.PoolSpec().NetworkID
+	}
+	return pool.PoolStatus().DiscoveredNetworkID
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *UnifiIPReservationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta2.UnifiIPReservation{}).
+		Complete(r)
+}
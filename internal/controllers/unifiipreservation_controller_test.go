@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+)
+
+func TestReservationRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		reservation *v1beta2.UnifiIPReservation
+		want        unifi.AllocationRequest
+	}{
+		{
+			name: "defaults",
+			reservation: &v1beta2.UnifiIPReservation{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb-vip"},
+			},
+			want: unifi.AllocationRequest{
+				Name:       "lb-vip",
+				Namespace:  "default",
+				MACAddress: unifi.GenerateMACAddress("unifiipreservation/default/lb-vip"),
+				Hostname:   "lb-vip",
+			},
+		},
+		{
+			name: "explicit address, hostname and MAC",
+			reservation: &v1beta2.UnifiIPReservation{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nas"},
+				Spec: v1beta2.UnifiIPReservationSpec{
+					Address:    "10.1.40.20",
+					Hostname:   "nas01",
+					MACAddress: "aa:bb:cc:dd:ee:ff",
+				},
+			},
+			want: unifi.AllocationRequest{
+				Name:        "nas",
+				Namespace:   "default",
+				RequestedIP: "10.1.40.20",
+				MACAddress:  "aa:bb:cc:dd:ee:ff",
+				Hostname:    "nas01",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reservationRequest(tt.reservation); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reservationRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReservationMACDiffersFromClaimMAC(t *testing.T) {
+	reservation := &v1beta2.UnifiIPReservation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "worker-0"},
+	}
+	if got := reservationRequest(reservation).MACAddress; got == generateMACAddress("default", "worker-0") {
+		t.Errorf("reservation MAC %s collides with claim MAC of the same name", got)
+	}
+}
+
+func TestReservedAddresses(t *testing.T) {
+	reservations := []v1beta2.UnifiIPReservation{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nas"},
+			Status:     v1beta2.UnifiIPReservationStatus{Address: "10.1.40.20"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "printer"},
+			Status:     v1beta2.UnifiIPReservationStatus{Address: "10.1.40.21"},
+		},
+	}
+
+	want := map[string]string{"10.1.40.21": "default/printer"}
+	if got := reservedAddresses(reservations, "nas"); !reflect.DeepEqual(got, want) {
+		t.Errorf("reservedAddresses() = %v, want %v", got, want)
+	}
+}
+
+func TestPoolContains(t *testing.T) {
+	pool := &v1beta2.UnifiIPPool{
+		Spec: v1beta2.UnifiIPPoolSpec{
+			Subnets: []v1beta2.SubnetSpec{{CIDR: "10.1.40.0/24", ExcludeRanges: []string{"10.1.40.200-10.1.40.254"}}},
+		},
+	}
+
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{address: "10.1.40.20", want: true},
+		{address: "10.1.40.210"},
+		{address: "10.1.41.20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := poolContains(pool, tt.address)
+			if err != nil {
+				t.Fatalf("poolContains() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("poolContains(%s) = %v, want %v", tt.address, got, tt.want)
+			}
+		})
+	}
+}
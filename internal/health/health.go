@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package health reports the reachability of the Unifi controllers in the manager's
+// readiness probe and on a debug endpoint.
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+)
+
+// DebugPath is where DebugHandler is served on the metrics server.
+const DebugPath = "/debug/unifi"
+
+// ReadinessMode decides whether unreachable Unifi controllers fail the readiness probe.
+type ReadinessMode string
+
+const (
+	// ReadinessInformational logs unreachable controllers but keeps the pod ready.
+	ReadinessInformational ReadinessMode = "informational"
+
+	// ReadinessGating reports the pod as not ready while a controller is unreachable.
+	ReadinessGating ReadinessMode = "gating"
+)
+
+// ParseReadinessMode parses the value of the --unifi-readiness flag.
+func ParseReadinessMode(s string) (ReadinessMode, error) {
+	switch mode := ReadinessMode(s); mode {
+	case ReadinessInformational, ReadinessGating:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown readiness mode %q, must be %q or %q", s, ReadinessInformational, ReadinessGating)
+	}
+}
+
+// UnifiChecker returns a readiness check that fails while the latest call to any
+// UnifiInstance failed. In informational mode the failure is only logged.
+func UnifiChecker(tracker *unifi.HealthTracker, mode ReadinessMode, logger logr.Logger) healthz.Checker {
+	return func(_ *http.Request) error {
+		var unhealthy []string
+		for _, instance := range tracker.Instances() {
+			if !instance.Healthy() {
+				unhealthy = append(unhealthy, fmt.Sprintf("%s: %s", instance.Instance, instance.LastError))
+			}
+		}
+		if len(unhealthy) == 0 {
+			return nil
+		}
+
+		err := fmt.Errorf("unifi controllers unreachable: %s", strings.Join(unhealthy, "; "))
+		if mode != ReadinessGating {
+			logger.V(1).Info("ignoring unreachable Unifi controllers for readiness", "error", err.Error())
+			return nil
+		}
+		return err
+	}
+}
+
+// InstanceStatus is the debug view of a UnifiInstance.
+type InstanceStatus struct {
+	unifi.InstanceHealth `json:",inline"`
+
+	// Host is the URL of the Unifi controller.
+	Host string `json:"host"`
+
+	// Ready is the Ready status of the UnifiInstance.
+	Ready bool `json:"ready"`
+
+	// Healthy reports whether the latest API call succeeded; unset if no call was made yet.
+	Healthy *bool `json:"healthy,omitempty"`
+}
+
+// DebugHandler serves the UnifiInstances and the outcome of their latest API calls as JSON.
+func DebugHandler(reader client.Reader, tracker *unifi.HealthTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		instances := &v1beta2.UnifiInstanceList{}
+		if err := reader.List(req.Context(), instances); err != nil {
+			http.Error(w, fmt.Sprintf("failed to list UnifiInstances: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		statuses := make([]InstanceStatus, 0, len(instances.Items))
+		for i := range instances.Items {
+			statuses = append(statuses, instanceStatus(&instances.Items[i], tracker))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(statuses)
+	})
+}
+
+func instanceStatus(instance *v1beta2.UnifiInstance, tracker *unifi.HealthTracker) InstanceStatus {
+	key := client.ObjectKeyFromObject(instance).String()
+	status := InstanceStatus{
+		InstanceHealth: unifi.InstanceHealth{Instance: key},
+		Host:           instance.Spec.Host,
+		Ready:          instance.Status.Ready != nil && *instance.Status.Ready,
+	}
+	if health, ok := tracker.Get(key); ok {
+		healthy := health.Healthy()
+		status.InstanceHealth = health
+		status.Healthy = &healthy
+	}
+	return status
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+)
+
+func TestParseReadinessMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ReadinessMode
+		wantErr bool
+	}{
+		{value: "informational", want: ReadinessInformational},
+		{value: "gating", want: ReadinessGating},
+		{value: "strict", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseReadinessMode(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReadinessMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseReadinessMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    ReadinessMode
+		lastErr error
+		wantErr bool
+	}{
+		{name: "gating with reachable controller", mode: ReadinessGating},
+		{name: "gating with unreachable controller", mode: ReadinessGating, lastErr: errors.New("timeout"), wantErr: true},
+		{name: "informational with unreachable controller", mode: ReadinessInformational, lastErr: errors.New("timeout")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := unifi.NewHealthTracker()
+			tracker.Record("default/reachable", nil, time.Now())
+			tracker.Record("default/other", tt.lastErr, time.Now())
+
+			err := UnifiChecker(tracker, tt.mode, logr.Discard())(nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("check error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDebugHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	ready := true
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1beta2.UnifiInstance{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "called"},
+			Spec:       v1beta2.UnifiInstanceSpec{Host: "https://unifi.example.com"},
+			Status:     v1beta2.UnifiInstanceStatus{Ready: &ready},
+		},
+		&v1beta2.UnifiInstance{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "idle"},
+		},
+	).Build()
+
+	tracker := unifi.NewHealthTracker()
+	tracker.Record("default/called", errors.New("timeout"), time.Now())
+
+	rec := httptest.NewRecorder()
+	DebugHandler(reader, tracker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got []InstanceStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d instances, want 2", len(got))
+	}
+
+	called, idle := got[0], got[1]
+	if called.Instance != "default/called" || !called.Ready || called.Host != "https://unifi.example.com" ||
+		called.Healthy == nil || *called.Healthy || called.LastError != "timeout" {
+		t.Errorf("called instance = %+v", called)
+	}
+	if idle.Instance != "default/idle" || idle.Ready || idle.Healthy != nil || idle.LastSuccess != nil {
+		t.Errorf("idle instance = %+v", idle)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the provider. They are
+// registered with the controller-runtime registry and served on the manager's
+// metrics endpoint.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+const namespace = "unifi_ipam"
+
+var poolLabels = []string{"kind", "namespace", "pool"}
+
+var (
+	poolAddressesTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "pool_addresses_total",
+		Help:      "Number of allocatable addresses of a pool.",
+	}, poolLabels)
+
+	poolAddressesUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "pool_addresses_used",
+		Help:      "Number of addresses of a pool in use by claims and reservations.",
+	}, poolLabels)
+
+	poolAddressesFree = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "pool_addresses_free",
+		Help:      "Number of free addresses of a pool.",
+	}, poolLabels)
+
+	poolUtilization = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "pool_utilization_ratio",
+		Help:      "Ratio of used to allocatable addresses of a pool (0-1).",
+	}, poolLabels)
+
+	allocationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "allocations_total",
+		Help:      "Number of addresses allocated, by how the address was chosen.",
+	}, append(poolLabels, "source"))
+
+	releasesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "releases_total",
+		Help:      "Number of addresses released.",
+	}, poolLabels)
+
+	allocationFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "allocation_failures_total",
+		Help:      "Number of failed allocations, by the reason reported on the claim.",
+	}, append(poolLabels, "reason"))
+
+	driftDetectionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "drift_detections_total",
+		Help:      "Number of syncs that found the pool drifted from the Unifi network configuration.",
+	}, poolLabels)
+
+	unifiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "unifi_api_request_duration_seconds",
+		Help:      "Latency of Unifi API calls.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"operation", "instance"})
+
+	unifiRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unifi_api_errors_total",
+		Help:      "Number of failed Unifi API calls.",
+	}, []string{"operation", "instance"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		poolAddressesTotal,
+		poolAddressesUsed,
+		poolAddressesFree,
+		poolUtilization,
+		allocationsTotal,
+		releasesTotal,
+		allocationFailuresTotal,
+		driftDetectionsTotal,
+		unifiRequestDuration,
+		unifiRequestErrors,
+	)
+}
+
+// PoolRef identifies the pool a metric is recorded for.
+type PoolRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (p PoolRef) labels(extra ...string) []string {
+	return append([]string{p.Kind, p.Namespace, p.Name}, extra...)
+}
+
+// RecordPoolStatus publishes the address counts of a pool.
+func RecordPoolStatus(pool PoolRef, summary *v1beta2.IPAddressStatusSummary) {
+	if summary == nil {
+		return
+	}
+	total, used, free := value(summary.Total), value(summary.Used), value(summary.Free)
+
+	poolAddressesTotal.WithLabelValues(pool.labels()...).Set(total)
+	poolAddressesUsed.WithLabelValues(pool.labels()...).Set(used)
+	poolAddressesFree.WithLabelValues(pool.labels()...).Set(free)
+
+	utilization := 0.0
+	if total > 0 {
+		utilization = used / total
+	}
+	poolUtilization.WithLabelValues(pool.labels()...).Set(utilization)
+}
+
+// DeletePool removes the metrics of a deleted pool.
+func DeletePool(pool PoolRef) {
+	match := prometheus.Labels{"kind": pool.Kind, "namespace": pool.Namespace, "pool": pool.Name}
+	poolAddressesTotal.DeletePartialMatch(match)
+	poolAddressesUsed.DeletePartialMatch(match)
+	poolAddressesFree.DeletePartialMatch(match)
+	poolUtilization.DeletePartialMatch(match)
+	allocationsTotal.DeletePartialMatch(match)
+	releasesTotal.DeletePartialMatch(match)
+	allocationFailuresTotal.DeletePartialMatch(match)
+	driftDetectionsTotal.DeletePartialMatch(match)
+}
+
+// RecordAllocation counts an allocated address.
+func RecordAllocation(pool PoolRef, source string) {
+	allocationsTotal.WithLabelValues(pool.labels(source)...).Inc()
+}
+
+// RecordRelease counts a released address.
+func RecordRelease(pool PoolRef) {
+	releasesTotal.WithLabelValues(pool.labels()...).Inc()
+}
+
+// RecordAllocationFailure counts a failed allocation.
+func RecordAllocationFailure(pool PoolRef, reason string) {
+	allocationFailuresTotal.WithLabelValues(pool.labels(reason)...).Inc()
+}
+
+// RecordDrift counts a sync that detected configuration drift.
+func RecordDrift(pool PoolRef) {
+	driftDetectionsTotal.WithLabelValues(pool.labels()...).Inc()
+}
+
+// ObserveUnifiRequest records the latency and outcome of a Unifi API call started at start.
+func ObserveUnifiRequest(operation, instance string, start time.Time, failed bool) {
+	unifiRequestDuration.WithLabelValues(operation, instance).Observe(time.Since(start).Seconds())
+	if failed {
+		unifiRequestErrors.WithLabelValues(operation, instance).Inc()
+	}
+}
+
+func value(v *int32) float64 {
+	if v == nil {
+		return 0
+	}
+	return float64(*v)
+}
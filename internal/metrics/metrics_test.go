@@ -0,0 +1,135 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestRecordPoolStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		summary         *v1beta2.IPAddressStatusSummary
+		wantTotal       float64
+		wantUsed        float64
+		wantFree        float64
+		wantUtilization float64
+	}{
+		{
+			name:            "partially used pool",
+			summary:         &v1beta2.IPAddressStatusSummary{Total: int32Ptr(10), Used: int32Ptr(4), Free: int32Ptr(6)},
+			wantTotal:       10,
+			wantUsed:        4,
+			wantFree:        6,
+			wantUtilization: 0.4,
+		},
+		{
+			name:    "empty pool",
+			summary: &v1beta2.IPAddressStatusSummary{Total: int32Ptr(0), Used: int32Ptr(0), Free: int32Ptr(0)},
+		},
+		{
+			name:    "unset counts",
+			summary: &v1beta2.IPAddressStatusSummary{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := PoolRef{Kind: "UnifiIPPool", Namespace: "default", Name: tt.name}
+			RecordPoolStatus(pool, tt.summary)
+
+			labels := pool.labels()
+			if got := testutil.ToFloat64(poolAddressesTotal.WithLabelValues(labels...)); got != tt.wantTotal {
+				t.Errorf("total = %v, want %v", got, tt.wantTotal)
+			}
+			if got := testutil.ToFloat64(poolAddressesUsed.WithLabelValues(labels...)); got != tt.wantUsed {
+				t.Errorf("used = %v, want %v", got, tt.wantUsed)
+			}
+			if got := testutil.ToFloat64(poolAddressesFree.WithLabelValues(labels...)); got != tt.wantFree {
+				t.Errorf("free = %v, want %v", got, tt.wantFree)
+			}
+			if got := testutil.ToFloat64(poolUtilization.WithLabelValues(labels...)); got != tt.wantUtilization {
+				t.Errorf("utilization = %v, want %v", got, tt.wantUtilization)
+			}
+		})
+	}
+}
+
+func TestDeletePool(t *testing.T) {
+	pool := PoolRef{Kind: "GlobalUnifiIPPool", Name: "deleted"}
+	other := PoolRef{Kind: "GlobalUnifiIPPool", Name: "kept"}
+
+	RecordPoolStatus(pool, &v1beta2.IPAddressStatusSummary{Total: int32Ptr(4), Used: int32Ptr(1), Free: int32Ptr(3)})
+	RecordPoolStatus(other, &v1beta2.IPAddressStatusSummary{Total: int32Ptr(4), Used: int32Ptr(1), Free: int32Ptr(3)})
+	RecordAllocation(pool, "Dynamic")
+	RecordAllocationFailure(pool, "PoolExhausted")
+	RecordRelease(pool)
+	RecordDrift(pool)
+
+	before := testutil.CollectAndCount(poolAddressesTotal)
+	DeletePool(pool)
+
+	if got := testutil.CollectAndCount(poolAddressesTotal); got != before-1 {
+		t.Errorf("pool_addresses_total series = %d, want %d", got, before-1)
+	}
+	for name, c := range map[string]int{
+		"allocations_total":         testutil.CollectAndCount(allocationsTotal),
+		"releases_total":            testutil.CollectAndCount(releasesTotal),
+		"allocation_failures_total": testutil.CollectAndCount(allocationFailuresTotal),
+		"drift_detections_total":    testutil.CollectAndCount(driftDetectionsTotal),
+	} {
+		if c != 0 {
+			t.Errorf("%s has %d series after delete, want 0", name, c)
+		}
+	}
+	if got := testutil.ToFloat64(poolAddressesTotal.WithLabelValues(other.labels()...)); got != 4 {
+		t.Errorf("total of other pool = %v, want 4", got)
+	}
+}
+
+func TestObserveUnifiRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		failed     bool
+		wantErrors float64
+	}{
+		{name: "success", failed: false, wantErrors: 0},
+		{name: "failure", failed: true, wantErrors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ObserveUnifiRequest("ListUser", tt.name, time.Now(), tt.failed)
+
+			if got := testutil.ToFloat64(unifiRequestErrors.WithLabelValues("ListUser", tt.name)); got != tt.wantErrors {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+			}
+		})
+	}
+	if got := testutil.CollectAndCount(unifiRequestDuration); got != len(tests) {
+		t.Errorf("duration series = %d, want %d", got, len(tests))
+	}
+}
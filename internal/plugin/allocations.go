@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+const (
+	// reservationInSync is the Unifi state of an allocation whose reservation matches it.
+	reservationInSync = "Reserved"
+	// reservationUnchecked is the Unifi state of allocations listed with --offline.
+	reservationUnchecked = "-"
+)
+
+// allocation is a row of the allocations table.
+type allocation struct {
+	Namespace string
+	Owner     string
+	Address   string
+	MAC       string
+	Cluster   string
+	Unifi     string
+
+	expected unifi.ExpectedReservation
+}
+
+func newAllocationsCommand(o *options) *cobra.Command {
+	var global, offline bool
+	cmd := &cobra.Command{
+		Use:   "allocations POOL",
+		Short: "List the addresses allocated from a pool with the state of their Unifi reservations",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			pool, err := o.getPool(ctx, args[0], global)
+			if err != nil {
+				return err
+			}
+			addresses, reservations, err := o.addressesInUse(ctx, pool)
+			if err != nil {
+				return err
+			}
+
+			allocations := poolAllocations(addresses, reservations, pool.PoolStatus().UserGroupID)
+			if !offline {
+				if err := o.checkAllocations(ctx, pool, allocations); err != nil {
+					return err
+				}
+			}
+			return printAllocations(cmd.OutOrStdout(), allocations)
+		},
+	}
+	cmd.Flags().BoolVar(&global, "global", false, "List the allocations of a GlobalUnifiIPPool.")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Do not check the reservations against Unifi.")
+	return cmd
+}
+
+// poolAllocations returns the allocations of the pool's IPAddresses and UnifiIPReservations
+// ordered by address. Reservations of claims are expected in the pool's user group.
+func poolAllocations(addresses []ipamv1beta2.IPAddress, reservations []v1beta2.UnifiIPReservation, userGroupID string) []allocation {
+	expected := unifi.ExpectedReservations(addresses, userGroupID)
+	allocations := make([]allocation, 0, len(expected)+len(reservations))
+	for i := range addresses {
+		address := &addresses[i]
+		index := slices.IndexFunc(expected, func(e unifi.ExpectedReservation) bool {
+			return e.ClaimName == address.Spec.ClaimRef.Name && e.Address == address.Spec.Address
+		})
+		if index < 0 {
+			// Addresses being deleted or without a recorded MAC have no reservation to check.
+			continue
+		}
+		allocations = append(allocations, allocation{
+			Namespace: address.Namespace,
+			Owner:     "IPAddressClaim/" + address.Spec.ClaimRef.Name,
+			Address:   address.Spec.Address,
+			MAC:       expected[index].MAC,
+			Cluster:   address.Labels[clusterv1beta2.ClusterNameLabel],
+			Unifi:     reservationUnchecked,
+			expected:  expected[index],
+		})
+	}
+	for _, reservation := range reservations {
+		allocations = append(allocations, allocation{
+			Namespace: reservation.Namespace,
+			Owner:     "UnifiIPReservation/" + reservation.Name,
+			Address:   reservation.Status.Address,
+			MAC:       reservation.Status.MACAddress,
+			Unifi:     reservationUnchecked,
+			expected: unifi.ExpectedReservation{
+				Address:  reservation.Status.Address,
+				MAC:      reservation.Status.MACAddress,
+				Hostname: reservation.Spec.Hostname,
+			},
+		})
+	}
+
+	slices.SortFunc(allocations, func(a, b allocation) int {
+		return compareAddresses(a.Address, b.Address)
+	})
+	return allocations
+}
+
+// checkAllocations sets the Unifi state of the allocations from their reservations.
+func (o *options) checkAllocations(ctx context.Context, pool v1beta2.GenericUnifiIPPool, allocations []allocation) error {
+	unifiClient, err := o.newUnifiClient(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	expected := make([]unifi.ExpectedReservation, 0, len(allocations))
+	for _, a := range allocations {
+		expected = append(expected, a.expected)
+	}
+	drifts, err := unifiClient.CheckReservations(ctx, poolNetworkID(pool), expected)
+	if err != nil {
+		return err
+	}
+
+	setReservationStates(allocations, drifts)
+	return nil
+}
+
+// setReservationStates records the drift of each allocation's reservation as its Unifi
+// state. Allocations without drift are in sync.
+func setReservationStates(allocations []allocation, drifts []unifi.ReservationDrift) {
+	for i := range allocations {
+		allocations[i].Unifi = reservationInSync
+		if allocations[i].expected.Adopted {
+			allocations[i].Unifi += " (adopted)"
+		}
+		for _, drift := range drifts {
+			if drift.MAC != allocations[i].expected.MAC || drift.Address != allocations[i].Address {
+				continue
+			}
+			allocations[i].Unifi = string(drift.Reason)
+			if drift.Observed != "" {
+				allocations[i].Unifi += fmt.Sprintf(" (%s)", drift.Observed)
+			}
+		}
+	}
+}
+
+// printAllocations writes the allocations as a table.
+func printAllocations(out io.Writer, allocations []allocation) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tOWNER\tADDRESS\tMAC\tCLUSTER\tUNIFI")
+	for _, a := range allocations {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.Namespace, a.Owner, a.Address, a.MAC, valueOrDash(a.Cluster), a.Unifi)
+	}
+	return w.Flush()
+}
+
+// valueOrDash returns the value, or a dash if it is empty.
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,590 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go4.org/netipx"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/ipamutil"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// inClusterPoolGroupVersion is the API of the pools of CAPI's in-cluster IPAM provider.
+var inClusterPoolGroupVersion = schema.GroupVersion{Group: "ipam.cluster.x-k8s.io", Version: "v1alpha2"}
+
+const (
+	inClusterIPPoolKind       = "InClusterIPPool"
+	globalInClusterIPPoolKind = "GlobalInClusterIPPool"
+
+	// claimDeletionTimeout is how long migrate waits for a replaced claim to be gone.
+	claimDeletionTimeout = time.Minute
+)
+
+// inClusterPoolSpec is the spec of an InClusterIPPool or GlobalInClusterIPPool.
+type inClusterPoolSpec struct {
+	Addresses                   []string `json:"addresses"`
+	Prefix                      int      `json:"prefix"`
+	Gateway                     string   `json:"gateway,omitempty"`
+	ExcludedAddresses           []string `json:"excludedAddresses,omitempty"`
+	AllocateReservedIPAddresses bool     `json:"allocateReservedIPAddresses,omitempty"`
+}
+
+// migratedClaim is a claim of the in-cluster pool and the address it holds.
+type migratedClaim struct {
+	claim   *ipamv1beta2.IPAddressClaim
+	address *ipamv1beta2.IPAddress
+	mac     string
+}
+
+func newMigrateCommand(o *options) *cobra.Command {
+	var global, dryRun bool
+	var instance, name, networkID, userGroup string
+	cmd := &cobra.Command{
+		Use:   "migrate POOL --instance [NAMESPACE/]NAME",
+		Short: "Move an InClusterIPPool and its claims to a UnifiIPPool",
+		Long: "Converts an InClusterIPPool (or GlobalInClusterIPPool with --global) to a UnifiIPPool,\n" +
+			"reserves the addresses of its claims in Unifi and recreates the claims against the new\n" +
+			"pool, so they keep their addresses. The clusters of the claims must be paused.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+
+			instanceRef, err := parseInstanceRef(instance, global)
+			if err != nil {
+				return err
+			}
+			source, spec, err := o.getInClusterPool(ctx, args[0], global)
+			if err != nil {
+				return err
+			}
+			subnets, err := convertInClusterPool(spec)
+			if err != nil {
+				return fmt.Errorf("failed to convert %s %s: %w", source.GetKind(), args[0], err)
+			}
+
+			if name == "" {
+				name = source.GetName()
+			}
+			pool := newMigratedPool(source.GetNamespace(), name, global)
+			poolSpec := pool.PoolSpec()
+			poolSpec.InstanceRef = instanceRef
+			poolSpec.NetworkID = networkID
+			poolSpec.Subnets = subnets
+			prefix := int32(spec.Prefix)
+			poolSpec.Prefix = &prefix
+			poolSpec.Gateway = spec.Gateway
+			poolSpec.UserGroup = userGroup
+
+			claims, err := o.inClusterPoolClaims(ctx, source)
+			if err != nil {
+				return err
+			}
+			if err := checkMigratedAddresses(poolSpec, claims); err != nil {
+				return err
+			}
+			if err := o.checkClustersPaused(ctx, claims); err != nil {
+				return err
+			}
+
+			if err := printMigration(out, pool, claims); err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Fprintf(out, "Dry run: would create %s %s and recreate %d claims\n", pool.PoolKind(), poolName(pool), len(claims))
+				return nil
+			}
+
+			// Reserve the addresses in Unifi before touching any claim, so a failure leaves
+			// the claims on the in-cluster pool.
+			unifiClient, err := o.newUnifiClient(ctx, pool)
+			if err != nil {
+				return err
+			}
+			if networkID == "" {
+				network, err := unifiClient.FindNetworkForSubnet(ctx, subnetNetwork(subnets[0], spec.Prefix))
+				if err != nil {
+					return err
+				}
+				networkID = network.ID
+			}
+			poolSpec.NetworkID = networkID
+			// Reservations get the pool's user group, as the pool controller resolves it.
+			var userGroupID string
+			if poolSpec.UserGroup != "" {
+				group, err := unifiClient.ResolveUserGroup(ctx, poolSpec.UserGroup)
+				if err != nil {
+					return fmt.Errorf("failed to resolve user group %q: %w", poolSpec.UserGroup, err)
+				}
+				userGroupID = group.ID
+			}
+			if err := reserveMigratedAddresses(ctx, unifiClient, networkID, userGroupID, claims); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Reserved %d addresses on Unifi network %s\n", countAddresses(claims), networkID)
+
+			if err := o.client.Create(ctx, pool); err != nil {
+				if !apierrors.IsAlreadyExists(err) {
+					return fmt.Errorf("failed to create %s %s: %w", pool.PoolKind(), poolName(pool), err)
+				}
+				fmt.Fprintf(out, "%s %s already exists, keeping its spec\n", pool.PoolKind(), poolName(pool))
+			} else {
+				fmt.Fprintf(out, "Created %s %s\n", pool.PoolKind(), poolName(pool))
+			}
+
+			poolRef := ipamv1beta2.IPPoolReference{
+				APIGroup: v1beta2.GroupVersion.Group,
+				Kind:     pool.PoolKind(),
+				Name:     pool.GetName(),
+			}
+			for _, c := range claims {
+				if err := o.recreateClaim(ctx, c, poolRef); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Recreated IPAddressClaim %s/%s\n", c.claim.Namespace, c.claim.Name)
+			}
+
+			fmt.Fprintf(out, "Point the infrastructure templates using %s %s at %s %s before unpausing the clusters\n",
+				source.GetKind(), source.GetName(), pool.PoolKind(), pool.GetName())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&instance, "instance", "", "The UnifiInstance of the new pool as [NAMESPACE/]NAME.")
+	cmd.Flags().StringVar(&name, "name", "", "The name of the new pool. Defaults to the name of the migrated pool.")
+	cmd.Flags().StringVar(&networkID, "network-id", "", "The Unifi network of the pool. Discovered from the subnets if not set.")
+	cmd.Flags().StringVar(&userGroup, "user-group", "", "The Unifi user group (name or ID) of the pool's reservations.")
+	cmd.Flags().BoolVar(&global, "global", false, "Migrate a GlobalInClusterIPPool to a GlobalUnifiIPPool.")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print the pool and claims that would be migrated.")
+	_ = cmd.MarkFlagRequired("instance")
+	return cmd
+}
+
+// parseInstanceRef parses a [NAMESPACE/]NAME reference to a UnifiInstance.
+// Global pools must name the namespace of the instance.
+func parseInstanceRef(instance string, global bool) (corev1.ObjectReference, error) {
+	ref := corev1.ObjectReference{Name: instance}
+	if namespace, name, found := strings.Cut(instance, "/"); found {
+		ref = corev1.ObjectReference{Namespace: namespace, Name: name}
+	}
+	if ref.Name == "" {
+		return ref, fmt.Errorf("invalid instance %q: must be [NAMESPACE/]NAME", instance)
+	}
+	if global && ref.Namespace == "" {
+		return ref, fmt.Errorf("instance %q of a global pool must be given as NAMESPACE/NAME", instance)
+	}
+	return ref, nil
+}
+
+// getInClusterPool fetches the InClusterIPPool in the namespace, or the
+// GlobalInClusterIPPool if global is set, and its spec.
+func (o *options) getInClusterPool(ctx context.Context, name string, global bool) (*unstructured.Unstructured, inClusterPoolSpec, error) {
+	source := &unstructured.Unstructured{}
+	key := types.NamespacedName{Namespace: o.namespace, Name: name}
+	source.SetGroupVersionKind(inClusterPoolGroupVersion.WithKind(inClusterIPPoolKind))
+	if global {
+		source.SetGroupVersionKind(inClusterPoolGroupVersion.WithKind(globalInClusterIPPoolKind))
+		key.Namespace = ""
+	}
+
+	spec := inClusterPoolSpec{}
+	if err := o.client.Get(ctx, key, source); err != nil {
+		return nil, spec, fmt.Errorf("failed to get %s %s: %w", source.GetKind(), name, err)
+	}
+	content, ok := source.UnstructuredContent()["spec"].(map[string]any)
+	if !ok {
+		return nil, spec, fmt.Errorf("%s %s has no spec", source.GetKind(), name)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &spec); err != nil {
+		return nil, spec, fmt.Errorf("failed to read spec of %s %s: %w", source.GetKind(), name, err)
+	}
+	return source, spec, nil
+}
+
+// newMigratedPool returns an empty UnifiIPPool, or GlobalUnifiIPPool if global is set.
+func newMigratedPool(namespace, name string, global bool) v1beta2.GenericUnifiIPPool {
+	if global {
+		return &v1beta2.GlobalUnifiIPPool{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	return &v1beta2.UnifiIPPool{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+// convertInClusterPool converts the addresses of an in-cluster pool to subnets. Every
+// continuous range of addresses becomes a subnet, written as a CIDR when it is exactly
+// one network of the pool's prefix, with the excluded addresses it contains. Unless the
+// pool allocates reserved addresses, the network and broadcast addresses stay excluded.
+func convertInClusterPool(spec inClusterPoolSpec) ([]v1beta2.SubnetSpec, error) {
+	if spec.Prefix < 1 || spec.Prefix > 32 {
+		return nil, fmt.Errorf("prefix %d is not an IPv4 prefix length, only IPv4 pools can be migrated", spec.Prefix)
+	}
+
+	addresses, err := parseAddressRanges(spec.Addresses)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses.Ranges()) == 0 {
+		return nil, fmt.Errorf("pool has no addresses")
+	}
+	excluded, err := parseAddressRanges(spec.ExcludedAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	subnets := make([]v1beta2.SubnetSpec, 0, len(addresses.Ranges()))
+	for _, r := range addresses.Ranges() {
+		var subnet v1beta2.SubnetSpec
+		var exclude netipx.IPSetBuilder
+		exclude.AddSet(excluded)
+
+		if prefix, ok := r.Prefix(); ok && prefix.Bits() == spec.Prefix && !spec.AllocateReservedIPAddresses {
+			// CIDR subnets never allocate their network and broadcast addresses.
+			subnet.CIDR = prefix.String()
+		} else {
+			subnet.Start, subnet.End = r.From().String(), r.To().String()
+			if !spec.AllocateReservedIPAddresses && spec.Prefix < 31 {
+				for _, addr := range []netip.Addr{r.From(), r.To()} {
+					network := netipx.RangeOfPrefix(netip.PrefixFrom(addr, spec.Prefix).Masked())
+					exclude.Add(network.From())
+					exclude.Add(network.To())
+				}
+			}
+		}
+
+		exclude.Intersect(rangeSet(r))
+		excludeSet, err := exclude.IPSet()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range excludeSet.Ranges() {
+			subnet.ExcludeRanges = append(subnet.ExcludeRanges, poolutil.FormatExcludeRange(e))
+		}
+		subnets = append(subnets, subnet)
+	}
+	return subnets, nil
+}
+
+// parseAddressRanges parses addresses given as single IPs, CIDRs or "start-end" ranges.
+func parseAddressRanges(entries []string) (*netipx.IPSet, error) {
+	var builder netipx.IPSetBuilder
+	for _, entry := range entries {
+		r, err := poolutil.ParseExcludeRange(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", entry, err)
+		}
+		if !r.From().Is4() {
+			return nil, fmt.Errorf("address %q is not IPv4, only IPv4 pools can be migrated", entry)
+		}
+		builder.AddRange(r)
+	}
+	return builder.IPSet()
+}
+
+// rangeSet returns the set of addresses in a range.
+func rangeSet(r netipx.IPRange) *netipx.IPSet {
+	var builder netipx.IPSetBuilder
+	builder.AddRange(r)
+	set, _ := builder.IPSet()
+	return set
+}
+
+// subnetNetwork returns the network CIDR of a subnet with the pool's prefix.
+func subnetNetwork(subnet v1beta2.SubnetSpec, prefix int) string {
+	if subnet.CIDR != "" {
+		return subnet.CIDR
+	}
+	start, err := netip.ParseAddr(subnet.Start)
+	if err != nil {
+		return ""
+	}
+	return netip.PrefixFrom(start, prefix).Masked().String()
+}
+
+// inClusterPoolClaims returns the IPAddressClaims of an in-cluster pool with their
+// IPAddresses, ordered by namespace and name.
+func (o *options) inClusterPoolClaims(ctx context.Context, source *unstructured.Unstructured) ([]migratedClaim, error) {
+	addresses, err := poolutil.ListAddressesInUse(ctx, o.client, source.GetNamespace(),
+		source.GetName(), source.GetKind(), inClusterPoolGroupVersion.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	claimList := &ipamv1beta2.IPAddressClaimList{}
+	if err := o.client.List(ctx, claimList, client.InNamespace(source.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list IPAddressClaims: %w", err)
+	}
+
+	var claims []migratedClaim
+	for i := range claimList.Items {
+		claim := &claimList.Items[i]
+		poolRef := claim.Spec.PoolRef
+		if poolRef.APIGroup != inClusterPoolGroupVersion.Group || poolRef.Kind != source.GetKind() || poolRef.Name != source.GetName() {
+			continue
+		}
+		c := migratedClaim{claim: claim}
+		for j := range addresses {
+			if addresses[j].Namespace == claim.Namespace && addresses[j].Spec.ClaimRef.Name == claim.Name {
+				c.address = &addresses[j]
+				c.mac = unifi.ClaimMACAddress(claim.Namespace, claim.Name)
+			}
+		}
+		claims = append(claims, c)
+	}
+
+	slices.SortFunc(claims, func(a, b migratedClaim) int {
+		return strings.Compare(a.claim.Namespace+"/"+a.claim.Name, b.claim.Namespace+"/"+b.claim.Name)
+	})
+	return claims, nil
+}
+
+// checkMigratedAddresses returns an error if an address held by a claim cannot be
+// allocated from the converted pool, or if an address or MAC is held twice.
+func checkMigratedAddresses(spec *v1beta2.UnifiIPPoolSpec, claims []migratedClaim) error {
+	allocator, err := poolutil.NewAllocator(spec, nil)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]string{}
+	seenMACs := map[string]string{}
+	for _, c := range claims {
+		if c.address == nil {
+			continue
+		}
+		claimName := c.claim.Namespace + "/" + c.claim.Name
+		addr, err := netip.ParseAddr(c.address.Spec.Address)
+		if err != nil {
+			return fmt.Errorf("claim %s holds invalid address %q: %w", claimName, c.address.Spec.Address, err)
+		}
+		if !allocator.Contains(addr) {
+			return fmt.Errorf("address %s of claim %s is not allocatable from the converted pool", addr, claimName)
+		}
+		if other, ok := seen[addr.String()]; ok {
+			return fmt.Errorf("address %s is held by claims %s and %s", addr, other, claimName)
+		}
+		seen[addr.String()] = claimName
+
+		mac := strings.ToLower(c.mac)
+		if other, ok := seenMACs[mac]; ok {
+			return fmt.Errorf("claims %s and %s would both be reserved for MAC %s", other, claimName, c.mac)
+		}
+		seenMACs[mac] = claimName
+	}
+	return nil
+}
+
+// checkClustersPaused returns an error if a cluster of the claims is not paused, as its
+// infrastructure provider could recreate a claim against the old pool while it is replaced.
+func (o *options) checkClustersPaused(ctx context.Context, claims []migratedClaim) error {
+	var unpaused []string
+	checked := map[types.NamespacedName]bool{}
+	for _, c := range claims {
+		name := c.claim.Spec.ClusterName
+		if name == "" {
+			name = c.claim.Labels[clusterv1beta2.ClusterNameLabel]
+		}
+		key := types.NamespacedName{Namespace: c.claim.Namespace, Name: name}
+		if name == "" || checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		cluster := &clusterv1beta2.Cluster{}
+		if err := o.client.Get(ctx, key, cluster); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get Cluster %s: %w", key, err)
+		}
+		if cluster.Spec.Paused == nil || !*cluster.Spec.Paused {
+			unpaused = append(unpaused, key.String())
+		}
+	}
+
+	if len(unpaused) > 0 {
+		return fmt.Errorf("clusters %s are not paused, set spec.paused on them before migrating their claims", strings.Join(unpaused, ", "))
+	}
+	return nil
+}
+
+// printMigration prints the converted pool and the claims that move to it.
+func printMigration(out io.Writer, pool v1beta2.GenericUnifiIPPool, claims []migratedClaim) error {
+	spec := pool.PoolSpec()
+	fmt.Fprintf(out, "%s %s (instance %s, prefix %d, gateway %s)\n", pool.PoolKind(), poolName(pool),
+		spec.InstanceRef.Name, deref(spec.Prefix), valueOrDash(spec.Gateway))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SUBNET\tEXCLUDED")
+	for _, subnet := range spec.Subnets {
+		name := subnet.CIDR
+		if name == "" {
+			name = subnet.Start + "-" + subnet.End
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, valueOrDash(strings.Join(subnet.ExcludeRanges, ",")))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLAIM\tADDRESS\tMAC")
+	for _, c := range claims {
+		address := ""
+		if c.address != nil {
+			address = c.address.Spec.Address
+		}
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\n", c.claim.Namespace, c.claim.Name, valueOrDash(address), valueOrDash(c.mac))
+	}
+	return w.Flush()
+}
+
+// reserveMigratedAddresses creates or corrects the Unifi reservations of the claims'
+// addresses in the user group, if any. It fails without changes if an address is
+// reserved for another client.
+func reserveMigratedAddresses(ctx context.Context, unifiClient *unifi.Client, networkID, userGroupID string, claims []migratedClaim) error {
+	assignments, err := unifiClient.GetStaticAssignments(ctx, networkID)
+	if err != nil {
+		return err
+	}
+
+	var expected []unifi.ExpectedReservation
+	for _, c := range claims {
+		if c.address == nil {
+			continue
+		}
+		for _, sa := range assignments {
+			if sa.IP == c.address.Spec.Address && !strings.EqualFold(sa.MAC, c.mac) {
+				return fmt.Errorf("address %s of claim %s/%s is reserved in Unifi for %s",
+					sa.IP, c.claim.Namespace, c.claim.Name, sa.MAC)
+			}
+		}
+		expected = append(expected, unifi.ExpectedReservation{
+			Address:   c.address.Spec.Address,
+			MAC:       c.mac,
+			Hostname:    c.claim.Name,
+			ClaimName:   c.claim.Name,
+			UserGroupID: userGroupID,
+		})
+	}
+
+	drifts, err := unifiClient.CheckReservations(ctx, networkID, expected)
+	if err != nil {
+		return err
+	}
+	for _, drift := range drifts {
+		if err := unifiClient.RepairReservation(ctx, networkID, userGroupID, drift); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countAddresses returns how many of the claims hold an address.
+func countAddresses(claims []migratedClaim) int {
+	n := 0
+	for _, c := range claims {
+		if c.address != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// recreateClaim replaces a claim of the in-cluster pool with one referencing the Unifi
+// pool. The specs of IPAddressClaims and IPAddresses are immutable, so the claim and its
+// address are deleted, dropping the IPAM finalizers, and the claim is created again with
+// the same name, labels, annotations and owners. The Unifi reservation made for the
+// claim's MAC then hands it its previous address.
+func (o *options) recreateClaim(ctx context.Context, c migratedClaim, poolRef ipamv1beta2.IPPoolReference) error {
+	claimName := c.claim.Namespace + "/" + c.claim.Name
+	if c.address != nil {
+		if err := o.deleteWithoutIPAMFinalizers(ctx, c.address); err != nil {
+			return fmt.Errorf("failed to delete IPAddress %s/%s: %w", c.address.Namespace, c.address.Name, err)
+		}
+	}
+	if err := o.deleteWithoutIPAMFinalizers(ctx, c.claim); err != nil {
+		return fmt.Errorf("failed to delete IPAddressClaim %s: %w", claimName, err)
+	}
+
+	err := wait.PollUntilContextTimeout(ctx, time.Second, claimDeletionTimeout, true, func(ctx context.Context) (bool, error) {
+		err := o.client.Get(ctx, client.ObjectKeyFromObject(c.claim), &ipamv1beta2.IPAddressClaim{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("IPAddressClaim %s was not deleted: %w", claimName, err)
+	}
+
+	replacement := &ipamv1beta2.IPAddressClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       c.claim.Namespace,
+			Name:            c.claim.Name,
+			Labels:          c.claim.Labels,
+			Annotations:     c.claim.Annotations,
+			OwnerReferences: c.claim.OwnerReferences,
+		},
+		Spec: *c.claim.Spec.DeepCopy(),
+	}
+	replacement.Spec.PoolRef = poolRef
+	if err := o.client.Create(ctx, replacement); err != nil {
+		return fmt.Errorf("failed to recreate IPAddressClaim %s: %w", claimName, err)
+	}
+	return nil
+}
+
+// deleteWithoutIPAMFinalizers removes the finalizers of the in-cluster provider from an
+// object and deletes it.
+func (o *options) deleteWithoutIPAMFinalizers(ctx context.Context, obj client.Object) error {
+	base, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("failed to copy %s", obj.GetName())
+	}
+	removed := controllerutil.RemoveFinalizer(obj, ipamutil.ReleaseAddressFinalizer)
+	removed = controllerutil.RemoveFinalizer(obj, ipamutil.ProtectAddressFinalizer) || removed
+	if removed {
+		if err := o.client.Patch(ctx, obj, client.MergeFrom(base)); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	return client.IgnoreNotFound(o.client.Delete(ctx, obj))
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin implements kubectl-unifi-ipam, a kubectl plugin to inspect and
+// operate the pools of the Unifi IPAM provider.
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// options are the settings shared by all commands.
+type options struct {
+	kubeconfig string
+	context    string
+	namespace  string
+
+	// client talks to the cluster. It is built from the kubeconfig unless set.
+	client client.Client
+
+	// newUnifiClient connects to the Unifi controller of a pool.
+	newUnifiClient func(ctx context.Context, pool v1beta2.GenericUnifiIPPool) (*unifi.Client, error)
+}
+
+// NewCommand returns the kubectl-unifi-ipam root command.
+func NewCommand() *cobra.Command {
+	return newRootCommand(&options{})
+}
+
+func newRootCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "kubectl-unifi-ipam",
+		Short:        "Inspect and operate Unifi IPAM pools",
+		SilenceUsage: true,
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return o.complete()
+		},
+	}
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use.")
+	flags.StringVar(&o.context, "context", "", "The name of the kubeconfig context to use.")
+	flags.StringVarP(&o.namespace, "namespace", "n", "", "The namespace of the pool or claim. Defaults to the namespace of the context.")
+
+	cmd.AddCommand(
+		newPoolCommand(o),
+		newAllocationsCommand(o),
+		newWhoisCommand(o),
+		newSnapshotCommand(o),
+		newReleaseCommand(o),
+		newMigrateCommand(o),
+	)
+	return cmd
+}
+
+// complete builds the clients and resolves the namespace from the kubeconfig.
+func (o *options) complete() error {
+	if o.newUnifiClient == nil {
+		o.newUnifiClient = o.unifiClientForPool
+	}
+	if o.client != nil {
+		if o.namespace == "" {
+			o.namespace = corev1.NamespaceDefault
+		}
+		return nil
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = o.kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: o.context})
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	if o.namespace == "" {
+		if o.namespace, _, err = clientConfig.Namespace(); err != nil {
+			return fmt.Errorf("failed to resolve namespace: %w", err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		v1beta2.AddToScheme,
+		clusterv1beta2.AddToScheme,
+		ipamv1beta2.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			return fmt.Errorf("failed to build scheme: %w", err)
+		}
+	}
+
+	o.client, err = client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return nil
+}
+
+// getPool fetches the UnifiIPPool in the namespace, or the GlobalUnifiIPPool if global is set.
+func (o *options) getPool(ctx context.Context, name string, global bool) (v1beta2.GenericUnifiIPPool, error) {
+	var pool v1beta2.GenericUnifiIPPool = &v1beta2.UnifiIPPool{}
+	key := types.NamespacedName{Namespace: o.namespace, Name: name}
+	if global {
+		pool = &v1beta2.GlobalUnifiIPPool{}
+		key.Namespace = ""
+	}
+
+	if err := o.client.Get(ctx, key, pool); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", pool.PoolKind(), name, err)
+	}
+	return pool, nil
+}
+
+// getClaimPool fetches the pool an IPAddressClaim references.
+func (o *options) getClaimPool(ctx context.Context, claim *ipamv1beta2.IPAddressClaim) (v1beta2.GenericUnifiIPPool, error) {
+	switch claim.Spec.PoolRef.Kind {
+	case v1beta2.GlobalUnifiIPPoolKind:
+		return o.getPool(ctx, claim.Spec.PoolRef.Name, true)
+	case v1beta2.UnifiIPPoolKind:
+		return o.getPool(ctx, claim.Spec.PoolRef.Name, false)
+	default:
+		return nil, fmt.Errorf("claim %s references a %s, not a Unifi pool", claim.Name, claim.Spec.PoolRef.Kind)
+	}
+}
+
+// addressesInUse returns the IPAddresses and UnifiIPReservations of the pool.
+func (o *options) addressesInUse(ctx context.Context, pool v1beta2.GenericUnifiIPPool) ([]ipamv1beta2.IPAddress, []v1beta2.UnifiIPReservation, error) {
+	addresses, err := poolutil.ListAddressesInUse(ctx, o.client, pool.GetNamespace(),
+		pool.GetName(), pool.PoolKind(), v1beta2.GroupVersion.Group)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reservations reference pools in their own namespace only.
+	if pool.GetNamespace() == "" {
+		return addresses, nil, nil
+	}
+	reservations, err := poolutil.ListReservationsInUse(ctx, o.client, pool.GetNamespace(), pool.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
+	return addresses, reservations, nil
+}
+
+// unifiClientForPool connects to the UnifiInstance of the pool with the credentials
+// the provider uses for it.
+func (o *options) unifiClientForPool(ctx context.Context, pool v1beta2.GenericUnifiIPPool) (*unifi.Client, error) {
+	instanceRef := pool.PoolSpec().InstanceRef
+	instance := &v1beta2.UnifiInstance{}
+	key := types.NamespacedName{Namespace: instanceRef.Namespace, Name: instanceRef.Name}
+	if key.Namespace == "" {
+		key.Namespace = pool.GetNamespace()
+	}
+	if err := o.client.Get(ctx, key, instance); err != nil {
+		return nil, fmt.Errorf("failed to get UnifiInstance %s: %w", key, err)
+	}
+
+	secret := &corev1.Secret{}
+	secretKey := types.NamespacedName{Namespace: pool.GetNamespace(), Name: instance.Spec.CredentialsRef.Name}
+	if secretKey.Namespace == "" {
+		secretKey.Namespace = instance.Namespace
+	}
+	if err := o.client.Get(ctx, secretKey, secret); err != nil {
+		return nil, fmt.Errorf("failed to get credentials secret %s: %w", secretKey, err)
+	}
+
+	cfg := unifi.Config{
+		Host:     instance.Spec.Host,
+		APIKey:   string(secret.Data["apiKey"]),
+		Instance: key.String(),
+	}
+	if instance.Spec.Site != nil {
+		cfg.Site = *instance.Spec.Site
+	}
+	if instance.Spec.Insecure != nil {
+		cfg.Insecure = *instance.Spec.Insecure
+	}
+	if instance.Spec.DryRun != nil {
+		cfg.DryRun = *instance.Spec.DryRun
+	}
+	return unifi.NewClient(cfg)
+}
+
+// poolNetworkID returns the configured or discovered Unifi network of the pool.
+func poolNetworkID(pool v1beta2.GenericUnifiIPPool) string {
+	if networkID := pool.PoolSpec().NetworkID; networkID != "" {
+		return networkID
+	}
+	return pool.PoolStatus().DiscoveredNetworkID
+}
+
+// poolName returns the namespace/name of a namespaced pool or the name of a global one.
+func poolName(pool v1beta2.GenericUnifiIPPool) string {
+	if pool.GetNamespace() == "" {
+		return pool.GetName()
+	}
+	return pool.GetNamespace() + "/" + pool.GetName()
+}
+
+// compareAddresses orders IP addresses numerically, and unparsable ones last.
+func compareAddresses(a, b string) int {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return 1
+	case errB != nil:
+		return -1
+	default:
+		return addrA.Compare(addrB)
+	}
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
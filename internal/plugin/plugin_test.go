@@ -0,0 +1,473 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/unifi"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/ipamutil"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func testPool() *v1beta2.UnifiIPPool {
+	return &v1beta2.UnifiIPPool{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster-pool"},
+		Spec: v1beta2.UnifiIPPoolSpec{
+			NetworkID: "net-1",
+			Subnets: []v1beta2.SubnetSpec{
+				{CIDR: "10.1.40.0/28", Gateway: "10.1.40.1"},
+			},
+		},
+		Status: v1beta2.UnifiIPPoolStatus{
+			Allocations: map[string]string{"web-0": "10.1.40.2", "db-0": "10.1.40.3"},
+			ReleasedAddresses: []v1beta2.ReleasedAddress{
+				{Address: "10.1.40.9", ClaimName: "old-0", ReleasedAt: metav1.Now()},
+			},
+		},
+	}
+}
+
+func testAddress(name, ip, cluster string) *ipamv1beta2.IPAddress {
+	address := &ipamv1beta2.IPAddress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels:    map[string]string{clusterv1beta2.ClusterNameLabel: cluster},
+		},
+		Spec: ipamv1beta2.IPAddressSpec{
+			ClaimRef: ipamv1beta2.IPAddressClaimReference{Name: name},
+			PoolRef: ipamv1beta2.IPPoolReference{
+				APIGroup: v1beta2.GroupVersion.Group,
+				Kind:     v1beta2.UnifiIPPoolKind,
+				Name:     "cluster-pool",
+			},
+			Address: ip,
+		},
+	}
+	poolutil.SetAddressMAC(address, unifi.ClaimMACAddress("default", name))
+	return address
+}
+
+// runCommand runs the plugin with args against a fake cluster holding objs.
+func runCommand(t *testing.T, objs []client.Object, args ...string) (string, client.Client, error) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		corev1.AddToScheme,
+		v1beta2.AddToScheme,
+		clusterv1beta2.AddToScheme,
+		ipamv1beta2.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	o := &options{
+		client: c,
+		newUnifiClient: func(context.Context, v1beta2.GenericUnifiIPPool) (*unifi.Client, error) {
+			t.Fatal("unexpected connection to Unifi")
+			return nil, nil
+		},
+	}
+	cmd := newRootCommand(o)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), c, err
+}
+
+func TestPoolShow(t *testing.T) {
+	out, _, err := runCommand(t, []client.Object{
+		testPool(),
+		testAddress("web-0", "10.1.40.2", "web"),
+		testAddress("db-0", "10.1.40.3", "db"),
+	}, "pool", "show", "cluster-pool")
+	if err != nil {
+		t.Fatalf("pool show failed: %v\n%s", err, out)
+	}
+
+	for _, want := range []string{
+		"default/cluster-pool (UnifiIPPool)",
+		"2 used, 11 free of 13",
+		"10.1.40.0/28  13     2     11    10.1.40.4-10.1.40.14",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("pool show output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAllocationsOffline(t *testing.T) {
+	adopted := testAddress("legacy-0", "10.1.40.4", "")
+	adopted.Annotations = map[string]string{poolutil.AdoptedMACAnnotation: "aa:bb:cc:dd:ee:ff"}
+
+	out, _, err := runCommand(t, []client.Object{
+		testPool(),
+		testAddress("web-0", "10.1.40.12", "web"),
+		testAddress("db-0", "10.1.40.3", "db"),
+		adopted,
+	}, "allocations", "cluster-pool", "--offline")
+	if err != nil {
+		t.Fatalf("allocations failed: %v\n%s", err, out)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("allocations printed %d lines, want a header and 3 rows:\n%s", len(lines), out)
+	}
+	for i, want := range []string{"IPAddressClaim/db-0", "IPAddressClaim/legacy-0", "IPAddressClaim/web-0"} {
+		if !strings.Contains(lines[i+1], want) {
+			t.Errorf("row %d = %q, want %s ordered by address", i, lines[i+1], want)
+		}
+	}
+	if !strings.Contains(lines[2], "aa:bb:cc:dd:ee:ff") {
+		t.Errorf("adopted row = %q, want the adopted MAC", lines[2])
+	}
+}
+
+func TestSetReservationStates(t *testing.T) {
+	allocations := []allocation{
+		{Address: "10.1.40.2", expected: unifi.ExpectedReservation{Address: "10.1.40.2", MAC: "02:00:00:00:00:01"}},
+		{Address: "10.1.40.3", expected: unifi.ExpectedReservation{Address: "10.1.40.3", MAC: "02:00:00:00:00:02"}},
+		{Address: "10.1.40.4", expected: unifi.ExpectedReservation{Address: "10.1.40.4", MAC: "aa:bb:cc:dd:ee:ff", Adopted: true}},
+	}
+	drifts := []unifi.ReservationDrift{{
+		ExpectedReservation: allocations[1].expected,
+		Reason:              v1beta2.ReservationIPChanged,
+		Observed:            "10.1.40.99",
+	}}
+
+	setReservationStates(allocations, drifts)
+
+	got := []string{allocations[0].Unifi, allocations[1].Unifi, allocations[2].Unifi}
+	want := []string{"Reserved", "IPChanged (10.1.40.99)", "Reserved (adopted)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reservation states = %q, want %q", got, want)
+	}
+}
+
+func TestWhois(t *testing.T) {
+	objs := []client.Object{
+		testPool(),
+		testAddress("web-0", "10.1.40.2", "web"),
+		&v1beta2.UnifiIPReservation{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ingress-vip"},
+			Spec:       v1beta2.UnifiIPReservationSpec{PoolRef: corev1.LocalObjectReference{Name: "cluster-pool"}},
+			Status:     v1beta2.UnifiIPReservationStatus{Address: "10.1.40.5", MACAddress: "02:00:00:00:00:05"},
+		},
+	}
+
+	tests := []struct {
+		ip   string
+		want []string
+	}{
+		{ip: "10.1.40.2", want: []string{"allocated to claim web-0", "IPAddress", "cluster web"}},
+		{ip: "10.1.40.5", want: []string{"UnifiIPReservation", "default/ingress-vip"}},
+		{ip: "10.1.40.9", want: []string{"quarantined since", "released by claim old-0"}},
+		{ip: "10.1.40.1", want: []string{"excluded from allocation"}},
+		{ip: "10.1.40.7", want: []string{"free"}},
+		{ip: "192.168.1.1", want: []string{"No pool, claim or reservation owns 192.168.1.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			out, _, err := runCommand(t, objs, "whois", tt.ip)
+			if err != nil {
+				t.Fatalf("whois failed: %v\n%s", err, out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("whois output is missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	pool := testPool()
+	pool.Spec.PreAllocations = map[string]string{"other-0": "10.1.40.10"}
+	objs := []client.Object{
+		pool,
+		testAddress("web-0", "10.1.40.2", "web"),
+		testAddress("db-0", "10.1.40.3", "db"),
+	}
+
+	out, c, err := runCommand(t, objs, "snapshot", "cluster-pool", "--cluster", "web")
+	if err != nil {
+		t.Fatalf("snapshot failed: %v\n%s", err, out)
+	}
+
+	updated := &v1beta2.UnifiIPPool{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "cluster-pool"}, updated); err != nil {
+		t.Fatalf("failed to get pool: %v", err)
+	}
+	want := map[string]string{"other-0": "10.1.40.10", "web-0": "10.1.40.2"}
+	if !reflect.DeepEqual(updated.Spec.PreAllocations, want) {
+		t.Errorf("preAllocations = %v, want %v", updated.Spec.PreAllocations, want)
+	}
+
+	if _, _, err := runCommand(t, objs, "snapshot", "cluster-pool", "--cluster", "missing"); err == nil {
+		t.Error("snapshot of a cluster without allocations succeeded, want error")
+	}
+}
+
+func TestClusterAllocationsGlobal(t *testing.T) {
+	web := testAddress("web-0", "10.1.40.2", "web")
+	other := testAddress("web-0", "10.1.40.3", "web")
+	other.Namespace = "team-b"
+	allocations := map[string]string{"default/web-0": "10.1.40.2", "team-b/web-0": "10.1.40.3"}
+
+	got := clusterAllocations("", allocations, []ipamv1beta2.IPAddress{*web, *other}, "web")
+	if !reflect.DeepEqual(got, allocations) {
+		t.Errorf("clusterAllocations() = %v, want %v", got, allocations)
+	}
+}
+
+func TestReleaseDryRun(t *testing.T) {
+	claim := &ipamv1beta2.IPAddressClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"},
+		Spec: ipamv1beta2.IPAddressClaimSpec{PoolRef: ipamv1beta2.IPPoolReference{
+			APIGroup: v1beta2.GroupVersion.Group,
+			Kind:     v1beta2.UnifiIPPoolKind,
+			Name:     "cluster-pool",
+		}},
+	}
+
+	out, _, err := runCommand(t, []client.Object{testPool(), claim, testAddress("web-0", "10.1.40.2", "web")},
+		"release", "web-0", "--dry-run")
+	if err != nil {
+		t.Fatalf("release failed: %v\n%s", err, out)
+	}
+	want := "Dry run: would delete the Unifi fixed IP 10.1.40.2 for MAC " + unifi.ClaimMACAddress("default", "web-0")
+	if !strings.Contains(out, want) {
+		t.Errorf("release output = %q, want %q", out, want)
+	}
+
+	unlabeled := testAddress("web-0", "10.1.40.2", "web")
+	delete(unlabeled.Labels, poolutil.MACAddressLabel)
+	out, _, err = runCommand(t, []client.Object{testPool(), claim, unlabeled}, "release", "web-0", "--dry-run")
+	if err == nil {
+		t.Errorf("release of an address without a recorded MAC succeeded:\n%s", out)
+	}
+}
+
+func TestConvertInClusterPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    inClusterPoolSpec
+		want    []v1beta2.SubnetSpec
+		wantErr bool
+	}{
+		{
+			name: "whole network as CIDR",
+			spec: inClusterPoolSpec{Addresses: []string{"10.1.40.0/24"}, Prefix: 24, Gateway: "10.1.40.1"},
+			want: []v1beta2.SubnetSpec{{CIDR: "10.1.40.0/24"}},
+		},
+		{
+			name: "ranges and single addresses with exclusions",
+			spec: inClusterPoolSpec{
+				Addresses:         []string{"10.1.40.10-10.1.40.20", "10.1.40.21", "10.1.40.50-10.1.40.60"},
+				Prefix:            24,
+				ExcludedAddresses: []string{"10.1.40.15", "10.1.40.58-10.1.40.70", "10.1.41.0/24"},
+			},
+			want: []v1beta2.SubnetSpec{
+				{Start: "10.1.40.10", End: "10.1.40.21", ExcludeRanges: []string{"10.1.40.15"}},
+				{Start: "10.1.40.50", End: "10.1.40.60", ExcludeRanges: []string{"10.1.40.58-10.1.40.60"}},
+			},
+		},
+		{
+			name: "network address of a range stays excluded",
+			spec: inClusterPoolSpec{Addresses: []string{"10.1.40.0-10.1.40.100"}, Prefix: 24},
+			want: []v1beta2.SubnetSpec{{Start: "10.1.40.0", End: "10.1.40.100", ExcludeRanges: []string{"10.1.40.0"}}},
+		},
+		{
+			name: "reserved addresses allocated",
+			spec: inClusterPoolSpec{Addresses: []string{"10.1.40.0/25"}, Prefix: 25, AllocateReservedIPAddresses: true},
+			want: []v1beta2.SubnetSpec{{Start: "10.1.40.0", End: "10.1.40.127"}},
+		},
+		{
+			name:    "IPv6",
+			spec:    inClusterPoolSpec{Addresses: []string{"fd00::/64"}, Prefix: 64},
+			wantErr: true,
+		},
+		{
+			name:    "invalid address",
+			spec:    inClusterPoolSpec{Addresses: []string{"10.1.40.300"}, Prefix: 24},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertInClusterPool(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("convertInClusterPool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertInClusterPool() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func inClusterPool() *unstructured.Unstructured {
+	pool := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"addresses": []any{"10.1.40.10-10.1.40.50"},
+			"prefix":    int64(24),
+			"gateway":   "10.1.40.1",
+		},
+	}}
+	pool.SetGroupVersionKind(inClusterPoolGroupVersion.WithKind(inClusterIPPoolKind))
+	pool.SetNamespace("default")
+	pool.SetName("legacy-pool")
+	return pool
+}
+
+func inClusterClaim(name, ip, cluster string) (*ipamv1beta2.IPAddressClaim, *ipamv1beta2.IPAddress) {
+	poolRef := ipamv1beta2.IPPoolReference{
+		APIGroup: inClusterPoolGroupVersion.Group,
+		Kind:     inClusterIPPoolKind,
+		Name:     "legacy-pool",
+	}
+	claim := &ipamv1beta2.IPAddressClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       name,
+			Labels:     map[string]string{clusterv1beta2.ClusterNameLabel: cluster},
+			Finalizers: []string{ipamutil.ReleaseAddressFinalizer},
+		},
+		Spec: ipamv1beta2.IPAddressClaimSpec{PoolRef: poolRef},
+	}
+	address := testAddress(name, ip, cluster)
+	address.Finalizers = []string{ipamutil.ProtectAddressFinalizer}
+	address.Spec.PoolRef = poolRef
+	return claim, address
+}
+
+func TestMigrate(t *testing.T) {
+	webClaim, webAddress := inClusterClaim("web-0", "10.1.40.12", "web")
+	cluster := &clusterv1beta2.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+
+	t.Run("unpaused cluster", func(t *testing.T) {
+		_, _, err := runCommand(t, []client.Object{inClusterPool(), webClaim, webAddress, cluster},
+			"migrate", "legacy-pool", "--instance", "unifi-controller", "--dry-run")
+		if err == nil || !strings.Contains(err.Error(), "default/web are not paused") {
+			t.Errorf("migrate error = %v, want clusters not paused", err)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		paused := cluster.DeepCopy()
+		isPaused := true
+		paused.Spec.Paused = &isPaused
+		out, c, err := runCommand(t, []client.Object{inClusterPool(), webClaim, webAddress, paused},
+			"migrate", "legacy-pool", "--instance", "unifi-controller", "--dry-run")
+		if err != nil {
+			t.Fatalf("migrate failed: %v\n%s", err, out)
+		}
+		for _, want := range []string{
+			"UnifiIPPool default/legacy-pool (instance unifi-controller, prefix 24, gateway 10.1.40.1)",
+			"10.1.40.10-10.1.40.50",
+			"default/web-0  10.1.40.12  " + unifi.ClaimMACAddress("default", "web-0"),
+			"Dry run: would create UnifiIPPool default/legacy-pool and recreate 1 claims",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("migrate output is missing %q:\n%s", want, out)
+			}
+		}
+
+		if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "legacy-pool"}, &v1beta2.UnifiIPPool{}); !apierrors.IsNotFound(err) {
+			t.Errorf("dry run created the UnifiIPPool, get error = %v", err)
+		}
+	})
+
+	t.Run("address outside the converted pool", func(t *testing.T) {
+		claim, address := inClusterClaim("db-0", "10.1.40.200", "")
+		_, _, err := runCommand(t, []client.Object{inClusterPool(), claim, address},
+			"migrate", "legacy-pool", "--instance", "unifi-controller", "--dry-run")
+		if err == nil || !strings.Contains(err.Error(), "not allocatable") {
+			t.Errorf("migrate error = %v, want address not allocatable", err)
+		}
+	})
+
+	t.Run("duplicate MAC", func(t *testing.T) {
+		dbClaim, dbAddress := inClusterClaim("db-0", "10.1.40.13", "")
+		spec := &v1beta2.UnifiIPPoolSpec{Subnets: []v1beta2.SubnetSpec{{Start: "10.1.40.10", End: "10.1.40.50"}}}
+		claims := []migratedClaim{
+			{claim: webClaim, address: webAddress, mac: "02:00:00:00:00:01"},
+			{claim: dbClaim, address: dbAddress, mac: "02:00:00:00:00:01"},
+		}
+		if err := checkMigratedAddresses(spec, claims); err == nil || !strings.Contains(err.Error(), "MAC 02:00:00:00:00:01") {
+			t.Errorf("checkMigratedAddresses() error = %v, want duplicate MAC", err)
+		}
+	})
+}
+
+func TestRecreateClaim(t *testing.T) {
+	claim, address := inClusterClaim("web-0", "10.1.40.12", "web")
+	claim.Annotations = map[string]string{"example.com/note": "kept"}
+	_, c, err := runCommand(t, []client.Object{claim, address}, "--help")
+	if err != nil {
+		t.Fatalf("failed to set up client: %v", err)
+	}
+
+	o := &options{client: c}
+	poolRef := ipamv1beta2.IPPoolReference{APIGroup: v1beta2.GroupVersion.Group, Kind: v1beta2.UnifiIPPoolKind, Name: "legacy-pool"}
+	if err := o.recreateClaim(context.Background(), migratedClaim{claim: claim.DeepCopy(), address: address.DeepCopy()}, poolRef); err != nil {
+		t.Fatalf("recreateClaim() error = %v", err)
+	}
+
+	got := &ipamv1beta2.IPAddressClaim{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "web-0"}, got); err != nil {
+		t.Fatalf("failed to get recreated claim: %v", err)
+	}
+	if got.Spec.PoolRef != poolRef {
+		t.Errorf("poolRef = %+v, want %+v", got.Spec.PoolRef, poolRef)
+	}
+	if !reflect.DeepEqual(got.Labels, claim.Labels) || !reflect.DeepEqual(got.Annotations, claim.Annotations) {
+		t.Errorf("metadata = %v %v, want %v %v", got.Labels, got.Annotations, claim.Labels, claim.Annotations)
+	}
+	if len(got.Finalizers) != 0 {
+		t.Errorf("finalizers = %v, want none", got.Finalizers)
+	}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "web-0"}, &ipamv1beta2.IPAddress{}); !apierrors.IsNotFound(err) {
+		t.Errorf("old IPAddress still exists, get error = %v", err)
+	}
+}
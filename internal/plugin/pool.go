@@ -0,0 +1,114 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func newPoolCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pool",
+		Short: "Inspect pools",
+	}
+
+	var global bool
+	show := &cobra.Command{
+		Use:   "show POOL",
+		Short: "Show the usage and free ranges of each subnet of a pool",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pool, err := o.getPool(cmd.Context(), args[0], global)
+			if err != nil {
+				return err
+			}
+			addresses, reservations, err := o.addressesInUse(cmd.Context(), pool)
+			if err != nil {
+				return err
+			}
+			return printPool(cmd.OutOrStdout(), pool, addresses, reservations)
+		},
+	}
+	show.Flags().BoolVar(&global, "global", false, "Show a GlobalUnifiIPPool.")
+	cmd.AddCommand(show)
+
+	return cmd
+}
+
+// printPool writes a summary of the pool followed by the usage of each of its subnets.
+func printPool(out io.Writer, pool v1beta2.GenericUnifiIPPool, addresses []ipamv1beta2.IPAddress, reservations []v1beta2.UnifiIPReservation) error {
+	spec, status := pool.PoolSpec(), pool.PoolStatus()
+
+	inUse := make([]string, 0, len(addresses)+len(reservations))
+	for _, address := range addresses {
+		inUse = append(inUse, address.Spec.Address)
+	}
+	for _, reservation := range reservations {
+		inUse = append(inUse, reservation.Status.Address)
+	}
+	freeRanges, err := poolutil.SubnetFreeRanges(spec, inUse)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Pool:\t%s (%s)\n", poolName(pool), pool.PoolKind())
+	network := poolNetworkID(pool)
+	if status.NetworkInfo != nil && status.NetworkInfo.Name != "" {
+		network = fmt.Sprintf("%s (%s)", status.NetworkInfo.Name, network)
+	}
+	fmt.Fprintf(w, "Network:\t%s\n", network)
+	if poolIPSet, err := poolutil.PoolIPSet(spec); err == nil {
+		summary := poolutil.ComputePoolStatus(poolIPSet, addresses, reservations, pool.GetNamespace())
+		fmt.Fprintf(w, "Addresses:\t%d used, %d free of %d\n", deref(summary.Used), deref(summary.Free), deref(summary.Total))
+	}
+	for _, conditionType := range []string{"Ready", "Exhausted", "ReservationsSynced"} {
+		if condition := meta.FindStatusCondition(status.Conditions, conditionType); condition != nil {
+			fmt.Fprintf(w, "%s:\t%s\t%s\n", conditionType, condition.Status, condition.Message)
+		}
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "SUBNET\tTOTAL\tUSED\tFREE\tFREE RANGES")
+	for i, subnet := range poolutil.ComputeSubnetStatus(spec, addresses, reservations, pool.GetNamespace()) {
+		if subnet.Total == nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\tinvalid subnet\n", subnet.Subnet)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", subnet.Subnet, *subnet.Total, deref(subnet.Used), deref(subnet.Free),
+			poolutil.FormatRanges(freeRanges[i]))
+	}
+	return w.Flush()
+}
+
+// deref returns the value of an optional count, or 0 if unset.
+func deref(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func newReleaseCommand(o *options) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "release CLAIM",
+		Short: "Delete the Unifi reservation of a claim",
+		Long: "Deletes the Unifi fixed IP of an IPAddressClaim, e.g. when the claim is stuck\n" +
+			"deleting because the provider cannot release it. Adopted reservations are left in Unifi.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+
+			claim := &ipamv1beta2.IPAddressClaim{}
+			if err := o.client.Get(ctx, types.NamespacedName{Namespace: o.namespace, Name: args[0]}, claim); err != nil {
+				return fmt.Errorf("failed to get IPAddressClaim %s: %w", args[0], err)
+			}
+			pool, err := o.getClaimPool(ctx, claim)
+			if err != nil {
+				return err
+			}
+			addresses, _, err := o.addressesInUse(ctx, pool)
+			if err != nil {
+				return err
+			}
+
+			address, mac := "", ""
+			for i := range addresses {
+				a := &addresses[i]
+				if a.Namespace != claim.Namespace || a.Spec.ClaimRef.Name != claim.Name {
+					continue
+				}
+				if mac := a.Annotations[poolutil.AdoptedMACAnnotation]; mac != "" {
+					fmt.Fprintf(out, "%s adopted the Unifi fixed IP %s of %s, leaving it in Unifi\n", claim.Name, a.Spec.Address, mac)
+					return nil
+				}
+				address = a.Spec.Address
+				mac = poolutil.AddressMAC(a)
+			}
+			if mac == "" {
+				return fmt.Errorf("no IPAddress of claim %s records the MAC of its Unifi fixed IP", claim.Name)
+			}
+
+			if dryRun {
+				fmt.Fprintf(out, "Dry run: would delete the Unifi fixed IP %s for MAC %s\n", valueOrDash(address), mac)
+				return nil
+			}
+
+			unifiClient, err := o.newUnifiClient(ctx, pool)
+			if err != nil {
+				return err
+			}
+			if unifiClient.DryRun() {
+				fmt.Fprintf(out, "Dry run: the UnifiInstance is in dry-run mode, would delete the Unifi fixed IP %s for MAC %s\n", valueOrDash(address), mac)
+				return nil
+			}
+			if err := unifiClient.ReleaseIP(ctx, poolNetworkID(pool), address, mac); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Deleted the Unifi fixed IP %s for MAC %s\n", valueOrDash(address), mac)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print the reservation that would be deleted.")
+	return cmd
+}
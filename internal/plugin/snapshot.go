@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func newSnapshotCommand(o *options) *cobra.Command {
+	var global, dryRun bool
+	var cluster string
+	cmd := &cobra.Command{
+		Use:   "snapshot POOL --cluster CLUSTER",
+		Short: "Copy the current allocations of a cluster into the pool's preAllocations",
+		Long: "Copies the entries of status.allocations held by the cluster's claims into\n" +
+			"spec.preAllocations, so the claims of a recreated or upgraded cluster get the same addresses.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			pool, err := o.getPool(ctx, args[0], global)
+			if err != nil {
+				return err
+			}
+			addresses, _, err := o.addressesInUse(ctx, pool)
+			if err != nil {
+				return err
+			}
+
+			snapshot := clusterAllocations(pool.GetNamespace(), pool.PoolStatus().Allocations, addresses, cluster)
+			if len(snapshot) == 0 {
+				return fmt.Errorf("%s %s has no allocations of cluster %s", pool.PoolKind(), poolName(pool), cluster)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CLAIM\tADDRESS\tPREVIOUS")
+			for _, claim := range sortedKeys(snapshot) {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", claim, snapshot[claim], valueOrDash(pool.PoolSpec().PreAllocations[claim]))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "Dry run: %s %s not changed\n", pool.PoolKind(), poolName(pool))
+				return nil
+			}
+
+			base, ok := pool.DeepCopyObject().(client.Object)
+			if !ok {
+				return fmt.Errorf("failed to copy %s %s", pool.PoolKind(), poolName(pool))
+			}
+			spec := pool.PoolSpec()
+			if spec.PreAllocations == nil {
+				spec.PreAllocations = make(map[string]string, len(snapshot))
+			}
+			for claim, address := range snapshot {
+				spec.PreAllocations[claim] = address
+			}
+			if err := o.client.Patch(ctx, pool, client.MergeFrom(base)); err != nil {
+				return fmt.Errorf("failed to update preAllocations of %s %s: %w", pool.PoolKind(), poolName(pool), err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Preallocated %d addresses of cluster %s in %s %s\n",
+				len(snapshot), cluster, pool.PoolKind(), poolName(pool))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cluster, "cluster", "", "The CAPI cluster whose allocations are preallocated.")
+	cmd.Flags().BoolVar(&global, "global", false, "Snapshot a GlobalUnifiIPPool.")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print the preAllocations that would be added.")
+	_ = cmd.MarkFlagRequired("cluster")
+	return cmd
+}
+
+// clusterAllocations returns the entries of the pool's allocations held by the IPAddresses
+// of the cluster.
+func clusterAllocations(poolNamespace string, allocations map[string]string, addresses []ipamv1beta2.IPAddress, cluster string) map[string]string {
+	snapshot := map[string]string{}
+	for i := range addresses {
+		address := &addresses[i]
+		if address.Labels[clusterv1beta2.ClusterNameLabel] != cluster {
+			continue
+		}
+		claim := poolutil.AllocationKey(poolNamespace, address)
+		if allocated, ok := allocations[claim]; ok && allocated == address.Spec.Address {
+			snapshot[claim] = allocated
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/netip"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func newWhoisCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "whois IP",
+		Short: "Find the pools, claims and reservations that own an address",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ip, err := netip.ParseAddr(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid IP address %q: %w", args[0], err)
+			}
+			owners, err := o.whois(cmd.Context(), ip, time.Now())
+			if err != nil {
+				return err
+			}
+			return printOwners(cmd.OutOrStdout(), ip, owners)
+		},
+	}
+}
+
+// owner is an object that owns or contains an address.
+type owner struct {
+	Kind   string
+	Name   string
+	Detail string
+}
+
+// whois returns the pools whose subnets contain the address and the objects holding it,
+// across all namespaces.
+func (o *options) whois(ctx context.Context, ip netip.Addr, now time.Time) ([]owner, error) {
+	var pools []v1beta2.GenericUnifiIPPool
+	poolList := &v1beta2.UnifiIPPoolList{}
+	if err := o.client.List(ctx, poolList); err != nil {
+		return nil, fmt.Errorf("failed to list UnifiIPPools: %w", err)
+	}
+	for i := range poolList.Items {
+		pools = append(pools, &poolList.Items[i])
+	}
+	globalPoolList := &v1beta2.GlobalUnifiIPPoolList{}
+	if err := o.client.List(ctx, globalPoolList); err != nil {
+		return nil, fmt.Errorf("failed to list GlobalUnifiIPPools: %w", err)
+	}
+	for i := range globalPoolList.Items {
+		pools = append(pools, &globalPoolList.Items[i])
+	}
+
+	var owners []owner
+	for _, pool := range pools {
+		if detail, ok := poolOwnsAddress(pool, ip, now); ok {
+			owners = append(owners, owner{Kind: pool.PoolKind(), Name: poolName(pool), Detail: detail})
+		}
+	}
+
+	addressList := &ipamv1beta2.IPAddressList{}
+	if err := o.client.List(ctx, addressList); err != nil {
+		return nil, fmt.Errorf("failed to list IPAddresses: %w", err)
+	}
+	for _, address := range addressList.Items {
+		if address.Spec.Address != ip.String() {
+			continue
+		}
+		detail := fmt.Sprintf("claim %s from %s %s", address.Spec.ClaimRef.Name, address.Spec.PoolRef.Kind, address.Spec.PoolRef.Name)
+		if cluster := address.Labels[clusterv1beta2.ClusterNameLabel]; cluster != "" {
+			detail += ", cluster " + cluster
+		}
+		if mac := address.Annotations[poolutil.AdoptedMACAnnotation]; mac != "" {
+			detail += ", adopted from " + mac
+		}
+		owners = append(owners, owner{Kind: "IPAddress", Name: address.Namespace + "/" + address.Name, Detail: detail})
+	}
+
+	reservationList := &v1beta2.UnifiIPReservationList{}
+	if err := o.client.List(ctx, reservationList); err != nil {
+		return nil, fmt.Errorf("failed to list UnifiIPReservations: %w", err)
+	}
+	for _, reservation := range reservationList.Items {
+		if reservation.Status.Address != ip.String() {
+			continue
+		}
+		owners = append(owners, owner{
+			Kind:   "UnifiIPReservation",
+			Name:   reservation.Namespace + "/" + reservation.Name,
+			Detail: fmt.Sprintf("pool %s, MAC %s", reservation.Spec.PoolRef.Name, reservation.Status.MACAddress),
+		})
+	}
+
+	return owners, nil
+}
+
+// poolOwnsAddress reports whether the address lies in one of the pool's subnets and
+// describes its state in the pool.
+func poolOwnsAddress(pool v1beta2.GenericUnifiIPPool, ip netip.Addr, now time.Time) (string, bool) {
+	spec, status := pool.PoolSpec(), pool.PoolStatus()
+	if !poolutil.IPInSubnets(ip.String(), spec.Subnets, poolutil.DefaultPrefix(spec)) {
+		return "", false
+	}
+
+	address := ip.String()
+	if poolIPSet, err := poolutil.PoolIPSet(spec); err == nil && !poolIPSet.Contains(ip) {
+		return "excluded from allocation", true
+	}
+	for claim, allocated := range status.Allocations {
+		if allocated == address {
+			return "allocated to claim " + claim, true
+		}
+	}
+	for name, preallocated := range spec.PreAllocations {
+		if preallocated == address {
+			return "preallocated to claim " + name, true
+		}
+	}
+	for _, released := range status.ReleasedAddresses {
+		if released.Address == address {
+			return fmt.Sprintf("quarantined since %s, released by claim %s", released.ReleasedAt.UTC().Format(time.RFC3339), released.ClaimName), true
+		}
+	}
+	for _, lease := range status.Leases {
+		if lease.Address == address && lease.ExpiresAt != nil && lease.ExpiresAt.After(now) {
+			return fmt.Sprintf("leased to sticky key %s until %s", lease.Key, lease.ExpiresAt.UTC().Format(time.RFC3339)), true
+		}
+	}
+	return "free", true
+}
+
+// printOwners writes the owners of the address as a table.
+func printOwners(out io.Writer, ip netip.Addr, owners []owner) error {
+	if len(owners) == 0 {
+		_, err := fmt.Fprintf(out, "No pool, claim or reservation owns %s\n", ip)
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tDETAIL")
+	for _, o := range owners {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", o.Kind, o.Name, o.Detail)
+	}
+	return w.Flush()
+}
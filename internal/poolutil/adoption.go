@@ -0,0 +1,123 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+const (
+	// AdoptMACAnnotation on a claim adopts the Unifi fixed IP of the client with this MAC.
+	AdoptMACAnnotation = "unifi.ipam.cluster.x-k8s.io/adopt-mac"
+
+	// AdoptHostnameAnnotation on a claim adopts the Unifi fixed IP of the client with
+	// this hostname or name.
+	AdoptHostnameAnnotation = "unifi.ipam.cluster.x-k8s.io/adopt-hostname"
+
+	// AdoptedMACAnnotation records on an IPAddress the MAC of the Unifi client whose
+	// fixed IP it adopted. The fixed IP is left in Unifi when the address is released.
+	AdoptedMACAnnotation = "unifi.ipam.cluster.x-k8s.io/adopted-mac"
+)
+
+// ErrAmbiguousAdoption is returned when several Unifi fixed IPs match a claim.
+var ErrAmbiguousAdoption = errors.New("several Unifi fixed IPs match")
+
+// AdoptionCandidate is a fixed IP reserved in Unifi that a claim may adopt.
+type AdoptionCandidate struct {
+	IP       string
+	MAC      string
+	Hostname string
+	Name     string
+}
+
+// AdoptionRequest describes which Unifi fixed IP a claim adopts.
+type AdoptionRequest struct {
+	// MAC matches the client's MAC address.
+	MAC string
+	// Hostnames match the client's hostname or name.
+	Hostnames []string
+}
+
+// AdoptionRequestFor returns what a claim with the given annotations adopts from a
+// pool, or nil if the pool has no adoption configured or nothing to match. With
+// MatchHostname the names of the claim and its owners are matched unless the claim
+// names a hostname explicitly.
+func AdoptionRequestFor(spec *v1beta2.UnifiIPPoolSpec, annotations map[string]string, names []string) *AdoptionRequest {
+	if spec.Adoption == nil {
+		return nil
+	}
+
+	req := &AdoptionRequest{MAC: strings.TrimSpace(annotations[AdoptMACAnnotation])}
+	if hostname := strings.TrimSpace(annotations[AdoptHostnameAnnotation]); hostname != "" {
+		req.Hostnames = []string{hostname}
+	} else if spec.Adoption.MatchHostname {
+		for _, name := range names {
+			if name != "" {
+				req.Hostnames = append(req.Hostnames, name)
+			}
+		}
+	}
+
+	if req.MAC == "" && len(req.Hostnames) == 0 {
+		return nil
+	}
+	return req
+}
+
+// FindAdoption returns the candidate matching the request. A MAC match wins over
+// hostname matches; several candidates matching by hostname are ambiguous.
+func FindAdoption(candidates []AdoptionCandidate, req AdoptionRequest) (AdoptionCandidate, bool, error) {
+	if req.MAC != "" {
+		for _, candidate := range candidates {
+			if normalizeMAC(candidate.MAC) == normalizeMAC(req.MAC) {
+				return candidate, true, nil
+			}
+		}
+	}
+
+	var matches []AdoptionCandidate
+	for _, candidate := range candidates {
+		for _, hostname := range req.Hostnames {
+			if strings.EqualFold(candidate.Hostname, hostname) || strings.EqualFold(candidate.Name, hostname) {
+				matches = append(matches, candidate)
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return AdoptionCandidate{}, false, nil
+	case 1:
+		return matches[0], true, nil
+	default:
+		ips := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ips = append(ips, match.IP)
+		}
+		return AdoptionCandidate{}, false, fmt.Errorf("%w hostnames %v: %s", ErrAmbiguousAdoption, req.Hostnames, strings.Join(ips, ", "))
+	}
+}
+
+// normalizeMAC makes MACs written with dashes or in upper case comparable.
+func normalizeMAC(mac string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(mac), "-", ":"))
+}
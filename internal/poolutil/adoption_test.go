@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func TestAdoptionRequestFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		adoption    *v1beta2.AdoptionSpec
+		annotations map[string]string
+		names       []string
+		want        *AdoptionRequest
+	}{
+		{
+			name:        "adoption disabled",
+			annotations: map[string]string{AdoptMACAnnotation: "aa:bb:cc:dd:ee:ff"},
+			names:       []string{"vm-1"},
+		},
+		{
+			name:     "nothing to match",
+			adoption: &v1beta2.AdoptionSpec{},
+			names:    []string{"vm-1"},
+		},
+		{
+			name:        "MAC annotation",
+			adoption:    &v1beta2.AdoptionSpec{},
+			annotations: map[string]string{AdoptMACAnnotation: " aa:bb:cc:dd:ee:ff "},
+			want:        &AdoptionRequest{MAC: "aa:bb:cc:dd:ee:ff"},
+		},
+		{
+			name:     "claim and owner names",
+			adoption: &v1beta2.AdoptionSpec{MatchHostname: true},
+			names:    []string{"vm-1-claim", "", "vm-1"},
+			want:     &AdoptionRequest{Hostnames: []string{"vm-1-claim", "vm-1"}},
+		},
+		{
+			name:        "hostname annotation wins over names",
+			adoption:    &v1beta2.AdoptionSpec{MatchHostname: true},
+			annotations: map[string]string{AdoptHostnameAnnotation: "legacy-vm"},
+			names:       []string{"vm-1"},
+			want:        &AdoptionRequest{Hostnames: []string{"legacy-vm"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1beta2.UnifiIPPoolSpec{Adoption: tt.adoption}
+			got := AdoptionRequestFor(spec, tt.annotations, tt.names)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AdoptionRequestFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAdoption(t *testing.T) {
+	candidates := []AdoptionCandidate{
+		{IP: "10.0.0.10", MAC: "AA:BB:CC:DD:EE:01", Hostname: "vm-1"},
+		{IP: "10.0.0.11", MAC: "aa:bb:cc:dd:ee:02", Name: "VM-2"},
+		{IP: "10.0.0.12", MAC: "aa:bb:cc:dd:ee:03", Hostname: "dup"},
+		{IP: "10.0.0.13", MAC: "aa:bb:cc:dd:ee:04", Name: "dup"},
+	}
+
+	tests := []struct {
+		name    string
+		req     AdoptionRequest
+		want    string
+		wantOK  bool
+		wantErr error
+	}{
+		{
+			name:   "MAC match ignores case and separators",
+			req:    AdoptionRequest{MAC: "aa-bb-cc-dd-ee-01"},
+			want:   "10.0.0.10",
+			wantOK: true,
+		},
+		{
+			name:   "MAC wins over hostname",
+			req:    AdoptionRequest{MAC: "aa:bb:cc:dd:ee:02", Hostnames: []string{"vm-1"}},
+			want:   "10.0.0.11",
+			wantOK: true,
+		},
+		{
+			name:   "hostname matches client name",
+			req:    AdoptionRequest{Hostnames: []string{"vm-2"}},
+			want:   "10.0.0.11",
+			wantOK: true,
+		},
+		{
+			name: "no match",
+			req:  AdoptionRequest{MAC: "aa:bb:cc:dd:ee:99", Hostnames: []string{"vm-9"}},
+		},
+		{
+			name:    "ambiguous hostname",
+			req:     AdoptionRequest{Hostnames: []string{"dup"}},
+			wantErr: ErrAmbiguousAdoption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := FindAdoption(candidates, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindAdoption() error = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || got.IP != tt.want {
+				t.Errorf("FindAdoption() = %q, %v, want %q, %v", got.IP, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// AllocationKey returns the key of an IPAddress in the allocations of its pool: the
+// claim name for namespaced pools and namespace/claim name for global pools, whose
+// claims live in several namespaces.
+func AllocationKey(poolNamespace string, address *ipamv1beta2.IPAddress) string {
+	if poolNamespace == "" {
+		return address.Namespace + "/" + address.Spec.ClaimRef.Name
+	}
+	return address.Spec.ClaimRef.Name
+}
+
+// PreAllocation returns the address preallocated to a claim. The namespace/name key
+// takes precedence over the name, which matches claims in every namespace.
+func PreAllocation(spec *v1beta2.UnifiIPPoolSpec, namespace, name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	if namespace != "" {
+		if address, ok := spec.PreAllocations[namespace+"/"+name]; ok {
+			return address, true
+		}
+	}
+	address, ok := spec.PreAllocations[name]
+	return address, ok
+}
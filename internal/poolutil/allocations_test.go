@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func TestAllocationKey(t *testing.T) {
+	address := &ipamv1beta2.IPAddress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "worker-0"},
+		Spec: ipamv1beta2.IPAddressSpec{
+			ClaimRef: ipamv1beta2.IPAddressClaimReference{Name: "worker-0"},
+		},
+	}
+
+	if got := AllocationKey("team-a", address); got != "worker-0" {
+		t.Errorf("AllocationKey() of a namespaced pool = %q, want %q", got, "worker-0")
+	}
+	if got := AllocationKey("", address); got != "team-a/worker-0" {
+		t.Errorf("AllocationKey() of a global pool = %q, want %q", got, "team-a/worker-0")
+	}
+}
+
+func TestPreAllocation(t *testing.T) {
+	spec := &v1beta2.UnifiIPPoolSpec{
+		PreAllocations: map[string]string{
+			"worker-0":        "10.1.40.10",
+			"team-b/worker-0": "10.1.40.20",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		claim     string
+		want      string
+		wantOK    bool
+	}{
+		{name: "by name", namespace: "team-a", claim: "worker-0", want: "10.1.40.10", wantOK: true},
+		{name: "by namespace and name", namespace: "team-b", claim: "worker-0", want: "10.1.40.20", wantOK: true},
+		{name: "without namespace", claim: "worker-0", want: "10.1.40.10", wantOK: true},
+		{name: "not preallocated", namespace: "team-a", claim: "worker-1"},
+		{name: "empty name", namespace: "team-a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PreAllocation(spec, tt.namespace, tt.claim)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("PreAllocation() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/netip"
+
+	"go4.org/netipx"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+// ErrPoolExhausted is returned when a pool has no free addresses left.
+var ErrPoolExhausted = errors.New("exhausted IP pool: no free IPs available")
+
+// Allocator hands out addresses from a pool.
+// The allocatable set holds the addresses of all subnets minus exclude ranges and
+// gateways; the free set additionally removes the addresses in use. Both are kept
+// as IP ranges, so lookups scale with the number of ranges rather than addresses.
+type Allocator struct {
+	spec        *v1beta2.UnifiIPPoolSpec
+	allocatable *netipx.IPSet
+	free        *netipx.IPSet
+}
+
+// NewAllocator builds an allocator for the pool spec. inUse holds the addresses
+// that must not be handed out, such as IPAddresses of the pool and Unifi fixed IPs.
+// Entries that don't parse as an address or CIDR are ignored.
+func NewAllocator(spec *v1beta2.UnifiIPPoolSpec, inUse []string) (*Allocator, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("pool spec is nil")
+	}
+	if len(spec.Subnets) == 0 {
+		return nil, fmt.Errorf("pool has no configured subnets")
+	}
+
+	allocatable, err := PoolIPSet(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var builder netipx.IPSetBuilder
+	builder.AddSet(allocatable)
+	for _, addr := range inUse {
+		if ip, err := netip.ParseAddr(addr); err == nil {
+			builder.Remove(ip)
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(addr); err == nil {
+			builder.RemovePrefix(prefix)
+		}
+	}
+
+	free, err := builder.IPSet()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build free IP set: %w", err)
+	}
+
+	return &Allocator{spec: spec, allocatable: allocatable, free: free}, nil
+}
+
+// PoolIPSet returns the allocatable addresses of a pool: the union of all subnets
+// minus exclude ranges, subnet gateways and the pool-level gateway.
+func PoolIPSet(spec *v1beta2.UnifiIPPoolSpec) (*netipx.IPSet, error) {
+	var builder netipx.IPSetBuilder
+	for i := range spec.Subnets {
+		subnetIPSet, err := PoolSpecToIPSet(&spec.Subnets[i])
+		if err != nil {
+			return nil, fmt.Errorf("subnet %d: %w", i, err)
+		}
+		builder.AddSet(subnetIPSet)
+	}
+
+	if spec.Gateway != "" {
+		gateway, err := netip.ParseAddr(spec.Gateway)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse gateway %s: %w", spec.Gateway, err)
+		}
+		builder.Remove(gateway)
+	}
+
+	return builder.IPSet()
+}
+
+// Allocatable returns the addresses the pool may hand out, whether in use or not.
+func (a *Allocator) Allocatable() *netipx.IPSet {
+	return a.allocatable
+}
+
+// Free returns the allocatable addresses that are not in use.
+func (a *Allocator) Free() *netipx.IPSet {
+	return a.free
+}
+
+// Contains reports whether ip may be handed out by the pool at all.
+func (a *Allocator) Contains(ip netip.Addr) bool {
+	return a.allocatable.Contains(ip)
+}
+
+// IsFree reports whether ip may be handed out and is not in use.
+func (a *Allocator) IsFree(ip netip.Addr) bool {
+	return a.free.Contains(ip)
+}
+
+// Next returns a free address according to the pool's allocation strategy.
+func (a *Allocator) Next() (netip.Addr, error) {
+	return a.NextSpread(nil)
+}
+
+// NextSpread returns a free address like Next. If the pool spreads clusters across
+// subnets, only the subnets holding the fewest of the given peer addresses (those of
+// the same cluster) are considered.
+func (a *Allocator) NextSpread(peers []string) (netip.Addr, error) {
+	candidates, err := a.subnetCandidates()
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	if len(candidates) == 0 {
+		return netip.Addr{}, ErrPoolExhausted
+	}
+
+	if a.spec.SpreadClustersAcrossSubnets && len(peers) > 0 {
+		candidates = fewestPeers(candidates, peers)
+	}
+
+	if a.spec.AllocationStrategy == v1beta2.AllocationStrategyLeastUsedSubnet {
+		candidates = leastUsed(candidates)
+	}
+
+	var builder netipx.IPSetBuilder
+	for _, candidate := range candidates {
+		builder.AddSet(candidate.free)
+	}
+	free, err := builder.IPSet()
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("failed to build free IP set: %w", err)
+	}
+
+	ranges := free.Ranges()
+	if len(ranges) == 0 {
+		return netip.Addr{}, ErrPoolExhausted
+	}
+
+	switch a.spec.AllocationStrategy {
+	case v1beta2.AllocationStrategyLastFree:
+		return ranges[len(ranges)-1].To(), nil
+	case v1beta2.AllocationStrategyRandom:
+		return randomAddress(ranges), nil
+	default:
+		return ranges[0].From(), nil
+	}
+}
+
+// subnetCandidate holds the addresses of one subnet and how many are in use.
+type subnetCandidate struct {
+	allocatable *netipx.IPSet
+	free        *netipx.IPSet
+	used        int
+}
+
+// subnetCandidates returns the subnets of the pool that still have free addresses.
+func (a *Allocator) subnetCandidates() ([]subnetCandidate, error) {
+	candidates := make([]subnetCandidate, 0, len(a.spec.Subnets))
+	for i := range a.spec.Subnets {
+		subnetIPSet, err := subnetAllocatableIPSet(&a.spec.Subnets[i], a.spec.Gateway)
+		if err != nil {
+			return nil, fmt.Errorf("subnet %d: %w", i, err)
+		}
+
+		var builder netipx.IPSetBuilder
+		builder.AddSet(subnetIPSet)
+		builder.Intersect(a.free)
+		free, err := builder.IPSet()
+		if err != nil {
+			return nil, fmt.Errorf("subnet %d: %w", i, err)
+		}
+
+		freeCount := CountAddresses(free)
+		if freeCount == 0 {
+			continue
+		}
+
+		candidates = append(candidates, subnetCandidate{
+			allocatable: subnetIPSet,
+			free:        free,
+			used:        CountAddresses(subnetIPSet) - freeCount,
+		})
+	}
+	return candidates, nil
+}
+
+// fewestPeers keeps the candidates holding the fewest peer addresses.
+func fewestPeers(candidates []subnetCandidate, peers []string) []subnetCandidate {
+	peerIPSet, err := AddressesToIPSet(peers)
+	if err != nil {
+		return candidates
+	}
+
+	counts := make([]int, len(candidates))
+	lowest := math.MaxInt
+	for i, candidate := range candidates {
+		counts[i] = CountAddresses(intersectIPSet(candidate.allocatable, peerIPSet))
+		lowest = min(lowest, counts[i])
+	}
+
+	preferred := make([]subnetCandidate, 0, len(candidates))
+	for i, candidate := range candidates {
+		if counts[i] == lowest {
+			preferred = append(preferred, candidate)
+		}
+	}
+	return preferred
+}
+
+// Metadata returns the prefix length and gateway of the subnet containing ip.
+// The pool-level defaults are returned if no subnet contains it.
+func (a *Allocator) Metadata(ip netip.Addr) (int32, string) {
+	defaultPrefix := DefaultPrefix(a.spec)
+	for _, subnet := range a.spec.Subnets {
+		if inSubnet(ip, subnet, defaultPrefix) {
+			return GetPrefix(subnet, defaultPrefix), GetGateway(subnet, a.spec.Gateway)
+		}
+	}
+	return defaultPrefix, a.spec.Gateway
+}
+
+// DefaultPrefix returns the pool-level prefix length, falling back to /24.
+func DefaultPrefix(spec *v1beta2.UnifiIPPoolSpec) int32 {
+	if spec.Prefix != nil && *spec.Prefix > 0 {
+		return *spec.Prefix
+	}
+	return 24
+}
+
+// CountAddresses returns the number of addresses in the set, capped at math.MaxInt32.
+func CountAddresses(ipSet *netipx.IPSet) int {
+	if ipSet == nil {
+		return 0
+	}
+
+	var total uint64
+	for _, r := range ipSet.Ranges() {
+		total += rangeSize(r)
+		if total >= math.MaxInt32 {
+			return math.MaxInt32
+		}
+	}
+	return int(total)
+}
+
+// rangeSize returns the number of addresses in r, saturating for very large IPv6 ranges.
+func rangeSize(r netipx.IPRange) uint64 {
+	from, to := r.From().As16(), r.To().As16()
+	var fromHigh, fromLow, toHigh, toLow uint64
+	for i := 0; i < 8; i++ {
+		fromHigh = fromHigh<<8 | uint64(from[i])
+		toHigh = toHigh<<8 | uint64(to[i])
+		fromLow = fromLow<<8 | uint64(from[i+8])
+		toLow = toLow<<8 | uint64(to[i+8])
+	}
+
+	if toHigh != fromHigh {
+		return math.MaxUint64
+	}
+	diff := toLow - fromLow
+	if diff == math.MaxUint64 {
+		return diff
+	}
+	return diff + 1
+}
+
+// leastUsed keeps the first candidate with the fewest addresses in use.
+func leastUsed(candidates []subnetCandidate) []subnetCandidate {
+	best := 0
+	for i, candidate := range candidates {
+		if candidate.used < candidates[best].used {
+			best = i
+		}
+	}
+	return candidates[best : best+1]
+}
+
+// randomAddress picks a uniformly distributed address from the ranges.
+// Very large IPv6 ranges are sampled from their first math.MaxInt32 addresses.
+func randomAddress(ranges []netipx.IPRange) netip.Addr {
+	sizes := make([]uint64, len(ranges))
+	var total uint64
+	for i, r := range ranges {
+		sizes[i] = min(rangeSize(r), math.MaxInt32)
+		total += sizes[i]
+	}
+
+	n := rand.Uint64N(total) //nolint:gosec // Address selection is not security sensitive
+	for i, r := range ranges {
+		if n < sizes[i] {
+			return addOffset(r.From(), n)
+		}
+		n -= sizes[i]
+	}
+	return ranges[len(ranges)-1].To()
+}
+
+// addOffset returns ip advanced by n addresses.
+func addOffset(ip netip.Addr, n uint64) netip.Addr {
+	if ip.Is4() {
+		b := ip.As4()
+		v := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+		v += uint32(n) // #nosec G115 - offsets stay within the range
+		return netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+	}
+
+	b := ip.As16()
+	var high, low uint64
+	for i := 0; i < 8; i++ {
+		high = high<<8 | uint64(b[i])
+		low = low<<8 | uint64(b[i+8])
+	}
+	sum := low + n
+	if sum < low {
+		high++
+	}
+	low = sum
+
+	var out [16]byte
+	for i := 7; i >= 0; i-- {
+		out[i] = byte(high)
+		out[i+8] = byte(low)
+		high >>= 8
+		low >>= 8
+	}
+	return netip.AddrFrom16(out)
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"math"
+	"net/netip"
+	"testing"
+
+	"go4.org/netipx"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func TestAllocator_Next(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    v1beta2.UnifiIPPoolSpec
+		inUse   []string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "skips network address and gateway",
+			spec: v1beta2.UnifiIPPoolSpec{Subnets: []v1beta2.SubnetSpec{
+				{CIDR: "10.0.0.0/24", Gateway: "10.0.0.1"},
+			}},
+			want: "10.0.0.2",
+		},
+		{
+			name: "skips pool-level gateway",
+			spec: v1beta2.UnifiIPPoolSpec{
+				Gateway: "10.0.0.10",
+				Subnets: []v1beta2.SubnetSpec{{Start: "10.0.0.10", End: "10.0.0.20"}},
+			},
+			want: "10.0.0.11",
+		},
+		{
+			name: "skips exclude ranges",
+			spec: v1beta2.UnifiIPPoolSpec{Subnets: []v1beta2.SubnetSpec{
+				{CIDR: "10.0.0.0/24", ExcludeRanges: []string{"10.0.0.0/28", "10.0.0.16"}},
+			}},
+			want: "10.0.0.17",
+		},
+		{
+			name: "skips addresses in use",
+			spec: v1beta2.UnifiIPPoolSpec{Subnets: []v1beta2.SubnetSpec{
+				{Start: "10.0.0.10", End: "10.0.0.20"},
+			}},
+			inUse: []string{"10.0.0.10", "10.0.0.11", "", "garbage"},
+			want:  "10.0.0.12",
+		},
+		{
+			name: "falls through to next subnet",
+			spec: v1beta2.UnifiIPPoolSpec{Subnets: []v1beta2.SubnetSpec{
+				{Start: "10.0.1.10", End: "10.0.1.11"},
+				{Start: "10.0.0.10", End: "10.0.0.11"},
+			}},
+			inUse: []string{"10.0.0.10", "10.0.0.11"},
+			want:  "10.0.1.10",
+		},
+		{
+			name: "exhausted",
+			spec: v1beta2.UnifiIPPoolSpec{Subnets: []v1beta2.SubnetSpec{
+				{Start: "10.0.0.10", End: "10.0.0.12", ExcludeRanges: []string{"10.0.0.11"}},
+			}},
+			inUse:   []string{"10.0.0.10", "10.0.0.12"},
+			wantErr: ErrPoolExhausted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocator, err := NewAllocator(&tt.spec, tt.inUse)
+			if err != nil {
+				t.Fatalf("NewAllocator() error = %v", err)
+			}
+			got, err := allocator.Next()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Allocator.Next() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.String() != tt.want {
+				t.Errorf("Allocator.Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocator_Contains(t *testing.T) {
+	spec := &v1beta2.UnifiIPPoolSpec{Subnets: []v1beta2.SubnetSpec{
+		{CIDR: "10.0.0.0/24", Gateway: "10.0.0.1", ExcludeRanges: []string{"10.0.0.100"}},
+	}}
+	allocator, err := NewAllocator(spec, []string{"10.0.0.50"})
+	if err != nil {
+		t.Fatalf("NewAllocator() error = %v", err)
+	}
+
+	tests := []struct {
+		ip          string
+		wantContain bool
+		wantFree    bool
+	}{
+		{ip: "10.0.0.2", wantContain: true, wantFree: true},
+		{ip: "10.0.0.50", wantContain: true, wantFree: false},
+		{ip: "10.0.0.1", wantContain: false, wantFree: false},
+		{ip: "10.0.0.100", wantContain: false, wantFree: false},
+		{ip: "10.0.0.255", wantContain: false, wantFree: false},
+		{ip: "10.0.1.2", wantContain: false, wantFree: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			ip := netip.MustParseAddr(tt.ip)
+			if got := allocator.Contains(ip); got != tt.wantContain {
+				t.Errorf("Allocator.Contains() = %v, want %v", got, tt.wantContain)
+			}
+			if got := allocator.IsFree(ip); got != tt.wantFree {
+				t.Errorf("Allocator.IsFree() = %v, want %v", got, tt.wantFree)
+			}
+		})
+	}
+}
+
+func TestAllocator_Metadata(t *testing.T) {
+	spec := &v1beta2.UnifiIPPoolSpec{
+		Gateway: "10.0.0.1",
+		Prefix:  int32Ptr(16),
+		Subnets: []v1beta2.SubnetSpec{
+			{CIDR: "10.0.0.0/24"},
+			{Start: "10.0.1.10", End: "10.0.1.20", Gateway: "10.0.1.1", Prefix: int32Ptr(24)},
+		},
+	}
+	allocator, err := NewAllocator(spec, nil)
+	if err != nil {
+		t.Fatalf("NewAllocator() error = %v", err)
+	}
+
+	tests := []struct {
+		ip          string
+		wantPrefix  int32
+		wantGateway string
+	}{
+		{ip: "10.0.0.5", wantPrefix: 24, wantGateway: "10.0.0.1"},
+		{ip: "10.0.1.15", wantPrefix: 24, wantGateway: "10.0.1.1"},
+		{ip: "10.0.2.1", wantPrefix: 16, wantGateway: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			prefix, gateway := allocator.Metadata(netip.MustParseAddr(tt.ip))
+			if prefix != tt.wantPrefix || gateway != tt.wantGateway {
+				t.Errorf("Allocator.Metadata() = %v, %v, want %v, %v", prefix, gateway, tt.wantPrefix, tt.wantGateway)
+			}
+		})
+	}
+}
+
+func TestCountAddresses(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		want     int
+	}{
+		{name: "nil set", want: 0},
+		{name: "single address", prefixes: []string{"10.0.0.1/32"}, want: 1},
+		{name: "crosses last octet", prefixes: []string{"10.0.0.0/23"}, want: 512},
+		{name: "multiple ranges", prefixes: []string{"10.0.0.0/24", "10.0.2.0/30"}, want: 260},
+		{name: "large IPv6 prefix is capped", prefixes: []string{"2001:db8::/64"}, want: math.MaxInt32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ipSet *netipx.IPSet
+			if tt.prefixes != nil {
+				var builder netipx.IPSetBuilder
+				for _, p := range tt.prefixes {
+					builder.AddPrefix(netip.MustParsePrefix(p))
+				}
+				var err error
+				if ipSet, err = builder.IPSet(); err != nil {
+					t.Fatalf("IPSet() error = %v", err)
+				}
+			}
+			if got := CountAddresses(ipSet); got != tt.want {
+				t.Errorf("CountAddresses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocator_NextSpread(t *testing.T) {
+	twoSubnets := []v1beta2.SubnetSpec{
+		{Start: "10.0.0.10", End: "10.0.0.19"},
+		{Start: "10.0.1.10", End: "10.0.1.19"},
+	}
+	tests := []struct {
+		name     string
+		strategy v1beta2.AllocationStrategy
+		spread   bool
+		inUse    []string
+		peers    []string
+		want     string
+	}{
+		{
+			name:  "default is first free",
+			inUse: []string{"10.0.0.10"},
+			want:  "10.0.0.11",
+		},
+		{
+			name:     "first free",
+			strategy: v1beta2.AllocationStrategyFirstFree,
+			want:     "10.0.0.10",
+		},
+		{
+			name:     "last free",
+			strategy: v1beta2.AllocationStrategyLastFree,
+			inUse:    []string{"10.0.1.19"},
+			want:     "10.0.1.18",
+		},
+		{
+			name:     "least used subnet",
+			strategy: v1beta2.AllocationStrategyLeastUsedSubnet,
+			inUse:    []string{"10.0.0.10"},
+			want:     "10.0.1.10",
+		},
+		{
+			name:     "least used subnet ties go to the first subnet",
+			strategy: v1beta2.AllocationStrategyLeastUsedSubnet,
+			inUse:    []string{"10.0.0.10", "10.0.1.10"},
+			want:     "10.0.0.11",
+		},
+		{
+			name:   "spread avoids subnet holding the cluster",
+			spread: true,
+			inUse:  []string{"10.0.0.10", "10.0.0.11"},
+			peers:  []string{"10.0.0.10"},
+			want:   "10.0.1.10",
+		},
+		{
+			name:     "spread combined with last free",
+			strategy: v1beta2.AllocationStrategyLastFree,
+			spread:   true,
+			inUse:    []string{"10.0.1.10"},
+			peers:    []string{"10.0.1.10"},
+			want:     "10.0.0.19",
+		},
+		{
+			name:  "spread disabled ignores peers",
+			inUse: []string{"10.0.0.10"},
+			peers: []string{"10.0.0.10"},
+			want:  "10.0.0.11",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1beta2.UnifiIPPoolSpec{
+				Subnets:                     twoSubnets,
+				AllocationStrategy:          tt.strategy,
+				SpreadClustersAcrossSubnets: tt.spread,
+			}
+			allocator, err := NewAllocator(spec, tt.inUse)
+			if err != nil {
+				t.Fatalf("NewAllocator() error = %v", err)
+			}
+			got, err := allocator.NextSpread(tt.peers)
+			if err != nil {
+				t.Fatalf("Allocator.NextSpread() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Allocator.NextSpread() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocator_NextRandom(t *testing.T) {
+	spec := &v1beta2.UnifiIPPoolSpec{
+		AllocationStrategy: v1beta2.AllocationStrategyRandom,
+		Subnets: []v1beta2.SubnetSpec{
+			{Start: "10.0.0.10", End: "10.0.0.19"},
+			{CIDR: "2001:db8::/64"},
+		},
+	}
+	allocator, err := NewAllocator(spec, []string{"10.0.0.15"})
+	if err != nil {
+		t.Fatalf("NewAllocator() error = %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		got, err := allocator.Next()
+		if err != nil {
+			t.Fatalf("Allocator.Next() error = %v", err)
+		}
+		if !allocator.IsFree(got) {
+			t.Fatalf("Allocator.Next() = %v, which is not free", got)
+		}
+	}
+}
+
+func TestAddOffset(t *testing.T) {
+	tests := []struct {
+		ip   string
+		n    uint64
+		want string
+	}{
+		{ip: "10.0.0.250", n: 10, want: "10.0.1.4"},
+		{ip: "2001:db8::ffff:ffff:ffff:fffe", n: 3, want: "2001:db8:0:1::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := addOffset(netip.MustParseAddr(tt.ip), tt.n); got.String() != tt.want {
+				t.Errorf("addOffset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+const (
+	// UsageSampleInterval is the minimum time between two usage samples.
+	UsageSampleInterval = 15 * time.Minute
+
+	// UsageHistoryWindow is how long usage samples are kept.
+	UsageHistoryWindow = 24 * time.Hour
+
+	// MaxUsageSamples bounds the size of the usage history.
+	MaxUsageSamples = int(UsageHistoryWindow / UsageSampleInterval)
+
+	// ExhaustionForecastWindow is how far ahead a projected exhaustion marks the
+	// pool as nearly exhausted.
+	ExhaustionForecastWindow = 7 * 24 * time.Hour
+
+	// maxForecastHorizon caps projections; slower growth is reported as no forecast.
+	maxForecastHorizon = 365 * 24 * time.Hour
+)
+
+// RecordUsage appends a sample of the used addresses to the usage history unless the
+// last sample is more recent than UsageSampleInterval. Samples older than
+// UsageHistoryWindow are dropped and at most MaxUsageSamples are kept.
+func RecordUsage(history []v1beta2.UsageSample, used int32, now time.Time) []v1beta2.UsageSample {
+	cutoff := now.Add(-UsageHistoryWindow)
+	result := make([]v1beta2.UsageSample, 0, len(history)+1)
+	for _, sample := range history {
+		if sample.Time.After(cutoff) {
+			result = append(result, sample)
+		}
+	}
+
+	if n := len(result); n == 0 || now.Sub(result[n-1].Time.Time) >= UsageSampleInterval {
+		result = append(result, v1beta2.UsageSample{Time: metav1.NewTime(now), Used: used})
+	}
+	if len(result) > MaxUsageSamples {
+		result = result[len(result)-MaxUsageSamples:]
+	}
+	return result
+}
+
+// ForecastExhaustion projects when the pool runs out of addresses, fitting a linear
+// trend to the usage history and extrapolating it from the current usage. It returns
+// nil if usage is not growing, the history is too short or exhaustion is further
+// away than a year. A pool without free addresses is exhausted now.
+func ForecastExhaustion(history []v1beta2.UsageSample, used, total int32, now time.Time) *time.Time {
+	if total <= 0 {
+		return nil
+	}
+	if used >= total {
+		return &now
+	}
+	if len(history) < 2 {
+		return nil
+	}
+
+	// Least squares slope of used addresses per second.
+	origin := history[0].Time.Time
+	var meanX, meanY float64
+	for _, sample := range history {
+		meanX += sample.Time.Sub(origin).Seconds()
+		meanY += float64(sample.Used)
+	}
+	n := float64(len(history))
+	meanX /= n
+	meanY /= n
+
+	var covariance, variance float64
+	for _, sample := range history {
+		dx := sample.Time.Sub(origin).Seconds() - meanX
+		covariance += dx * (float64(sample.Used) - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return nil
+	}
+	slope := covariance / variance
+	if slope <= 0 {
+		return nil
+	}
+
+	remaining := float64(total-used) / slope
+	if remaining > maxForecastHorizon.Seconds() {
+		return nil
+	}
+	exhaustedAt := now.Add(time.Duration(remaining * float64(time.Second)))
+	return &exhaustedAt
+}
+
+// NearlyExhausted reports whether a projected exhaustion falls within ExhaustionForecastWindow.
+func NearlyExhausted(exhaustedAt *time.Time, now time.Time) bool {
+	return exhaustedAt != nil && exhaustedAt.Sub(now) <= ExhaustionForecastWindow
+}
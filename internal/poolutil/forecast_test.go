@@ -0,0 +1,213 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func usageSample(at time.Time, used int32) v1beta2.UsageSample {
+	return v1beta2.UsageSample{Time: metav1.NewTime(at), Used: used}
+}
+
+func TestRecordUsage(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	full := make([]v1beta2.UsageSample, 0, MaxUsageSamples)
+	for i := MaxUsageSamples; i > 0; i-- {
+		full = append(full, usageSample(now.Add(-time.Duration(i)*UsageSampleInterval+time.Second), int32(i)))
+	}
+	full[len(full)-1].Time = metav1.NewTime(now.Add(-UsageSampleInterval))
+
+	tests := []struct {
+		name    string
+		history []v1beta2.UsageSample
+		used    int32
+		want    []v1beta2.UsageSample
+	}{
+		{
+			name: "first sample",
+			used: 3,
+			want: []v1beta2.UsageSample{usageSample(now, 3)},
+		},
+		{
+			name:    "sample after interval is appended",
+			history: []v1beta2.UsageSample{usageSample(now.Add(-UsageSampleInterval), 2)},
+			used:    3,
+			want:    []v1beta2.UsageSample{usageSample(now.Add(-UsageSampleInterval), 2), usageSample(now, 3)},
+		},
+		{
+			name:    "sample within interval is skipped",
+			history: []v1beta2.UsageSample{usageSample(now.Add(-time.Minute), 2)},
+			used:    3,
+			want:    []v1beta2.UsageSample{usageSample(now.Add(-time.Minute), 2)},
+		},
+		{
+			name: "samples outside the window are dropped",
+			history: []v1beta2.UsageSample{
+				usageSample(now.Add(-UsageHistoryWindow-time.Minute), 1),
+				usageSample(now.Add(-time.Hour), 2),
+			},
+			used: 3,
+			want: []v1beta2.UsageSample{usageSample(now.Add(-time.Hour), 2), usageSample(now, 3)},
+		},
+		{
+			name:    "history is bounded",
+			history: full,
+			used:    0,
+			want:    append(append([]v1beta2.UsageSample{}, full[1:]...), usageSample(now, 0)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RecordUsage(tt.history, tt.used, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RecordUsage() = %v, want %v", got, tt.want)
+			}
+			if len(got) > MaxUsageSamples {
+				t.Errorf("RecordUsage() kept %d samples, want at most %d", len(got), MaxUsageSamples)
+			}
+		})
+	}
+}
+
+func TestForecastExhaustion(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// One address per hour over the last four hours.
+	growing := []v1beta2.UsageSample{
+		usageSample(now.Add(-4*time.Hour), 6),
+		usageSample(now.Add(-3*time.Hour), 7),
+		usageSample(now.Add(-2*time.Hour), 8),
+		usageSample(now.Add(-time.Hour), 9),
+		usageSample(now, 10),
+	}
+
+	tests := []struct {
+		name    string
+		history []v1beta2.UsageSample
+		used    int32
+		total   int32
+		want    *time.Time
+	}{
+		{
+			name:    "linear growth",
+			history: growing,
+			used:    10,
+			total:   20,
+			want:    ptrTime(now.Add(10 * time.Hour)),
+		},
+		{
+			name:    "projected from current usage",
+			history: growing,
+			used:    15,
+			total:   20,
+			want:    ptrTime(now.Add(5 * time.Hour)),
+		},
+		{
+			name:    "exhausted pool",
+			history: growing,
+			used:    20,
+			total:   20,
+			want:    ptrTime(now),
+		},
+		{
+			name: "shrinking usage",
+			history: []v1beta2.UsageSample{
+				usageSample(now.Add(-time.Hour), 10),
+				usageSample(now, 8),
+			},
+			used:  8,
+			total: 20,
+		},
+		{
+			name: "flat usage",
+			history: []v1beta2.UsageSample{
+				usageSample(now.Add(-time.Hour), 8),
+				usageSample(now, 8),
+			},
+			used:  8,
+			total: 20,
+		},
+		{
+			name:    "single sample",
+			history: []v1beta2.UsageSample{usageSample(now, 8)},
+			used:    8,
+			total:   20,
+		},
+		{
+			name: "growth too slow to forecast",
+			history: []v1beta2.UsageSample{
+				usageSample(now.Add(-UsageHistoryWindow), 8),
+				usageSample(now, 9),
+			},
+			used:  9,
+			total: 1000,
+		},
+		{
+			name:  "empty pool",
+			used:  0,
+			total: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ForecastExhaustion(tt.history, tt.used, tt.total, now)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil:
+				t.Errorf("ForecastExhaustion() = %v, want %v", got, tt.want)
+			case got.Sub(*tt.want).Abs() > time.Second:
+				t.Errorf("ForecastExhaustion() = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestNearlyExhausted(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		exhaustedAt *time.Time
+		want        bool
+	}{
+		{name: "no forecast", want: false},
+		{name: "within window", exhaustedAt: ptrTime(now.Add(ExhaustionForecastWindow - time.Hour)), want: true},
+		{name: "beyond window", exhaustedAt: ptrTime(now.Add(ExhaustionForecastWindow + time.Hour)), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NearlyExhausted(tt.exhaustedAt, now); got != tt.want {
+				t.Errorf("NearlyExhausted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+// ErrHeadroomReserved is returned when the only free addresses left are reserved as headroom.
+var ErrHeadroomReserved = errors.New("remaining free IPs are reserved as headroom for other claims")
+
+// HeadroomReserved returns the number of addresses the pool keeps free for headroom claims.
+func HeadroomReserved(spec *v1beta2.UnifiIPPoolSpec) int32 {
+	if spec.Headroom == nil || spec.Headroom.Reserved < 0 {
+		return 0
+	}
+	return spec.Headroom.Reserved
+}
+
+// UsesHeadroom reports whether a claim with the given labels may allocate the
+// addresses reserved as headroom. Every claim may if the pool has no headroom.
+func UsesHeadroom(spec *v1beta2.UnifiIPPoolSpec, claimLabels map[string]string) (bool, error) {
+	if HeadroomReserved(spec) == 0 {
+		return true, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&spec.Headroom.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid headroom selector: %w", err)
+	}
+	return selector.Matches(labels.Set(claimLabels)), nil
+}
+
+// CheckHeadroom returns ErrHeadroomReserved if taking one of the free addresses
+// would eat into the headroom of a claim that may not use it.
+func CheckHeadroom(spec *v1beta2.UnifiIPPoolSpec, free int, useHeadroom bool) error {
+	reserved := HeadroomReserved(spec)
+	if useHeadroom || reserved == 0 {
+		return nil
+	}
+	if free <= int(reserved) {
+		return ErrHeadroomReserved
+	}
+	return nil
+}
+
+// SplitHeadroom splits the free addresses of a pool into the headroom still held
+// back and the addresses available to all claims.
+func SplitHeadroom(spec *v1beta2.UnifiIPPoolSpec, free int32) (headroom, general int32) {
+	headroom = min(HeadroomReserved(spec), max(free, 0))
+	return headroom, max(free, 0) - headroom
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func testHeadroomSpec(reserved int32) *v1beta2.UnifiIPPoolSpec {
+	return &v1beta2.UnifiIPPoolSpec{
+		Headroom: &v1beta2.HeadroomSpec{
+			Reserved: reserved,
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"role": "control-plane"}},
+		},
+	}
+}
+
+func TestUsesHeadroom(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   *v1beta2.UnifiIPPoolSpec
+		labels map[string]string
+		want   bool
+	}{
+		{name: "no headroom", spec: &v1beta2.UnifiIPPoolSpec{}, want: true},
+		{name: "zero headroom", spec: testHeadroomSpec(0), want: true},
+		{name: "matching claim", spec: testHeadroomSpec(2), labels: map[string]string{"role": "control-plane"}, want: true},
+		{name: "other claim", spec: testHeadroomSpec(2), labels: map[string]string{"role": "worker"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UsesHeadroom(tt.spec, tt.labels)
+			if err != nil {
+				t.Fatalf("UsesHeadroom() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("UsesHeadroom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHeadroom(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        *v1beta2.UnifiIPPoolSpec
+		free        int
+		useHeadroom bool
+		wantErr     bool
+	}{
+		{name: "no headroom", spec: &v1beta2.UnifiIPPoolSpec{}, free: 1},
+		{name: "free beyond headroom", spec: testHeadroomSpec(2), free: 3},
+		{name: "only headroom left", spec: testHeadroomSpec(2), free: 2, wantErr: true},
+		{name: "headroom claim", spec: testHeadroomSpec(2), free: 1, useHeadroom: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckHeadroom(tt.spec, tt.free, tt.useHeadroom)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckHeadroom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrHeadroomReserved) {
+				t.Errorf("CheckHeadroom() error = %v, want ErrHeadroomReserved", err)
+			}
+		})
+	}
+}
+
+func TestSplitHeadroom(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         *v1beta2.UnifiIPPoolSpec
+		free         int32
+		wantHeadroom int32
+		wantGeneral  int32
+	}{
+		{name: "no headroom", spec: &v1beta2.UnifiIPPoolSpec{}, free: 10, wantGeneral: 10},
+		{name: "headroom held back", spec: testHeadroomSpec(3), free: 10, wantHeadroom: 3, wantGeneral: 7},
+		{name: "headroom partially used", spec: testHeadroomSpec(3), free: 2, wantHeadroom: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headroom, general := SplitHeadroom(tt.spec, tt.free)
+			if headroom != tt.wantHeadroom || general != tt.wantGeneral {
+				t.Errorf("SplitHeadroom() = %d, %d, want %d, %d", headroom, general, tt.wantHeadroom, tt.wantGeneral)
+			}
+		})
+	}
+}
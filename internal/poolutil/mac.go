@@ -0,0 +1,42 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"strings"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// MACAddressLabel records on an IPAddress the MAC of its Unifi fixed IP, with the
+// colons replaced by dashes to form a valid label value. Release and reservation
+// sync read the MAC back from it, so addresses keep the MAC they were allocated
+// with when the MAC generation scheme changes.
+const MACAddressLabel = "unifi.ipam.cluster.x-k8s.io/mac"
+
+// AddressMAC returns the MAC recorded on an IPAddress, empty if it has none.
+func AddressMAC(address *ipamv1beta2.IPAddress) string {
+	return strings.ReplaceAll(address.Labels[MACAddressLabel], "-", ":")
+}
+
+// SetAddressMAC records the MAC of an IPAddress's Unifi fixed IP.
+func SetAddressMAC(address *ipamv1beta2.IPAddress, mac string) {
+	if address.Labels == nil {
+		address.Labels = make(map[string]string)
+	}
+	address.Labels[MACAddressLabel] = strings.ReplaceAll(mac, ":", "-")
+}
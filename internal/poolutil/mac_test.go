@@ -0,0 +1,44 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"testing"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func TestAddressMAC(t *testing.T) {
+	address := &ipamv1beta2.IPAddress{}
+	if got := AddressMAC(address); got != "" {
+		t.Errorf("AddressMAC() of an unlabeled address = %q, want empty", got)
+	}
+
+	SetAddressMAC(address, "02:ab:cd:ef:01:23")
+	if got := address.Labels[MACAddressLabel]; got != "02-ab-cd-ef-01-23" {
+		t.Errorf("label = %q, want %q", got, "02-ab-cd-ef-01-23")
+	}
+	if got := AddressMAC(address); got != "02:ab:cd:ef:01:23" {
+		t.Errorf("AddressMAC() = %q, want %q", got, "02:ab:cd:ef:01:23")
+	}
+
+	// Addresses allocated before the sha256 scheme keep their MAC.
+	address.Labels[MACAddressLabel] = "00-00-00-00-00-05"
+	if got := AddressMAC(address); got != "00:00:00:00:00:05" {
+		t.Errorf("AddressMAC() = %q, want %q", got, "00:00:00:00:00:05")
+	}
+}
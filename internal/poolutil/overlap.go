@@ -0,0 +1,251 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go4.org/netipx"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+// ErrInstanceNotFound is returned by FindPoolOverlaps when the pool's UnifiInstance
+// does not exist, so the Unifi site the pool allocates from is unknown.
+var ErrInstanceNotFound = errors.New("UnifiInstance not found")
+
+// PoolOverlap describes addresses a pool shares with another pool on the same Unifi site.
+type PoolOverlap struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Ranges    []netipx.IPRange
+}
+
+// String returns a human readable description of the overlap.
+func (o PoolOverlap) String() string {
+	name := o.Name
+	if o.Namespace != "" {
+		name = o.Namespace + "/" + o.Name
+	}
+	return fmt.Sprintf("%s %s (%s)", o.Kind, name, FormatRanges(o.Ranges))
+}
+
+// SubnetOverlap describes addresses shared by two subnets of the same pool.
+type SubnetOverlap struct {
+	First  int
+	Second int
+	Ranges []netipx.IPRange
+}
+
+// IntersectRanges returns the address ranges contained in both sets.
+func IntersectRanges(a, b *netipx.IPSet) []netipx.IPRange {
+	intersection := intersectIPSet(a, b)
+	if intersection == nil {
+		return nil
+	}
+	return intersection.Ranges()
+}
+
+// intersectIPSet returns the addresses contained in both sets, or nil if either is nil.
+func intersectIPSet(a, b *netipx.IPSet) *netipx.IPSet {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	var builder netipx.IPSetBuilder
+	builder.AddSet(a)
+	builder.Intersect(b)
+	intersection, err := builder.IPSet()
+	if err != nil {
+		return nil
+	}
+	return intersection
+}
+
+// FormatRanges formats ranges as a comma separated list of addresses and start-end ranges.
+func FormatRanges(ranges []netipx.IPRange) string {
+	parts := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if r.From() == r.To() {
+			parts = append(parts, r.From().String())
+			continue
+		}
+		parts = append(parts, r.From().String()+"-"+r.To().String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FindSubnetOverlaps returns the overlapping address ranges between subnets of a single pool.
+func FindSubnetOverlaps(spec *v1beta2.UnifiIPPoolSpec) ([]SubnetOverlap, error) {
+	subnetIPSets := make([]*netipx.IPSet, len(spec.Subnets))
+	for i := range spec.Subnets {
+		subnetIPSet, err := PoolSpecToIPSet(&spec.Subnets[i])
+		if err != nil {
+			return nil, fmt.Errorf("subnet %d: %w", i, err)
+		}
+		subnetIPSets[i] = subnetIPSet
+	}
+
+	var overlaps []SubnetOverlap
+	for i := range subnetIPSets {
+		for j := i + 1; j < len(subnetIPSets); j++ {
+			if ranges := IntersectRanges(subnetIPSets[i], subnetIPSets[j]); len(ranges) > 0 {
+				overlaps = append(overlaps, SubnetOverlap{First: i, Second: j, Ranges: ranges})
+			}
+		}
+	}
+	return overlaps, nil
+}
+
+// FindPoolOverlaps returns the other UnifiIPPools and GlobalUnifiIPPools that resolve to
+// the same Unifi controller and site as pool and share allocatable addresses with it.
+// Exclude ranges and gateways are taken into account, so pools may split a subnet.
+func FindPoolOverlaps(ctx context.Context, c client.Client, pool v1beta2.GenericUnifiIPPool) ([]PoolOverlap, error) {
+	poolIPSet, err := PoolIPSet(pool.PoolSpec())
+	if err != nil {
+		return nil, err
+	}
+
+	siteKeys := make(map[types.NamespacedName]string)
+	poolSite, err := unifiSiteKey(ctx, c, pool, siteKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := listAllPools(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var overlaps []PoolOverlap
+	for _, other := range candidates {
+		if other.PoolKind() == pool.PoolKind() &&
+			other.GetNamespace() == pool.GetNamespace() &&
+			other.GetName() == pool.GetName() {
+			continue
+		}
+		if !other.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		// Pools whose instance can't be resolved are reported by their own validation.
+		otherSite, err := unifiSiteKey(ctx, c, other, siteKeys)
+		if err != nil || otherSite != poolSite {
+			continue
+		}
+
+		if onDifferentNetworks(pool, other) {
+			continue
+		}
+
+		otherIPSet, err := PoolIPSet(other.PoolSpec())
+		if err != nil {
+			continue
+		}
+
+		if ranges := IntersectRanges(poolIPSet, otherIPSet); len(ranges) > 0 {
+			overlaps = append(overlaps, PoolOverlap{
+				Kind:      other.PoolKind(),
+				Namespace: other.GetNamespace(),
+				Name:      other.GetName(),
+				Ranges:    ranges,
+			})
+		}
+	}
+
+	sort.Slice(overlaps, func(i, j int) bool {
+		return overlaps[i].String() < overlaps[j].String()
+	})
+
+	return overlaps, nil
+}
+
+// listAllPools returns the UnifiIPPools of all namespaces and all GlobalUnifiIPPools.
+func listAllPools(ctx context.Context, c client.Client) ([]v1beta2.GenericUnifiIPPool, error) {
+	poolList := &v1beta2.UnifiIPPoolList{}
+	if err := c.List(ctx, poolList); err != nil {
+		return nil, fmt.Errorf("failed to list UnifiIPPools: %w", err)
+	}
+
+	globalPoolList := &v1beta2.GlobalUnifiIPPoolList{}
+	if err := c.List(ctx, globalPoolList); err != nil {
+		return nil, fmt.Errorf("failed to list GlobalUnifiIPPools: %w", err)
+	}
+
+	pools := make([]v1beta2.GenericUnifiIPPool, 0, len(poolList.Items)+len(globalPoolList.Items))
+	for i := range poolList.Items {
+		pools = append(pools, &poolList.Items[i])
+	}
+	for i := range globalPoolList.Items {
+		pools = append(pools, &globalPoolList.Items[i])
+	}
+	return pools, nil
+}
+
+// unifiSiteKey identifies the Unifi controller and site a pool allocates from.
+// Different UnifiInstances pointing at the same controller and site share a key.
+func unifiSiteKey(ctx context.Context, c client.Client, pool v1beta2.GenericUnifiIPPool, cache map[types.NamespacedName]string) (string, error) {
+	instanceKey := types.NamespacedName{
+		Name:      pool.PoolSpec().InstanceRef.Name,
+		Namespace: pool.PoolSpec().InstanceRef.Namespace,
+	}
+	if instanceKey.Namespace == "" {
+		instanceKey.Namespace = pool.GetNamespace()
+	}
+
+	if key, ok := cache[instanceKey]; ok {
+		return key, nil
+	}
+
+	instance := &v1beta2.UnifiInstance{}
+	if err := c.Get(ctx, instanceKey, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("%w: %s", ErrInstanceNotFound, instanceKey)
+		}
+		return "", fmt.Errorf("failed to get UnifiInstance %s: %w", instanceKey, err)
+	}
+
+	site := "default"
+	if instance.Spec.Site != nil && *instance.Spec.Site != "" {
+		site = *instance.Spec.Site
+	}
+
+	key := strings.ToLower(strings.TrimRight(instance.Spec.Host, "/")) + "/" + site
+	cache[instanceKey] = key
+	return key, nil
+}
+
+// onDifferentNetworks reports whether both pools are known to use different Unifi networks.
+func onDifferentNetworks(a, b v1beta2.GenericUnifiIPPool) bool {
+	aNetwork, bNetwork := networkID(a), networkID(b)
+	return aNetwork != "" && bNetwork != "" && aNetwork != bNetwork
+}
+
+func networkID(pool v1beta2.GenericUnifiIPPool) string {
+	if pool.PoolSpec().NetworkID != "" {
+		return pool.PoolSpec().NetworkID
+	}
+	return pool.PoolStatus().DiscoveredNetworkID
+}
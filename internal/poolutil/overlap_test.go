@@ -0,0 +1,197 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func TestFindSubnetOverlaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		subnets    []v1beta2.SubnetSpec
+		wantRanges []string
+		wantErr    bool
+	}{
+		{
+			name: "disjoint subnets",
+			subnets: []v1beta2.SubnetSpec{
+				{CIDR: "10.0.0.0/24"},
+				{CIDR: "10.0.1.0/24"},
+			},
+		},
+		{
+			name: "same CIDR",
+			subnets: []v1beta2.SubnetSpec{
+				{CIDR: "10.0.0.0/30"},
+				{CIDR: "10.0.0.0/30"},
+			},
+			wantRanges: []string{"10.0.0.1-10.0.0.2"},
+		},
+		{
+			name: "range inside CIDR",
+			subnets: []v1beta2.SubnetSpec{
+				{CIDR: "10.0.0.0/24", Gateway: "10.0.0.1"},
+				{Start: "10.0.0.1", End: "10.0.0.5"},
+			},
+			wantRanges: []string{"10.0.0.2-10.0.0.5"},
+		},
+		{
+			name: "overlap removed by exclude",
+			subnets: []v1beta2.SubnetSpec{
+				{CIDR: "10.0.0.0/24", ExcludeRanges: []string{"10.0.0.0/28"}},
+				{Start: "10.0.0.2", End: "10.0.0.10"},
+			},
+		},
+		{
+			name: "invalid subnet",
+			subnets: []v1beta2.SubnetSpec{
+				{CIDR: "not-a-cidr"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindSubnetOverlaps(&v1beta2.UnifiIPPoolSpec{Subnets: tt.subnets})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindSubnetOverlaps() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var gotRanges []string
+			for _, overlap := range got {
+				gotRanges = append(gotRanges, FormatRanges(overlap.Ranges))
+			}
+			if len(gotRanges) != len(tt.wantRanges) {
+				t.Fatalf("FindSubnetOverlaps() = %v, want %v", gotRanges, tt.wantRanges)
+			}
+			for i := range gotRanges {
+				if gotRanges[i] != tt.wantRanges[i] {
+					t.Errorf("FindSubnetOverlaps()[%d] = %v, want %v", i, gotRanges[i], tt.wantRanges[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIntersectRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []v1beta2.SubnetSpec
+		b    []v1beta2.SubnetSpec
+		want string
+	}{
+		{
+			name: "no overlap",
+			a:    []v1beta2.SubnetSpec{{Start: "10.0.0.10", End: "10.0.0.20"}},
+			b:    []v1beta2.SubnetSpec{{Start: "10.0.0.21", End: "10.0.0.30"}},
+			want: "",
+		},
+		{
+			name: "single address",
+			a:    []v1beta2.SubnetSpec{{Start: "10.0.0.10", End: "10.0.0.20"}},
+			b:    []v1beta2.SubnetSpec{{Start: "10.0.0.20", End: "10.0.0.30"}},
+			want: "10.0.0.20",
+		},
+		{
+			name: "split by excludes",
+			a:    []v1beta2.SubnetSpec{{Start: "10.0.0.10", End: "10.0.0.20", ExcludeRanges: []string{"10.0.0.15"}}},
+			b:    []v1beta2.SubnetSpec{{CIDR: "10.0.0.0/24"}},
+			want: "10.0.0.10-10.0.0.14, 10.0.0.16-10.0.0.20",
+		},
+		{
+			name: "multiple subnets",
+			a:    []v1beta2.SubnetSpec{{Start: "10.0.0.10", End: "10.0.0.11"}, {Start: "10.0.1.10", End: "10.0.1.11"}},
+			b:    []v1beta2.SubnetSpec{{CIDR: "10.0.1.0/24"}},
+			want: "10.0.1.10-10.0.1.11",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := PoolIPSet(&v1beta2.UnifiIPPoolSpec{Subnets: tt.a})
+			if err != nil {
+				t.Fatalf("PoolIPSet() error = %v", err)
+			}
+			b, err := PoolIPSet(&v1beta2.UnifiIPPoolSpec{Subnets: tt.b})
+			if err != nil {
+				t.Fatalf("PoolIPSet() error = %v", err)
+			}
+			if got := FormatRanges(IntersectRanges(a, b)); got != tt.want {
+				t.Errorf("IntersectRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOnDifferentNetworks(t *testing.T) {
+	pool := func(specNetwork, discoveredNetwork string) v1beta2.GenericUnifiIPPool {
+		return &v1beta2.UnifiIPPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "pool"},
+			Spec:       v1beta2.UnifiIPPoolSpec{NetworkID: specNetwork},
+			Status:     v1beta2.UnifiIPPoolStatus{DiscoveredNetworkID: discoveredNetwork},
+		}
+	}
+	tests := []struct {
+		name string
+		a    v1beta2.GenericUnifiIPPool
+		b    v1beta2.GenericUnifiIPPool
+		want bool
+	}{
+		{name: "both unknown", a: pool("", ""), b: pool("", ""), want: false},
+		{name: "one unknown", a: pool("net-a", ""), b: pool("", ""), want: false},
+		{name: "same network", a: pool("net-a", ""), b: pool("", "net-a"), want: false},
+		{name: "different networks", a: pool("net-a", ""), b: pool("", "net-b"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := onDifferentNetworks(tt.a, tt.b); got != tt.want {
+				t.Errorf("onDifferentNetworks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPoolOverlapsMissingInstance(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	pool := &v1beta2.UnifiIPPool{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pool"},
+		Spec: v1beta2.UnifiIPPoolSpec{
+			InstanceRef: corev1.ObjectReference{Name: "unifi"},
+			Subnets:     []v1beta2.SubnetSpec{{CIDR: "10.1.40.0/24"}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pool).Build()
+
+	overlaps, err := FindPoolOverlaps(context.Background(), c, pool)
+	if !errors.Is(err, ErrInstanceNotFound) {
+		t.Errorf("FindPoolOverlaps() error = %v, want %v", err, ErrInstanceNotFound)
+	}
+	if len(overlaps) != 0 {
+		t.Errorf("FindPoolOverlaps() = %v, want no overlaps", overlaps)
+	}
+}
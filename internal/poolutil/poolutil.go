@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"strings"
 
 	"go4.org/netipx"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -58,6 +59,23 @@ func ListAddressesInUse(ctx context.Context, c client.Client, namespace, poolNam
 	return inUse, nil
 }
 
+// ListReservationsInUse returns all UnifiIPReservations in the namespace that hold an address from the given pool.
+func ListReservationsInUse(ctx context.Context, c client.Client, namespace, poolName string) ([]v1beta2.UnifiIPReservation, error) {
+	reservationList := &v1beta2.UnifiIPReservationList{}
+	if err := c.List(ctx, reservationList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+
+	inUse := make([]v1beta2.UnifiIPReservation, 0)
+	for _, reservation := range reservationList.Items {
+		if reservation.Spec.PoolRef.Name == poolName && reservation.Status.Address != "" {
+			inUse = append(inUse, reservation)
+		}
+	}
+
+	return inUse, nil
+}
+
 // AddressesToIPSet converts a slice of IP address strings to an IPSet.
 func AddressesToIPSet(addresses []string) (*netipx.IPSet, error) {
 	var builder netipx.IPSetBuilder
@@ -130,21 +148,11 @@ func PoolSpecToIPSet(poolSpec *v1beta2.SubnetSpec) (*netipx.IPSet, error) {
 
 	// Remove excluded ranges.
 	for _, excludeRange := range poolSpec.ExcludeRanges {
-		// Try parsing as CIDR first.
-		if prefix, err := netip.ParsePrefix(excludeRange); err == nil {
-			builder.RemovePrefix(prefix)
-			continue
-		}
-
-		// Try parsing as single IP.
-		if ip, err := netip.ParseAddr(excludeRange); err == nil {
-			builder.Remove(ip)
-			continue
+		r, err := ParseExcludeRange(excludeRange)
+		if err != nil {
+			return nil, err
 		}
-
-		// Try parsing as IP range (start-end format)
-		// For simplicity, we'll skip range parsing for now
-		// A more robust implementation would parse "10.0.0.1-10.0.0.10" format.
+		builder.RemoveRange(r)
 	}
 
 	// For CIDR notation, remove network and broadcast addresses
@@ -160,6 +168,53 @@ func PoolSpecToIPSet(poolSpec *v1beta2.SubnetSpec) (*netipx.IPSet, error) {
 	return ipSet, err
 }
 
+// ParseExcludeRange parses an exclude range given as a single IP, a CIDR or a
+// "start-end" range such as "10.0.0.1-10.0.0.10".
+func ParseExcludeRange(excludeRange string) (netipx.IPRange, error) {
+	excludeRange = strings.TrimSpace(excludeRange)
+
+	if ip, err := netip.ParseAddr(excludeRange); err == nil {
+		return netipx.IPRangeFrom(ip, ip), nil
+	}
+
+	if prefix, err := netip.ParsePrefix(excludeRange); err == nil {
+		return netipx.RangeOfPrefix(prefix), nil
+	}
+
+	start, end, found := strings.Cut(excludeRange, "-")
+	if !found {
+		return netipx.IPRange{}, fmt.Errorf("invalid exclude range %q: must be an IP address, CIDR or start-end range", excludeRange)
+	}
+
+	startIP, err := netip.ParseAddr(strings.TrimSpace(start))
+	if err != nil {
+		return netipx.IPRange{}, fmt.Errorf("invalid start of exclude range %q: %w", excludeRange, err)
+	}
+	endIP, err := netip.ParseAddr(strings.TrimSpace(end))
+	if err != nil {
+		return netipx.IPRange{}, fmt.Errorf("invalid end of exclude range %q: %w", excludeRange, err)
+	}
+
+	r := netipx.IPRangeFrom(startIP, endIP)
+	if !r.IsValid() {
+		return netipx.IPRange{}, fmt.Errorf("invalid exclude range %q: start must not be after end and both must be of the same family", excludeRange)
+	}
+
+	return r, nil
+}
+
+// FormatExcludeRange formats a range as a single IP or CIDR when it is exactly
+// one address or prefix, and as a "start-end" range otherwise.
+func FormatExcludeRange(r netipx.IPRange) string {
+	if r.From() == r.To() {
+		return r.From().String()
+	}
+	if prefix, ok := r.Prefix(); ok {
+		return prefix.String()
+	}
+	return r.From().String() + "-" + r.To().String()
+}
+
 // FindNextAvailableIP finds the next available IP address in the pool.
 func FindNextAvailableIP(poolIPSet, inUseIPSet *netipx.IPSet) (string, error) {
 	if poolIPSet == nil || inUseIPSet == nil {
@@ -192,13 +247,17 @@ func FindNextAvailableIP(poolIPSet, inUseIPSet *netipx.IPSet) (string, error) {
 }
 
 // ComputePoolStatus computes the status summary for a pool.
-func ComputePoolStatus(poolIPSet *netipx.IPSet, addressesInUse []ipamv1beta2.IPAddress, poolNamespace string) *v1beta2.IPAddressStatusSummary {
+// Addresses held by UnifiIPReservations count as used alongside CAPI IPAddresses.
+func ComputePoolStatus(poolIPSet *netipx.IPSet, addressesInUse []ipamv1beta2.IPAddress, reservations []v1beta2.UnifiIPReservation, poolNamespace string) *v1beta2.IPAddressStatusSummary {
 	if poolIPSet == nil {
 		return &v1beta2.IPAddressStatusSummary{}
 	}
 
-	totalCount := computeTotalAddresses(poolIPSet)
+	totalCount := CountAddresses(poolIPSet)
 	usedCount, outOfRangeCount := computeAddressUsage(poolIPSet, addressesInUse, poolNamespace)
+	reservedCount, reservedOutOfRange := computeReservationUsage(poolIPSet, reservations)
+	usedCount += reservedCount
+	outOfRangeCount += reservedOutOfRange
 
 	freeCount := totalCount - usedCount
 	if freeCount < 0 {
@@ -219,6 +278,106 @@ func ComputePoolStatus(poolIPSet *netipx.IPSet, addressesInUse []ipamv1beta2.IPA
 	}
 }
 
+// ComputeSubnetStatus computes the address statistics of every subnet of a pool.
+// Subnets that can't be converted to an IPSet are reported without counts.
+func ComputeSubnetStatus(spec *v1beta2.UnifiIPPoolSpec, addressesInUse []ipamv1beta2.IPAddress, reservations []v1beta2.UnifiIPReservation, poolNamespace string) []v1beta2.SubnetAddressStatus {
+	if spec == nil || len(spec.Subnets) == 0 {
+		return nil
+	}
+
+	subnets := make([]v1beta2.SubnetAddressStatus, 0, len(spec.Subnets))
+	for i := range spec.Subnets {
+		subnet := &spec.Subnets[i]
+		subnetStatus := v1beta2.SubnetAddressStatus{Subnet: subnetName(subnet)}
+
+		subnetIPSet, err := subnetAllocatableIPSet(subnet, spec.Gateway)
+		if err != nil {
+			subnets = append(subnets, subnetStatus)
+			continue
+		}
+
+		totalCount := CountAddresses(subnetIPSet)
+		usedCount, _ := computeAddressUsage(subnetIPSet, addressesInUse, poolNamespace)
+		reservedCount, _ := computeReservationUsage(subnetIPSet, reservations)
+		usedCount += reservedCount
+
+		freeCount := totalCount - usedCount
+		if freeCount < 0 {
+			freeCount = 0
+		}
+
+		total := safeIntToInt32(totalCount)
+		used := safeIntToInt32(usedCount)
+		free := safeIntToInt32(freeCount)
+		subnetStatus.Total = &total
+		subnetStatus.Used = &used
+		subnetStatus.Free = &free
+
+		subnets = append(subnets, subnetStatus)
+	}
+
+	return subnets
+}
+
+// SubnetFreeRanges returns the free address ranges of each subnet of the pool, in the
+// order of the spec. inUse holds the addresses that are not free. Subnets that fail
+// to parse have no free ranges.
+func SubnetFreeRanges(spec *v1beta2.UnifiIPPoolSpec, inUse []string) ([][]netipx.IPRange, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	used, err := AddressesToIPSet(inUse)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([][]netipx.IPRange, len(spec.Subnets))
+	for i := range spec.Subnets {
+		subnetIPSet, err := subnetAllocatableIPSet(&spec.Subnets[i], spec.Gateway)
+		if err != nil {
+			continue
+		}
+
+		var builder netipx.IPSetBuilder
+		builder.AddSet(subnetIPSet)
+		builder.RemoveSet(used)
+		free, err := builder.IPSet()
+		if err != nil {
+			continue
+		}
+		ranges[i] = free.Ranges()
+	}
+	return ranges, nil
+}
+
+// subnetName returns the CIDR or start-end range identifying a subnet.
+func subnetName(subnet *v1beta2.SubnetSpec) string {
+	if subnet.CIDR != "" {
+		return subnet.CIDR
+	}
+	return subnet.Start + "-" + subnet.End
+}
+
+// subnetAllocatableIPSet returns the allocatable addresses of a subnet, also
+// removing the pool-level gateway.
+func subnetAllocatableIPSet(subnet *v1beta2.SubnetSpec, poolGateway string) (*netipx.IPSet, error) {
+	subnetIPSet, err := PoolSpecToIPSet(subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	gateway, err := netip.ParseAddr(poolGateway)
+	if err != nil || !subnetIPSet.Contains(gateway) {
+		return subnetIPSet, nil
+	}
+
+	var builder netipx.IPSetBuilder
+	builder.AddSet(subnetIPSet)
+	builder.Remove(gateway)
+	return builder.IPSet()
+}
+
 // safeIntToInt32 converts int to int32, capping at int32 max/min values to prevent overflow.
 func safeIntToInt32(i int) int32 {
 	const maxInt32 = 2147483647
@@ -232,21 +391,6 @@ func safeIntToInt32(i int) int32 {
 	return int32(i)
 }
 
-func computeTotalAddresses(poolIPSet *netipx.IPSet) int {
-	totalCount := 0
-	for _, r := range poolIPSet.Ranges() {
-		from := r.From()
-		to := r.To()
-		if from.Is4() {
-			totalCount += int(to.As4()[3] - from.As4()[3] + 1)
-		} else {
-			// IPv6 - approximate.
-			totalCount += 1000
-		}
-	}
-	return totalCount
-}
-
 func computeAddressUsage(poolIPSet *netipx.IPSet, addressesInUse []ipamv1beta2.IPAddress, poolNamespace string) (used, outOfRange int) {
 	for _, addr := range addressesInUse {
 		if poolNamespace != "" && addr.Namespace != poolNamespace {
@@ -270,3 +414,19 @@ func computeAddressUsage(poolIPSet *netipx.IPSet, addressesInUse []ipamv1beta2.I
 	}
 	return used, outOfRange
 }
+
+func computeReservationUsage(poolIPSet *netipx.IPSet, reservations []v1beta2.UnifiIPReservation) (used, outOfRange int) {
+	for _, reservation := range reservations {
+		ip, err := netip.ParseAddr(reservation.Status.Address)
+		if err != nil {
+			continue
+		}
+
+		if poolIPSet.Contains(ip) {
+			used++
+		} else {
+			outOfRange++
+		}
+	}
+	return used, outOfRange
+}
@@ -18,10 +18,12 @@ package poolutil
 
 import (
 	"context"
+	"net/netip"
 	"reflect"
 	"testing"
 
 	"go4.org/netipx"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
@@ -142,6 +144,7 @@ func TestComputePoolStatus(t *testing.T) {
 	type args struct {
 		poolIPSet      *netipx.IPSet
 		addressesInUse []ipamv1beta2.IPAddress
+		reservations   []v1beta2.UnifiIPReservation
 		poolNamespace  string
 	}
 	tests := []struct {
@@ -149,13 +152,188 @@ func TestComputePoolStatus(t *testing.T) {
 		args args
 		want *v1beta2.IPAddressStatusSummary
 	}{
-		// TODO: Add test cases.
+		{
+			name: "nil pool",
+			args: args{},
+			want: &v1beta2.IPAddressStatusSummary{},
+		},
+		{
+			name: "addresses and reservations",
+			args: args{
+				poolIPSet: mustIPSet(t, "192.168.1.10", "192.168.1.19"),
+				addressesInUse: []ipamv1beta2.IPAddress{
+					{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+						Spec:       ipamv1beta2.IPAddressSpec{Address: "192.168.1.10"},
+					},
+				},
+				reservations: []v1beta2.UnifiIPReservation{
+					{Status: v1beta2.UnifiIPReservationStatus{Address: "192.168.1.11"}},
+					{Status: v1beta2.UnifiIPReservationStatus{Address: "192.168.1.50"}},
+				},
+				poolNamespace: "default",
+			},
+			want: &v1beta2.IPAddressStatusSummary{
+				Total:      int32Ptr(10),
+				Used:       int32Ptr(2),
+				Free:       int32Ptr(8),
+				OutOfRange: int32Ptr(1),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := ComputePoolStatus(tt.args.poolIPSet, tt.args.addressesInUse, tt.args.poolNamespace); !reflect.DeepEqual(got, tt.want) {
+			if got := ComputePoolStatus(tt.args.poolIPSet, tt.args.addressesInUse, tt.args.reservations, tt.args.poolNamespace); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ComputePoolStatus() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestComputeSubnetStatus(t *testing.T) {
+	spec := &v1beta2.UnifiIPPoolSpec{
+		Gateway: "10.0.1.1",
+		Subnets: []v1beta2.SubnetSpec{
+			{CIDR: "10.0.0.0/24", Gateway: "10.0.0.1", ExcludeRanges: []string{"10.0.0.2-10.0.0.201"}},
+			{Start: "10.0.1.1", End: "10.0.1.10"},
+			{CIDR: "invalid"},
+		},
+	}
+	addressesInUse := []ipamv1beta2.IPAddress{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}, Spec: ipamv1beta2.IPAddressSpec{Address: "10.0.0.202"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}, Spec: ipamv1beta2.IPAddressSpec{Address: "10.0.1.2"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "other"}, Spec: ipamv1beta2.IPAddressSpec{Address: "10.0.1.3"}},
+	}
+	reservations := []v1beta2.UnifiIPReservation{
+		{Status: v1beta2.UnifiIPReservationStatus{Address: "10.0.1.4"}},
+	}
+
+	want := []v1beta2.SubnetAddressStatus{
+		{Subnet: "10.0.0.0/24", Total: int32Ptr(53), Used: int32Ptr(1), Free: int32Ptr(52)},
+		{Subnet: "10.0.1.1-10.0.1.10", Total: int32Ptr(9), Used: int32Ptr(2), Free: int32Ptr(7)},
+		{Subnet: "invalid"},
+	}
+
+	if got := ComputeSubnetStatus(spec, addressesInUse, reservations, "default"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ComputeSubnetStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSubnetFreeRanges(t *testing.T) {
+	spec := &v1beta2.UnifiIPPoolSpec{
+		Gateway: "10.0.1.1",
+		Subnets: []v1beta2.SubnetSpec{
+			{CIDR: "10.0.0.0/28", Gateway: "10.0.0.1", ExcludeRanges: []string{"10.0.0.2-10.0.0.4"}},
+			{Start: "10.0.1.1", End: "10.0.1.10"},
+			{CIDR: "invalid"},
+		},
+	}
+
+	got, err := SubnetFreeRanges(spec, []string{"10.0.0.8", "10.0.1.2", "10.0.1.10"})
+	if err != nil {
+		t.Fatalf("SubnetFreeRanges() error = %v", err)
+	}
+	want := []string{"10.0.0.5-10.0.0.7, 10.0.0.9-10.0.0.14", "10.0.1.3-10.0.1.9", ""}
+	if len(got) != len(want) {
+		t.Fatalf("SubnetFreeRanges() returned %d subnets, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if formatted := FormatRanges(got[i]); formatted != want[i] {
+			t.Errorf("subnet %d free ranges = %q, want %q", i, formatted, want[i])
+		}
+	}
+
+	if _, err := SubnetFreeRanges(spec, []string{"not-an-ip"}); err == nil {
+		t.Error("SubnetFreeRanges() with an invalid address in use succeeded, want error")
+	}
+}
+
+func mustIPSet(t *testing.T, from, to string) *netipx.IPSet {
+	t.Helper()
+	var builder netipx.IPSetBuilder
+	builder.AddRange(netipx.IPRangeFrom(netip.MustParseAddr(from), netip.MustParseAddr(to)))
+	set, err := builder.IPSet()
+	if err != nil {
+		t.Fatalf("failed to build IPSet: %v", err)
+	}
+	return set
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestParseExcludeRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		excludeRange string
+		wantFrom     string
+		wantTo       string
+		wantErr      bool
+	}{
+		{name: "single IP", excludeRange: "10.0.0.5", wantFrom: "10.0.0.5", wantTo: "10.0.0.5"},
+		{name: "CIDR", excludeRange: "10.0.0.0/30", wantFrom: "10.0.0.0", wantTo: "10.0.0.3"},
+		{name: "start-end range", excludeRange: "10.0.0.1-10.0.0.10", wantFrom: "10.0.0.1", wantTo: "10.0.0.10"},
+		{name: "range with spaces", excludeRange: " 10.0.0.1 - 10.0.0.10 ", wantFrom: "10.0.0.1", wantTo: "10.0.0.10"},
+		{name: "IPv6 range", excludeRange: "2001:db8::1-2001:db8::ff", wantFrom: "2001:db8::1", wantTo: "2001:db8::ff"},
+		{name: "reversed range", excludeRange: "10.0.0.10-10.0.0.1", wantErr: true},
+		{name: "mixed families", excludeRange: "10.0.0.1-2001:db8::1", wantErr: true},
+		{name: "invalid end", excludeRange: "10.0.0.1-10.0.0", wantErr: true},
+		{name: "garbage", excludeRange: "not-an-ip", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExcludeRange(tt.excludeRange)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExcludeRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.From().String() != tt.wantFrom || got.To().String() != tt.wantTo {
+				t.Errorf("ParseExcludeRange() = %v, want %s-%s", got, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestFormatExcludeRange(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{name: "single IP", from: "10.0.0.5", to: "10.0.0.5", want: "10.0.0.5"},
+		{name: "exact prefix", from: "10.0.0.0", to: "10.0.0.127", want: "10.0.0.0/25"},
+		{name: "unaligned range", from: "10.0.0.1", to: "10.0.0.99", want: "10.0.0.1-10.0.0.99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := netipx.IPRangeFrom(netip.MustParseAddr(tt.from), netip.MustParseAddr(tt.to))
+			if got := FormatExcludeRange(r); got != tt.want {
+				t.Errorf("FormatExcludeRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolSpecToIPSet_ExcludeRanges(t *testing.T) {
+	subnet := &v1beta2.SubnetSpec{
+		CIDR:          "10.0.0.0/24",
+		Gateway:       "10.0.0.1",
+		ExcludeRanges: []string{"10.0.0.2-10.0.0.99", "10.0.0.128/25", "10.0.0.100"},
+	}
+	got, err := PoolSpecToIPSet(subnet)
+	if err != nil {
+		t.Fatalf("PoolSpecToIPSet() error = %v", err)
+	}
+	if ranges := FormatRanges(got.Ranges()); ranges != "10.0.0.101-10.0.0.127" {
+		t.Errorf("PoolSpecToIPSet() = %v, want 10.0.0.101-10.0.0.127", ranges)
+	}
+
+	subnet.ExcludeRanges = []string{"10.0.0.99-10.0.0.2"}
+	if _, err := PoolSpecToIPSet(subnet); err == nil {
+		t.Errorf("PoolSpecToIPSet() expected error for invalid exclude range")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+// QuarantinePeriod returns the pool's quarantine period, zero if disabled.
+func QuarantinePeriod(spec *v1beta2.UnifiIPPoolSpec) time.Duration {
+	if spec.QuarantinePeriod == nil || spec.QuarantinePeriod.Duration < 0 {
+		return 0
+	}
+	return spec.QuarantinePeriod.Duration
+}
+
+// QuarantinedAddresses returns the released addresses of a pool that are still in quarantine at now.
+func QuarantinedAddresses(spec *v1beta2.UnifiIPPoolSpec, status *v1beta2.UnifiIPPoolStatus, now time.Time) []string {
+	period := QuarantinePeriod(spec)
+	if period == 0 {
+		return nil
+	}
+
+	var quarantined []string
+	for _, released := range status.ReleasedAddresses {
+		if now.Before(released.ReleasedAt.Add(period)) {
+			quarantined = append(quarantined, released.Address)
+		}
+	}
+	return quarantined
+}
+
+// UpdateReleasedAddresses diffs the previous and current allocations (claim name to
+// address) of a pool. Addresses that are no longer allocated are added to the
+// released list at now; entries whose quarantine expired or that are allocated
+// again are dropped. A zero period disables tracking.
+func UpdateReleasedAddresses(released []v1beta2.ReleasedAddress, previous, current map[string]string, period time.Duration, now time.Time) []v1beta2.ReleasedAddress {
+	if period <= 0 {
+		return nil
+	}
+
+	allocated := make(map[string]bool, len(current))
+	for _, address := range current {
+		allocated[address] = true
+	}
+
+	updated := make([]v1beta2.ReleasedAddress, 0, len(released))
+	tracked := make(map[string]bool, len(released))
+	for _, entry := range released {
+		if allocated[entry.Address] || !now.Before(entry.ReleasedAt.Add(period)) {
+			continue
+		}
+		updated = append(updated, entry)
+		tracked[entry.Address] = true
+	}
+
+	for claimName, address := range previous {
+		if address == "" || allocated[address] || tracked[address] {
+			continue
+		}
+		updated = append(updated, v1beta2.ReleasedAddress{
+			Address:    address,
+			ClaimName:  claimName,
+			ReleasedAt: metav1.NewTime(now),
+		})
+		tracked[address] = true
+	}
+
+	if len(updated) == 0 {
+		return nil
+	}
+
+	sort.Slice(updated, func(i, j int) bool {
+		return updated[i].Address < updated[j].Address
+	})
+	return updated
+}
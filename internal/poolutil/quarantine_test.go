@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func TestUpdateReleasedAddresses(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	earlier := metav1.NewTime(now.Add(-5 * time.Minute))
+	expired := metav1.NewTime(now.Add(-time.Hour))
+
+	tests := []struct {
+		name     string
+		released []v1beta2.ReleasedAddress
+		previous map[string]string
+		current  map[string]string
+		period   time.Duration
+		want     []v1beta2.ReleasedAddress
+	}{
+		{
+			name:     "disabled",
+			previous: map[string]string{"a": "10.0.0.1"},
+			period:   0,
+			want:     nil,
+		},
+		{
+			name:     "released address is tracked",
+			previous: map[string]string{"a": "10.0.0.1", "b": "10.0.0.2"},
+			current:  map[string]string{"b": "10.0.0.2"},
+			period:   10 * time.Minute,
+			want: []v1beta2.ReleasedAddress{
+				{Address: "10.0.0.1", ClaimName: "a", ReleasedAt: metav1.NewTime(now)},
+			},
+		},
+		{
+			name: "expired and reallocated entries are dropped",
+			released: []v1beta2.ReleasedAddress{
+				{Address: "10.0.0.3", ClaimName: "c", ReleasedAt: earlier},
+				{Address: "10.0.0.4", ClaimName: "d", ReleasedAt: expired},
+				{Address: "10.0.0.5", ClaimName: "e", ReleasedAt: earlier},
+			},
+			current: map[string]string{"f": "10.0.0.5"},
+			period:  10 * time.Minute,
+			want: []v1beta2.ReleasedAddress{
+				{Address: "10.0.0.3", ClaimName: "c", ReleasedAt: earlier},
+			},
+		},
+		{
+			name: "already tracked address keeps its release time",
+			released: []v1beta2.ReleasedAddress{
+				{Address: "10.0.0.1", ClaimName: "a", ReleasedAt: earlier},
+			},
+			previous: map[string]string{"a": "10.0.0.1"},
+			period:   10 * time.Minute,
+			want: []v1beta2.ReleasedAddress{
+				{Address: "10.0.0.1", ClaimName: "a", ReleasedAt: earlier},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UpdateReleasedAddresses(tt.released, tt.previous, tt.current, tt.period, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UpdateReleasedAddresses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuarantinedAddresses(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	status := &v1beta2.UnifiIPPoolStatus{
+		ReleasedAddresses: []v1beta2.ReleasedAddress{
+			{Address: "10.0.0.1", ReleasedAt: metav1.NewTime(now.Add(-time.Minute))},
+			{Address: "10.0.0.2", ReleasedAt: metav1.NewTime(now.Add(-time.Hour))},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		period *metav1.Duration
+		want   []string
+	}{
+		{name: "no quarantine", period: nil, want: nil},
+		{name: "only unexpired addresses", period: &metav1.Duration{Duration: 10 * time.Minute}, want: []string{"10.0.0.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1beta2.UnifiIPPoolSpec{QuarantinePeriod: tt.period}
+			if got := QuarantinedAddresses(spec, status, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QuarantinedAddresses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+// QuotaConsumer describes the claim holding, or asking for, an address of a pool.
+type QuotaConsumer struct {
+	Namespace   string
+	ClusterName string
+	Labels      map[string]string
+}
+
+// QuotaExceededError is returned when allocating an address would exceed a quota.
+type QuotaExceededError struct {
+	Type  v1beta2.QuotaType
+	Name  string
+	Limit int32
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %s exceeded: limit is %d addresses", e.Type, e.Name, e.Limit)
+}
+
+// quotaKey identifies a consumer of one quota.
+type quotaKey struct {
+	Type v1beta2.QuotaType
+	Name string
+}
+
+// quotaLimits returns the quotas applying to a consumer and their limits.
+func quotaLimits(quotas *v1beta2.QuotaSpec, selectors []labels.Selector, consumer QuotaConsumer) map[quotaKey]int32 {
+	limits := make(map[quotaKey]int32)
+	if quotas.MaxPerCluster != nil && consumer.ClusterName != "" {
+		limits[quotaKey{v1beta2.QuotaTypeCluster, consumer.Namespace + "/" + consumer.ClusterName}] = *quotas.MaxPerCluster
+	}
+	if quotas.MaxPerNamespace != nil {
+		limits[quotaKey{v1beta2.QuotaTypeNamespace, consumer.Namespace}] = *quotas.MaxPerNamespace
+	}
+	for i, selector := range selectors {
+		if selector.Matches(labels.Set(consumer.Labels)) {
+			limits[quotaKey{v1beta2.QuotaTypeSelector, quotas.Selectors[i].Name}] = quotas.Selectors[i].Max
+		}
+	}
+	return limits
+}
+
+// quotaSelectors parses the label selectors of the selector quotas.
+func quotaSelectors(quotas *v1beta2.QuotaSpec) ([]labels.Selector, error) {
+	selectors := make([]labels.Selector, 0, len(quotas.Selectors))
+	for _, quota := range quotas.Selectors {
+		selector, err := metav1.LabelSelectorAsSelector(&quota.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of quota %s: %w", quota.Name, err)
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// ComputeQuotaUsage counts the addresses held per consumer for each quota of the pool.
+// Selector quotas are always listed, other consumers only while they hold addresses.
+func ComputeQuotaUsage(spec *v1beta2.UnifiIPPoolSpec, consumers []QuotaConsumer) ([]v1beta2.QuotaUsage, error) {
+	if spec.Quotas == nil {
+		return nil, nil
+	}
+
+	selectors, err := quotaSelectors(spec.Quotas)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[quotaKey]*v1beta2.QuotaUsage)
+	for _, quota := range spec.Quotas.Selectors {
+		key := quotaKey{v1beta2.QuotaTypeSelector, quota.Name}
+		usage[key] = &v1beta2.QuotaUsage{Type: key.Type, Name: key.Name, Limit: quota.Max}
+	}
+	for _, consumer := range consumers {
+		for key, limit := range quotaLimits(spec.Quotas, selectors, consumer) {
+			entry, ok := usage[key]
+			if !ok {
+				entry = &v1beta2.QuotaUsage{Type: key.Type, Name: key.Name, Limit: limit}
+				usage[key] = entry
+			}
+			entry.Used++
+		}
+	}
+
+	if len(usage) == 0 {
+		return nil, nil
+	}
+
+	result := make([]v1beta2.QuotaUsage, 0, len(usage))
+	for _, entry := range usage {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// CheckQuota returns a *QuotaExceededError if allocating one more address to the
+// claim would exceed a quota, given the consumers already holding addresses.
+func CheckQuota(spec *v1beta2.UnifiIPPoolSpec, consumers []QuotaConsumer, claim QuotaConsumer) error {
+	if spec.Quotas == nil {
+		return nil
+	}
+
+	selectors, err := quotaSelectors(spec.Quotas)
+	if err != nil {
+		return err
+	}
+
+	used := make(map[quotaKey]int32)
+	for _, consumer := range consumers {
+		for key := range quotaLimits(spec.Quotas, selectors, consumer) {
+			used[key]++
+		}
+	}
+
+	// Report the first exceeded quota in a stable order.
+	limits := quotaLimits(spec.Quotas, selectors, claim)
+	keys := make([]quotaKey, 0, len(limits))
+	for key := range limits {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type < keys[j].Type
+		}
+		return keys[i].Name < keys[j].Name
+	})
+
+	for _, key := range keys {
+		if used[key]+1 > limits[key] {
+			return &QuotaExceededError{Type: key.Type, Name: key.Name, Limit: limits[key]}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func testQuotaSpec() *v1beta2.UnifiIPPoolSpec {
+	return &v1beta2.UnifiIPPoolSpec{
+		Quotas: &v1beta2.QuotaSpec{
+			MaxPerCluster:   int32Ptr(2),
+			MaxPerNamespace: int32Ptr(3),
+			Selectors: []v1beta2.SelectorQuota{{
+				Name:     "workers",
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"role": "worker"}},
+				Max:      1,
+			}},
+		},
+	}
+}
+
+func TestComputeQuotaUsage(t *testing.T) {
+	consumers := []QuotaConsumer{
+		{Namespace: "a", ClusterName: "prod", Labels: map[string]string{"role": "worker"}},
+		{Namespace: "a", ClusterName: "prod"},
+		{Namespace: "b"},
+	}
+
+	got, err := ComputeQuotaUsage(testQuotaSpec(), consumers)
+	if err != nil {
+		t.Fatalf("ComputeQuotaUsage() error = %v", err)
+	}
+
+	want := []v1beta2.QuotaUsage{
+		{Type: v1beta2.QuotaTypeCluster, Name: "a/prod", Used: 2, Limit: 2},
+		{Type: v1beta2.QuotaTypeNamespace, Name: "a", Used: 2, Limit: 3},
+		{Type: v1beta2.QuotaTypeNamespace, Name: "b", Used: 1, Limit: 3},
+		{Type: v1beta2.QuotaTypeSelector, Name: "workers", Used: 1, Limit: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ComputeQuotaUsage() = %+v, want %+v", got, want)
+	}
+
+	if got, err := ComputeQuotaUsage(&v1beta2.UnifiIPPoolSpec{}, consumers); err != nil || got != nil {
+		t.Errorf("ComputeQuotaUsage() without quotas = %+v, %v, want nil", got, err)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	consumers := []QuotaConsumer{
+		{Namespace: "a", ClusterName: "prod", Labels: map[string]string{"role": "worker"}},
+		{Namespace: "a", ClusterName: "prod"},
+		{Namespace: "a", ClusterName: "dev"},
+	}
+
+	tests := []struct {
+		name     string
+		spec     *v1beta2.UnifiIPPoolSpec
+		claim    QuotaConsumer
+		wantType v1beta2.QuotaType
+	}{
+		{
+			name:  "no quotas",
+			spec:  &v1beta2.UnifiIPPoolSpec{},
+			claim: QuotaConsumer{Namespace: "a", ClusterName: "prod"},
+		},
+		{
+			name:     "cluster quota reached",
+			spec:     testQuotaSpec(),
+			claim:    QuotaConsumer{Namespace: "a", ClusterName: "prod"},
+			wantType: v1beta2.QuotaTypeCluster,
+		},
+		{
+			name:     "namespace quota reached",
+			spec:     testQuotaSpec(),
+			claim:    QuotaConsumer{Namespace: "a", ClusterName: "staging"},
+			wantType: v1beta2.QuotaTypeNamespace,
+		},
+		{
+			name:     "selector quota reached",
+			spec:     testQuotaSpec(),
+			claim:    QuotaConsumer{Namespace: "b", Labels: map[string]string{"role": "worker"}},
+			wantType: v1beta2.QuotaTypeSelector,
+		},
+		{
+			name:  "within quotas",
+			spec:  testQuotaSpec(),
+			claim: QuotaConsumer{Namespace: "b", ClusterName: "prod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckQuota(tt.spec, consumers, tt.claim)
+			if tt.wantType == "" {
+				if err != nil {
+					t.Errorf("CheckQuota() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var quotaErr *QuotaExceededError
+			if !errors.As(err, &quotaErr) {
+				t.Fatalf("CheckQuota() error = %v, want *QuotaExceededError", err)
+			}
+			if quotaErr.Type != tt.wantType {
+				t.Errorf("CheckQuota() exceeded %s quota, want %s", quotaErr.Type, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+const (
+	// StickyKeyAnnotation sets the sticky key of a claim explicitly. The controller
+	// also records the key of the allocating claim on its IPAddress.
+	StickyKeyAnnotation = "unifi.ipam.cluster.x-k8s.io/sticky-key"
+
+	// DefaultLeaseDuration is how long a released address stays leased to its sticky key.
+	DefaultLeaseDuration = time.Hour
+)
+
+// ErrStickyLeasePending is returned when the address leased to a sticky key is still
+// held by an IPAddress being deleted, whose Unifi fixed IP is not released yet.
+var ErrStickyLeasePending = errors.New("sticky lease is pending release")
+
+// StickyKey returns the sticky key of a claim, empty if the pool has no stickiness
+// configured or the claim carries neither the annotation nor all label keys.
+func StickyKey(spec *v1beta2.UnifiIPPoolSpec, annotations, labels map[string]string) string {
+	if spec.Stickiness == nil {
+		return ""
+	}
+	if key := strings.TrimSpace(annotations[StickyKeyAnnotation]); key != "" {
+		return key
+	}
+	if len(spec.Stickiness.LabelKeys) == 0 {
+		return ""
+	}
+
+	values := make([]string, 0, len(spec.Stickiness.LabelKeys))
+	for _, labelKey := range spec.Stickiness.LabelKeys {
+		value := labels[labelKey]
+		if value == "" {
+			return ""
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, "/")
+}
+
+// LeaseDuration returns how long released addresses stay leased to their sticky key.
+func LeaseDuration(spec *v1beta2.UnifiIPPoolSpec) time.Duration {
+	if spec.Stickiness == nil || spec.Stickiness.LeaseDuration == nil || spec.Stickiness.LeaseDuration.Duration < 0 {
+		return DefaultLeaseDuration
+	}
+	return spec.Stickiness.LeaseDuration.Duration
+}
+
+// UpdateLeases rebuilds the leases of a pool from the addresses in use. Addresses
+// carrying a sticky key get an active lease; leases whose address is no longer in
+// use start expiring at now, and expired leases are dropped.
+func UpdateLeases(leases []v1beta2.AddressLease, addressesInUse []ipamv1beta2.IPAddress, duration time.Duration, now time.Time) []v1beta2.AddressLease {
+	updated := make([]v1beta2.AddressLease, 0, len(leases)+len(addressesInUse))
+	active := make(map[string]bool, len(addressesInUse))
+	allocated := make(map[string]bool, len(addressesInUse))
+
+	for _, addr := range addressesInUse {
+		allocated[addr.Spec.Address] = true
+		key := addr.Annotations[StickyKeyAnnotation]
+		if key == "" {
+			continue
+		}
+		updated = append(updated, v1beta2.AddressLease{
+			Key:       key,
+			Address:   addr.Spec.Address,
+			ClaimName: addr.Spec.ClaimRef.Name,
+		})
+		active[key] = true
+	}
+
+	for _, lease := range leases {
+		// A key holds a single lease and an address a single key.
+		if active[lease.Key] || allocated[lease.Address] {
+			continue
+		}
+		if lease.ExpiresAt == nil {
+			expiresAt := metav1.NewTime(now.Add(duration))
+			lease.ExpiresAt = &expiresAt
+		} else if !now.Before(lease.ExpiresAt.Time) {
+			continue
+		}
+		updated = append(updated, lease)
+	}
+
+	if len(updated) == 0 {
+		return nil
+	}
+
+	sort.Slice(updated, func(i, j int) bool {
+		if updated[i].Key != updated[j].Key {
+			return updated[i].Key < updated[j].Key
+		}
+		return updated[i].Address < updated[j].Address
+	})
+	return updated
+}
+
+// StickyLeaseAddress returns the address leased to key at now, empty if there is none.
+// Holders are taken from the live addresses in use rather than from the lease expiry
+// the pool controller records on release: a lease whose holder is gone is released
+// even before the pool noticed, and a lease whose holder is being deleted returns
+// ErrStickyLeasePending. Leases held by a claim are not returned.
+func StickyLeaseAddress(status *v1beta2.UnifiIPPoolStatus, addressesInUse []ipamv1beta2.IPAddress, key string, now time.Time) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+
+	allocated := make(map[string]bool, len(addressesInUse))
+	for _, addr := range addressesInUse {
+		if addr.Annotations[StickyKeyAnnotation] == key {
+			if !addr.DeletionTimestamp.IsZero() {
+				return "", fmt.Errorf("%w: %s is held by IPAddress %s/%s being deleted",
+					ErrStickyLeasePending, addr.Spec.Address, addr.Namespace, addr.Name)
+			}
+			return "", nil
+		}
+		allocated[addr.Spec.Address] = true
+	}
+
+	for _, lease := range status.Leases {
+		if lease.Key != key || allocated[lease.Address] {
+			continue
+		}
+		if lease.ExpiresAt == nil || now.Before(lease.ExpiresAt.Time) {
+			return lease.Address, nil
+		}
+	}
+	return "", nil
+}
+
+// LeasedAddresses returns the addresses leased to a sticky key at now. Leases the pool
+// has not started expiring are included, as their holder may be gone already.
+func LeasedAddresses(status *v1beta2.UnifiIPPoolStatus, now time.Time) []string {
+	var leased []string
+	for _, lease := range status.Leases {
+		if lease.ExpiresAt == nil || now.Before(lease.ExpiresAt.Time) {
+			leased = append(leased, lease.Address)
+		}
+	}
+	return leased
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func TestStickyKey(t *testing.T) {
+	stickiness := &v1beta2.StickinessSpec{LabelKeys: []string{"cluster", "index"}}
+
+	tests := []struct {
+		name        string
+		stickiness  *v1beta2.StickinessSpec
+		annotations map[string]string
+		labels      map[string]string
+		want        string
+	}{
+		{
+			name:        "stickiness disabled",
+			annotations: map[string]string{StickyKeyAnnotation: "cp-0"},
+			want:        "",
+		},
+		{
+			name:        "annotation wins over labels",
+			stickiness:  stickiness,
+			annotations: map[string]string{StickyKeyAnnotation: "cp-0"},
+			labels:      map[string]string{"cluster": "prod", "index": "1"},
+			want:        "cp-0",
+		},
+		{
+			name:       "label values are joined in order",
+			stickiness: stickiness,
+			labels:     map[string]string{"index": "1", "cluster": "prod"},
+			want:       "prod/1",
+		},
+		{
+			name:       "missing label",
+			stickiness: stickiness,
+			labels:     map[string]string{"cluster": "prod"},
+			want:       "",
+		},
+		{
+			name:       "no label keys",
+			stickiness: &v1beta2.StickinessSpec{},
+			labels:     map[string]string{"cluster": "prod"},
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1beta2.UnifiIPPoolSpec{Stickiness: tt.stickiness}
+			if got := StickyKey(spec, tt.annotations, tt.labels); got != tt.want {
+				t.Errorf("StickyKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateLeases(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	duration := time.Hour
+	expiresAt := metav1.NewTime(now.Add(duration))
+	later := metav1.NewTime(now.Add(10 * time.Minute))
+	expired := metav1.NewTime(now.Add(-time.Minute))
+
+	address := func(claim, ip, key string) ipamv1beta2.IPAddress {
+		addr := ipamv1beta2.IPAddress{
+			Spec: ipamv1beta2.IPAddressSpec{
+				Address:  ip,
+				ClaimRef: ipamv1beta2.IPAddressClaimReference{Name: claim},
+			},
+		}
+		if key != "" {
+			addr.Annotations = map[string]string{StickyKeyAnnotation: key}
+		}
+		return addr
+	}
+
+	tests := []struct {
+		name      string
+		leases    []v1beta2.AddressLease
+		addresses []ipamv1beta2.IPAddress
+		want      []v1beta2.AddressLease
+	}{
+		{
+			name:      "addresses without key are not leased",
+			addresses: []ipamv1beta2.IPAddress{address("a", "10.0.0.1", "")},
+			want:      nil,
+		},
+		{
+			name:      "allocated address holds an active lease",
+			addresses: []ipamv1beta2.IPAddress{address("a", "10.0.0.1", "cp-0")},
+			want: []v1beta2.AddressLease{
+				{Key: "cp-0", Address: "10.0.0.1", ClaimName: "a"},
+			},
+		},
+		{
+			name: "released address starts expiring",
+			leases: []v1beta2.AddressLease{
+				{Key: "cp-0", Address: "10.0.0.1", ClaimName: "a"},
+			},
+			want: []v1beta2.AddressLease{
+				{Key: "cp-0", Address: "10.0.0.1", ClaimName: "a", ExpiresAt: &expiresAt},
+			},
+		},
+		{
+			name: "expired leases are dropped",
+			leases: []v1beta2.AddressLease{
+				{Key: "cp-0", Address: "10.0.0.1", ClaimName: "a", ExpiresAt: &expired},
+				{Key: "cp-1", Address: "10.0.0.2", ClaimName: "b", ExpiresAt: &later},
+			},
+			want: []v1beta2.AddressLease{
+				{Key: "cp-1", Address: "10.0.0.2", ClaimName: "b", ExpiresAt: &later},
+			},
+		},
+		{
+			name: "replacement claim takes over the lease",
+			leases: []v1beta2.AddressLease{
+				{Key: "cp-0", Address: "10.0.0.1", ClaimName: "a", ExpiresAt: &later},
+			},
+			addresses: []ipamv1beta2.IPAddress{address("b", "10.0.0.1", "cp-0")},
+			want: []v1beta2.AddressLease{
+				{Key: "cp-0", Address: "10.0.0.1", ClaimName: "b"},
+			},
+		},
+		{
+			name: "address reused by another claim ends the lease",
+			leases: []v1beta2.AddressLease{
+				{Key: "cp-0", Address: "10.0.0.1", ClaimName: "a", ExpiresAt: &later},
+			},
+			addresses: []ipamv1beta2.IPAddress{address("b", "10.0.0.1", "")},
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UpdateLeases(tt.leases, tt.addresses, duration, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UpdateLeases() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStickyLeaseAddress(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := metav1.NewTime(now.Add(time.Minute))
+	expired := metav1.NewTime(now.Add(-time.Minute))
+
+	status := &v1beta2.UnifiIPPoolStatus{
+		Leases: []v1beta2.AddressLease{
+			{Key: "cp-0", Address: "10.0.0.1", ExpiresAt: &later},
+			{Key: "cp-1", Address: "10.0.0.2", ExpiresAt: &expired},
+			{Key: "cp-2", Address: "10.0.0.3"},
+			{Key: "cp-3", Address: "10.0.0.4"},
+			{Key: "cp-4", Address: "10.0.0.5"},
+		},
+	}
+	holder := func(key, ip string) ipamv1beta2.IPAddress {
+		return ipamv1beta2.IPAddress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        key,
+				Annotations: map[string]string{StickyKeyAnnotation: key},
+			},
+			Spec: ipamv1beta2.IPAddressSpec{Address: ip},
+		}
+	}
+	deleting := holder("cp-4", "10.0.0.5")
+	deleting.DeletionTimestamp = &expired
+	deleting.Finalizers = []string{"ipam.cluster.x-k8s.io/ProtectAddress"}
+	addressesInUse := []ipamv1beta2.IPAddress{holder("cp-3", "10.0.0.4"), deleting}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr error
+	}{
+		{name: "expiring lease", key: "cp-0", want: "10.0.0.1"},
+		{name: "expired lease", key: "cp-1"},
+		{name: "holder gone before the pool noticed", key: "cp-2", want: "10.0.0.3"},
+		{name: "held by a live claim", key: "cp-3"},
+		{name: "held by a deleting address", key: "cp-4", wantErr: ErrStickyLeasePending},
+		{name: "no key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StickyLeaseAddress(status, addressesInUse, tt.key, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StickyLeaseAddress(%q) error = %v, want %v", tt.key, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("StickyLeaseAddress(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	want := []string{"10.0.0.1", "10.0.0.3", "10.0.0.4", "10.0.0.5"}
+	if got := LeasedAddresses(status, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("LeasedAddresses() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+const (
+	// DefaultWarningUtilization is the default utilization percentage at which a pool
+	// reports high utilization.
+	DefaultWarningUtilization int32 = 80
+
+	// DefaultCriticalUtilization is the default utilization percentage at which a pool
+	// is reported as nearly exhausted.
+	DefaultCriticalUtilization int32 = 90
+
+	// DefaultSyncInterval is the default interval between syncs of a pool with Unifi.
+	DefaultSyncInterval = 10 * time.Minute
+
+	// DefaultSyncJitter is the default maximum fraction added to the sync interval so
+	// that pools created together do not sync in lockstep.
+	DefaultSyncJitter = 0.1
+
+	// MinSyncInterval is the shortest sync interval a pool may configure.
+	MinSyncInterval = time.Minute
+)
+
+// PoolDefaults are the manager-wide settings used for pools that do not configure them.
+// Zero thresholds and interval fall back to the package defaults; a zero SyncJitter
+// disables jitter.
+type PoolDefaults struct {
+	WarningUtilization  int32
+	CriticalUtilization int32
+	SyncInterval        time.Duration
+	SyncJitter          float64
+}
+
+// UtilizationThresholds returns the warning and critical utilization percentages of a
+// pool. The critical threshold is raised to the warning threshold if it is lower.
+func UtilizationThresholds(spec *v1beta2.UnifiIPPoolSpec, defaults PoolDefaults) (warning, critical int32) {
+	warning, critical = defaults.WarningUtilization, defaults.CriticalUtilization
+	if warning <= 0 {
+		warning = DefaultWarningUtilization
+	}
+	if critical <= 0 {
+		critical = DefaultCriticalUtilization
+	}
+
+	if thresholds := spec.UtilizationThresholds; thresholds != nil {
+		if thresholds.Warning != nil {
+			warning = *thresholds.Warning
+		}
+		if thresholds.Critical != nil {
+			critical = *thresholds.Critical
+		}
+	}
+
+	return warning, max(warning, critical)
+}
+
+// SyncInterval returns how often a pool is synced with Unifi, not shorter than MinSyncInterval.
+func SyncInterval(spec *v1beta2.UnifiIPPoolSpec, defaults PoolDefaults) time.Duration {
+	interval := defaults.SyncInterval
+	if spec.SyncInterval != nil {
+		interval = spec.SyncInterval.Duration
+	}
+	if interval <= 0 {
+		interval = DefaultSyncInterval
+	}
+	return max(interval, MinSyncInterval)
+}
+
+// NextSyncAfter returns the pool's sync interval with up to defaults.SyncJitter of it added.
+func NextSyncAfter(spec *v1beta2.UnifiIPPoolSpec, defaults PoolDefaults) time.Duration {
+	interval := SyncInterval(spec, defaults)
+	if defaults.SyncJitter <= 0 {
+		return interval
+	}
+	return wait.Jitter(interval, defaults.SyncJitter)
+}
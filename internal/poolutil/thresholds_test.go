@@ -0,0 +1,131 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolutil
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func TestUtilizationThresholds(t *testing.T) {
+	tests := []struct {
+		name         string
+		thresholds   *v1beta2.UtilizationThresholds
+		defaults     PoolDefaults
+		wantWarning  int32
+		wantCritical int32
+	}{
+		{
+			name:         "package defaults",
+			wantWarning:  DefaultWarningUtilization,
+			wantCritical: DefaultCriticalUtilization,
+		},
+		{
+			name:         "manager defaults",
+			defaults:     PoolDefaults{WarningUtilization: 70, CriticalUtilization: 85},
+			wantWarning:  70,
+			wantCritical: 85,
+		},
+		{
+			name:         "pool overrides manager defaults",
+			thresholds:   &v1beta2.UtilizationThresholds{Warning: int32Ptr(50), Critical: int32Ptr(75)},
+			defaults:     PoolDefaults{WarningUtilization: 70, CriticalUtilization: 85},
+			wantWarning:  50,
+			wantCritical: 75,
+		},
+		{
+			name:         "partial override",
+			thresholds:   &v1beta2.UtilizationThresholds{Critical: int32Ptr(95)},
+			wantWarning:  DefaultWarningUtilization,
+			wantCritical: 95,
+		},
+		{
+			name:         "critical raised to warning",
+			thresholds:   &v1beta2.UtilizationThresholds{Warning: int32Ptr(95)},
+			wantWarning:  95,
+			wantCritical: 95,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1beta2.UnifiIPPoolSpec{UtilizationThresholds: tt.thresholds}
+			warning, critical := UtilizationThresholds(spec, tt.defaults)
+			if warning != tt.wantWarning || critical != tt.wantCritical {
+				t.Errorf("UtilizationThresholds() = %d, %d, want %d, %d", warning, critical, tt.wantWarning, tt.wantCritical)
+			}
+		})
+	}
+}
+
+func TestSyncInterval(t *testing.T) {
+	tests := []struct {
+		name         string
+		syncInterval *metav1.Duration
+		defaults     PoolDefaults
+		want         time.Duration
+	}{
+		{
+			name: "package default",
+			want: DefaultSyncInterval,
+		},
+		{
+			name:     "manager default",
+			defaults: PoolDefaults{SyncInterval: 30 * time.Minute},
+			want:     30 * time.Minute,
+		},
+		{
+			name:         "pool overrides manager default",
+			syncInterval: &metav1.Duration{Duration: time.Hour},
+			defaults:     PoolDefaults{SyncInterval: 30 * time.Minute},
+			want:         time.Hour,
+		},
+		{
+			name:         "interval below minimum",
+			syncInterval: &metav1.Duration{Duration: time.Second},
+			want:         MinSyncInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1beta2.UnifiIPPoolSpec{SyncInterval: tt.syncInterval}
+			if got := SyncInterval(spec, tt.defaults); got != tt.want {
+				t.Errorf("SyncInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextSyncAfter(t *testing.T) {
+	spec := &v1beta2.UnifiIPPoolSpec{SyncInterval: &metav1.Duration{Duration: 10 * time.Minute}}
+
+	if got := NextSyncAfter(spec, PoolDefaults{}); got != 10*time.Minute {
+		t.Errorf("NextSyncAfter() without jitter = %v, want %v", got, 10*time.Minute)
+	}
+
+	for range 100 {
+		got := NextSyncAfter(spec, PoolDefaults{SyncJitter: 0.2})
+		if got < 10*time.Minute || got > 12*time.Minute {
+			t.Fatalf("NextSyncAfter() = %v, want within [10m, 12m]", got)
+		}
+	}
+}
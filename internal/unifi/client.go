@@ -19,34 +19,47 @@ package unifi
 import (
 	"context"
 	"crypto/sha256"
-	"crypto/tls"
 	"errors"
 	"fmt"
-	"net/http"
 	"net/netip"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/ubiquiti-community/go-unifi/unifi"
+	"go4.org/netipx"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/metrics"
 	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/tracing"
 
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
 )
 
 // Config holds the configuration for connecting to a Unifi controller.
 type Config struct {
-	Host       string
-	APIKey     string
-	Site       string
-	Insecure   bool
-	HTTPClient *http.Client
+	Host     string
+	APIKey   string
+	Site     string
+	Insecure bool
+
+	// Instance identifies the UnifiInstance the client talks to in metrics.
+	Instance string
+
+	// DryRun suppresses all writes to the controller. They are logged and recorded
+	// in DefaultDryRunLog instead.
+	DryRun bool
 }
 
 // Client wraps the Unifi API client with IPAM-specific operations.
 type Client struct {
-	client *unifi.Client
-	site   string
+	client   *unifi.ApiClient
+	site     string
+	instance string
+	dryRun   bool
 }
 
 // IPAllocation represents an allocated IP address.
@@ -57,6 +70,57 @@ type IPAllocation struct {
 	UseFixedIP bool
 	Prefix     int32
 	Gateway    string
+	// Source tells how the address was chosen.
+	Source AllocationSource
+	// Created is set when the Unifi fixed IP assignment was created by this call.
+	Created bool
+}
+
+// AllocationSource tells how an allocated address was chosen.
+type AllocationSource string
+
+const (
+	// AllocationSourceExisting is an address the consumer's MAC already had in Unifi.
+	AllocationSourceExisting AllocationSource = "Existing"
+	// AllocationSourcePreAllocation is an address from the pool's PreAllocations.
+	AllocationSourcePreAllocation AllocationSource = "PreAllocation"
+	// AllocationSourceRequested is the address requested by the consumer.
+	AllocationSourceRequested AllocationSource = "Requested"
+	// AllocationSourceStickyLease is the address leased to the consumer's sticky key.
+	AllocationSourceStickyLease AllocationSource = "StickyLease"
+	// AllocationSourceDynamic is an address picked by the pool's allocation strategy.
+	AllocationSourceDynamic AllocationSource = "Dynamic"
+	// AllocationSourceAdopted is a fixed IP that was already reserved in Unifi for another MAC.
+	AllocationSourceAdopted AllocationSource = "Adopted"
+)
+
+// AllocationRequest describes a consumer asking for an address from a pool.
+type AllocationRequest struct {
+	// Name identifies the consumer and is the key looked up in the pool's PreAllocations.
+	Name string
+	// Namespace of the consumer. Consumers of global pools with the same name in
+	// different namespaces are different consumers.
+	Namespace string
+	// RequestedIP is a specific address asked for by the consumer, if any.
+	RequestedIP string
+	// MACAddress is the MAC the Unifi fixed IP assignment is bound to.
+	MACAddress string
+	// Hostname is registered with the Unifi fixed IP assignment.
+	Hostname string
+	// ClusterName is the CAPI cluster of the consumer, used to spread a cluster across subnets.
+	ClusterName string
+	// StickyKey matches the consumer to the address leased by the consumer it replaces.
+	StickyKey string
+	// UseHeadroom allows the consumer to allocate the addresses the pool reserves as headroom.
+	UseHeadroom bool
+	// Adoption selects an existing Unifi fixed IP the consumer adopts, if any.
+	Adoption *poolutil.AdoptionRequest
+	// Reserved maps the addresses held by UnifiIPReservations of the pool, other
+	// than the consumer, to the namespace/name of their reservation.
+	Reserved map[string]string
+	// LegacyMACAddress is a MAC the consumer's fixed IP may still be bound to from
+	// before MACAddress was derived as it is now. A fixed IP under it is kept.
+	LegacyMACAddress string
 }
 
 // NewClient creates a new Unifi client.
@@ -65,60 +129,115 @@ func NewClient(cfg Config) (*Client, error) {
 		cfg.Site = "default"
 	}
 
-	httpClient := cfg.HTTPClient
-	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: cfg.Insecure, //nolint:gosec // G402: User-configurable for development/testing environments
-				},
-			},
-		}
+	// Connect to the controller (with API key, no user/pass needed).
+	start := time.Now()
+	client, err := unifi.New(context.Background(), &unifi.Config{
+		BaseURL:       cfg.Host,
+		APIKey:        cfg.APIKey,
+		AllowInsecure: cfg.Insecure,
+	})
+	metrics.ObserveUnifiRequest("Login", cfg.Instance, start, err != nil)
+	DefaultHealthTracker.Record(cfg.Instance, unreachableError(err), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Unifi controller: %w", &BackendError{Err: err})
 	}
 
-	// Create the client.
-	client := &unifi.Client{}
+	return &Client{
+		client:   client,
+		site:     cfg.Site,
+		instance: cfg.Instance,
+		dryRun:   cfg.DryRun,
+	}, nil
+}
 
-	// Set API key (required for authentication).
-	client.SetAPIKey(cfg.APIKey)
+// DryRun reports whether the client suppresses writes to the controller.
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
 
-	// Configure HTTP client.
-	if err := client.SetHTTPClient(httpClient); err != nil {
-		return nil, fmt.Errorf("failed to set HTTP client: %w", err)
+// plan logs and records a write to the user that is suppressed in dry-run mode.
+func (c *Client) plan(ctx context.Context, operation string, user *unifi.Client) {
+	log.FromContext(ctx).Info("dry run: skipping Unifi write", "instance", c.instance,
+		"operation", operation, "mac", user.MAC, "ip", user.FixedIP, "network", user.NetworkID)
+	DefaultDryRunLog.Record(c.instance, PlannedAction{
+		Time:      time.Now(),
+		Operation: operation,
+		MAC:       user.MAC,
+		IP:        user.FixedIP,
+		NetworkID: user.NetworkID,
+		Hostname:  user.Hostname,
+	})
+}
+
+// createUser creates the user, or only plans it in dry-run mode.
+func (c *Client) createUser(ctx context.Context, user *unifi.Client) (*unifi.Client, error) {
+	if c.dryRun {
+		c.plan(ctx, "CreateUser", user)
+		return user, nil
 	}
+	start := time.Now()
+	created, err := c.client.CreateClient(ctx, c.site, user)
+	c.observe(ctx, "CreateUser", start, err)
+	return created, err
+}
 
-	// Set base URL.
-	if err := client.SetBaseURL(cfg.Host); err != nil {
-		return nil, fmt.Errorf("failed to set base URL: %w", err)
+// updateUser updates the user, or only plans it in dry-run mode.
+func (c *Client) updateUser(ctx context.Context, user *unifi.Client) error {
+	if c.dryRun {
+		c.plan(ctx, "UpdateUser", user)
+		return nil
 	}
+	start := time.Now()
+	_, err := c.client.UpdateClient(ctx, c.site, user)
+	c.observe(ctx, "UpdateUser", start, err)
+	return err
+}
 
-	// Login to the controller (with API key, no user/pass needed).
-	if err := client.Login(context.Background(), "", ""); err != nil {
-		return nil, fmt.Errorf("failed to login to Unifi controller: %w", err)
+// deleteUserByMAC deletes the user with the MAC, or only plans it in dry-run mode.
+func (c *Client) deleteUserByMAC(ctx context.Context, macAddress string) error {
+	if c.dryRun {
+		c.plan(ctx, "DeleteUserByMAC", &unifi.Client{MAC: macAddress})
+		return nil
 	}
+	start := time.Now()
+	err := c.client.DeleteClientByMAC(ctx, c.site, macAddress)
+	c.observe(ctx, "DeleteUserByMAC", start, err)
+	return err
+}
 
-	return &Client{
-		client: client,
-		site:   cfg.Site,
-	}, nil
+// observe records the latency and outcome of a Unifi API call in metrics, as a span
+// and in the instance's health. Lookups of objects that do not exist are expected and not counted as errors.
+func (c *Client) observe(ctx context.Context, operation string, start time.Time, err error) {
+	notFoundError := &unifi.NotFoundError{}
+	if errors.As(err, &notFoundError) {
+		err = nil
+	}
+	metrics.ObserveUnifiRequest(operation, c.instance, start, err != nil)
+	DefaultHealthTracker.Record(c.instance, unreachableError(err), time.Now())
+	tracing.RecordCall(ctx, "unifi."+operation, start, err,
+		tracing.UnifiOperationKey.String(operation),
+		tracing.UnifiInstanceKey.String(c.instance))
 }
 
 // ValidateCredentials tests the connection and credentials.
 func (c *Client) ValidateCredentials(ctx context.Context) error {
 	// Try to list networks as a validation check.
+	start := time.Now()
 	_, err := c.client.ListNetwork(ctx, c.site)
+	c.observe(ctx, "ListNetwork", start, err)
 	if err != nil {
-		return fmt.Errorf("failed to validate credentials: %w", err)
+		return fmt.Errorf("failed to validate credentials: %w", &BackendError{Err: err})
 	}
 	return nil
 }
 
 // GetNetwork retrieves network information by ID.
 func (c *Client) GetNetwork(ctx context.Context, networkID string) (*unifi.Network, error) {
+	start := time.Now()
 	networks, err := c.client.ListNetwork(ctx, c.site)
+	c.observe(ctx, "ListNetwork", start, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list networks: %w", err)
+		return nil, fmt.Errorf("failed to list networks: %w", &BackendError{Err: err})
 	}
 
 	for i := range networks {
@@ -127,7 +246,7 @@ func (c *Client) GetNetwork(ctx context.Context, networkID string) (*unifi.Netwo
 		}
 	}
 
-	return nil, fmt.Errorf("network %s not found", networkID)
+	return nil, fmt.Errorf("%w: %s", ErrNetworkNotFound, networkID)
 }
 
 // SyncNetworkToCIDR retrieves network configuration from Unifi and populates SubnetSpec.
@@ -139,22 +258,24 @@ func (c *Client) SyncNetworkToCIDR(ctx context.Context, networkID string) (*v1be
 	if err != nil {
 		return nil, err
 	}
+	ipSubnet := deref(network.IPSubnet)
+	dhcpStart, dhcpStop := deref(network.DHCPDStart), deref(network.DHCPDStop)
 
 	// Validate that the network has required DHCP/IP configuration
-	if network.IPSubnet == "" {
+	if ipSubnet == "" {
 		return nil, fmt.Errorf("network %s has no IP subnet configured", networkID)
 	}
 
 	subnetSpec := &v1beta2.SubnetSpec{
-		CIDR: network.IPSubnet,
+		CIDR: ipSubnet,
 	}
 
 	// Extract gateway - prefer DHCPDGateway if set, otherwise calculate from CIDR
-	if network.DHCPDGateway != "" && network.DHCPDGatewayEnabled {
-		subnetSpec.Gateway = network.DHCPDGateway
+	if gateway := deref(network.DHCPDGateway); gateway != "" && network.DHCPDGatewayEnabled {
+		subnetSpec.Gateway = gateway
 	} else {
 		// Calculate gateway from CIDR (typically .1 of the subnet)
-		gateway, err := calculateGatewayFromCIDR(network.IPSubnet)
+		gateway, err := calculateGatewayFromCIDR(ipSubnet)
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate gateway: %w", err)
 		}
@@ -162,7 +283,7 @@ func (c *Client) SyncNetworkToCIDR(ctx context.Context, networkID string) (*v1be
 	}
 
 	// Calculate prefix from CIDR
-	prefix, err := extractPrefixFromCIDR(network.IPSubnet)
+	prefix, err := extractPrefixFromCIDR(ipSubnet)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract prefix: %w", err)
 	}
@@ -172,9 +293,9 @@ func (c *Client) SyncNetworkToCIDR(ctx context.Context, networkID string) (*v1be
 	excludeRanges := make([]string, 0)
 
 	// If DHCP is enabled, exclude IPs outside the DHCP range
-	if network.DHCPDEnabled && network.DHCPDStart != "" && network.DHCPDStop != "" {
+	if network.DHCPDEnabled && dhcpStart != "" && dhcpStop != "" {
 		// Calculate exclude ranges for IPs before DHCP start and after DHCP stop
-		beforeRange, afterRange, err := calculateExcludeRangesFromDHCP(network.IPSubnet, network.DHCPDStart, network.DHCPDStop)
+		beforeRange, afterRange, err := calculateExcludeRangesFromDHCP(ipSubnet, dhcpStart, dhcpStop)
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate exclude ranges: %w", err)
 		}
@@ -203,24 +324,118 @@ func (c *Client) SyncNetworkToCIDR(ctx context.Context, networkID string) (*v1be
 	return subnetSpec, nil
 }
 
+// ResolveUserGroup looks up a Unifi user group by ID or name.
+func (c *Client) ResolveUserGroup(ctx context.Context, nameOrID string) (*unifi.ClientGroup, error) {
+	start := time.Now()
+	groups, err := c.client.ListClientGroup(ctx, c.site)
+	c.observe(ctx, "ListUserGroup", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user groups: %w", &BackendError{Err: err})
+	}
+
+	return findUserGroup(groups, nameOrID)
+}
+
+// findUserGroup returns the user group matching nameOrID.
+// IDs take precedence over names; a name shared by several groups is rejected.
+func findUserGroup(groups []unifi.ClientGroup, nameOrID string) (*unifi.ClientGroup, error) {
+	if nameOrID == "" {
+		return nil, fmt.Errorf("user group name or ID is empty")
+	}
+
+	for i := range groups {
+		if groups[i].ID == nameOrID {
+			return &groups[i], nil
+		}
+	}
+
+	var match *unifi.ClientGroup
+	for i := range groups {
+		if groups[i].Name != nameOrID {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("user group name %q is ambiguous, use the group ID instead", nameOrID)
+		}
+		match = &groups[i]
+	}
+	if match == nil {
+		return nil, fmt.Errorf("user group %q not found", nameOrID)
+	}
+
+	return match, nil
+}
+
+// poolUserGroupID returns the resolved user group ID for the pool.
+// Returns an error if the pool names a user group that has not been resolved yet.
+func poolUserGroupID(pool v1beta2.GenericUnifiIPPool) (string, error) {
+	spec, status := pool.PoolSpec(), pool.PoolStatus()
+	if spec.UserGroup == "" {
+		return "", nil
+	}
+	if status.UserGroupID == "" {
+		return "", fmt.Errorf("%w: user group %q has not been resolved by the pool controller yet", ErrPoolNotReady, spec.UserGroup)
+	}
+	return status.UserGroupID, nil
+}
+
 // GetOrAllocateIP gets an existing IP or allocates a new one.
-func (c *Client) GetOrAllocateIP(ctx context.Context, pool *v1beta2.UnifiIPPool, claim *ipamv1beta2.IPAddressClaim, networkID, macAddress, hostname string, addressesInUse []ipamv1beta2.IPAddress) (*IPAllocation, error) {
+func (c *Client) GetOrAllocateIP(ctx context.Context, pool v1beta2.GenericUnifiIPPool, req AllocationRequest, networkID string, addressesInUse []ipamv1beta2.IPAddress) (*IPAllocation, error) {
+	spec := pool.PoolSpec()
+	userGroupID, err := poolUserGroupID(pool)
+	if err != nil {
+		return nil, err
+	}
+
 	// First, check if this MAC already has a fixed IP assignment via User object.
-	existingUser, err := c.client.GetUserByMAC(ctx, c.site, macAddress)
+	start := time.Now()
+	existingUser, err := c.client.GetClientByMAC(ctx, c.site, req.MACAddress)
+	c.observe(ctx, "GetUserByMAC", start, err)
+	notFoundError := &unifi.NotFoundError{}
+	if errors.As(err, &notFoundError) && req.LegacyMACAddress != "" {
+		// Keep a fixed IP allocated under the consumer's legacy MAC.
+		start = time.Now()
+		legacyUser, legacyErr := c.client.GetClientByMAC(ctx, c.site, req.LegacyMACAddress)
+		c.observe(ctx, "GetUserByMAC", start, legacyErr)
+		if legacyErr != nil && !errors.As(legacyErr, &notFoundError) {
+			return nil, fmt.Errorf("failed to check legacy user: %w", &BackendError{Err: legacyErr})
+		}
+		if legacyErr == nil && legacyUser != nil && legacyUser.UseFixedIP && legacyUser.FixedIP != "" {
+			existingUser, err = legacyUser, nil
+		}
+	}
+	if err == nil && existingUser != nil && (!existingUser.UseFixedIP || existingUser.FixedIP == "") {
+		// The client is known to Unifi, e.g. a real device, but has no fixed IP yet.
+		return c.assignFixedIP(ctx, pool, req, existingUser, networkID, userGroupID, addressesInUse)
+	}
 	if err == nil && existingUser != nil {
+		// The MAC's fixed IP must not be handed out twice.
+		if holder := heldByOther(addressesInUse, existingUser.FixedIP, req); holder != "" {
+			return nil, fmt.Errorf("%w: fixed IP %s of MAC %s is already assigned to %s",
+				ErrRequestedIPConflict, existingUser.FixedIP, existingUser.MAC, holder)
+		}
+
+		// Keep the user group of existing reservations in line with the pool.
+		if userGroupID != "" && existingUser.UserGroupID != userGroupID {
+			existingUser.UserGroupID = userGroupID
+			if err := c.updateUser(ctx, existingUser); err != nil {
+				return nil, fmt.Errorf("failed to update user group of existing user: %w", &BackendError{Err: err})
+			}
+		}
+
 		// User exists - return existing allocation with Prefix and Gateway.
 		// Need to determine prefix and gateway from pool config.
 		defaultPrefix := int32(24)
-		if pool.Spec.Prefix != nil && *pool.Spec.Prefix > 0 {
-			defaultPrefix = *pool.Spec.Prefix
+		if spec.Prefix != nil && *spec.Prefix > 0 {
+			defaultPrefix = *spec.Prefix
 		}
 
 		// Find subnet containing the existing IP to get accurate prefix/gateway
 		prefix := defaultPrefix
-		gateway := pool.Spec.Gateway
+		gateway := spec.Gateway
 		addr, err := netip.ParseAddr(existingUser.FixedIP)
 		if err == nil {
-			for _, subnet := range pool.Spec.Subnets {
+			for _, subnet := range spec.Subnets {
 				// Check if IP is in this subnet
 				var contains bool
 				if subnet.CIDR != "" {
@@ -236,7 +451,7 @@ func (c *Client) GetOrAllocateIP(ctx context.Context, pool *v1beta2.UnifiIPPool,
 				}
 				if contains {
 					prefix = poolutil.GetPrefix(subnet, defaultPrefix)
-					gateway = poolutil.GetGateway(subnet, pool.Spec.Gateway)
+					gateway = poolutil.GetGateway(subnet, spec.Gateway)
 					break
 				}
 			}
@@ -249,15 +464,23 @@ func (c *Client) GetOrAllocateIP(ctx context.Context, pool *v1beta2.UnifiIPPool,
 			UseFixedIP: existingUser.UseFixedIP,
 			Prefix:     prefix,
 			Gateway:    gateway,
+			Source:     AllocationSourceExisting,
 		}, nil
 	}
 
 	// If not found or error (other than NotFoundError), need to allocate new IP.
 	if err != nil {
 		// Check if it's a NotFoundError - that's expected, other errors should be returned.
-		notFoundError := &unifi.NotFoundError{}
-		if errors.As(err, &notFoundError) {
-			return nil, fmt.Errorf("failed to check existing user: %w", err)
+		if !errors.As(err, &notFoundError) {
+			return nil, fmt.Errorf("failed to check existing user: %w", &BackendError{Err: err})
+		}
+	}
+
+	// Adopt a fixed IP reserved in Unifi before CAPI managed the consumer.
+	if req.Adoption != nil {
+		adopted, err := c.adoptFixedIP(ctx, pool, req, networkID, addressesInUse)
+		if err != nil || adopted != nil {
+			return adopted, err
 		}
 	}
 
@@ -268,24 +491,25 @@ func (c *Client) GetOrAllocateIP(ctx context.Context, pool *v1beta2.UnifiIPPool,
 	}
 
 	// Allocate the next available IP using 3-level priority algorithm.
-	allocatedIP, prefix, gateway, err := c.allocateNextIP(ctx, pool, claim, network, addressesInUse)
+	allocatedIP, prefix, gateway, source, err := c.allocateNextIP(ctx, pool, req, network, addressesInUse)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate IP: %w", err)
 	}
 
 	// Create a User object with fixed IP assignment.
-	newUser := &unifi.User{
-		MAC:        macAddress,
-		FixedIP:    allocatedIP,
-		Hostname:   hostname,
-		UseFixedIP: true,
-		NetworkID:  networkID,
+	newUser := &unifi.Client{
+		MAC:         req.MACAddress,
+		FixedIP:     allocatedIP,
+		Hostname:    req.Hostname,
+		UseFixedIP:  true,
+		NetworkID:   networkID,
+		UserGroupID: userGroupID,
 	}
 
 	// Create the user in Unifi controller.
-	createdUser, err := c.client.CreateUser(ctx, c.site, newUser)
+	createdUser, err := c.createUser(ctx, newUser)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user with fixed IP: %w", err)
+		return nil, fmt.Errorf("failed to create user with fixed IP: %w", &BackendError{Err: err})
 	}
 
 	// Return the allocation with metadata.
@@ -296,212 +520,302 @@ func (c *Client) GetOrAllocateIP(ctx context.Context, pool *v1beta2.UnifiIPPool,
 		UseFixedIP: createdUser.UseFixedIP,
 		Prefix:     prefix,
 		Gateway:    gateway,
+		Source:     source,
+		Created:    true,
+	}, nil
+}
+
+// assignFixedIP allocates an address for a client that exists in Unifi without a
+// fixed IP and sets it on the client, keeping its name and history.
+func (c *Client) assignFixedIP(ctx context.Context, pool v1beta2.GenericUnifiIPPool, req AllocationRequest, user *unifi.Client, networkID, userGroupID string, addressesInUse []ipamv1beta2.IPAddress) (*IPAllocation, error) {
+	network, err := c.GetNetwork(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	allocatedIP, prefix, gateway, source, err := c.allocateNextIP(ctx, pool, req, network, addressesInUse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate IP: %w", err)
+	}
+
+	user.FixedIP = allocatedIP
+	user.UseFixedIP = true
+	user.NetworkID = networkID
+	if userGroupID != "" {
+		user.UserGroupID = userGroupID
+	}
+	if err := c.updateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to set fixed IP of existing user: %w", &BackendError{Err: err})
+	}
+
+	return &IPAllocation{
+		IPAddress:  allocatedIP,
+		MacAddress: user.MAC,
+		Hostname:   user.Hostname,
+		UseFixedIP: true,
+		Prefix:     prefix,
+		Gateway:    gateway,
+		Source:     source,
+		Created:    true,
+	}, nil
+}
+
+// adoptFixedIP returns the Unifi fixed IP on the network that matches the request's
+// adoption, nil if none matches. The fixed IP stays bound to the MAC of its client.
+func (c *Client) adoptFixedIP(ctx context.Context, pool v1beta2.GenericUnifiIPPool, req AllocationRequest, networkID string, addressesInUse []ipamv1beta2.IPAddress) (*IPAllocation, error) {
+	staticAssignments, err := c.GetStaticAssignments(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Unifi static assignments: %w", err)
+	}
+
+	candidates := make([]poolutil.AdoptionCandidate, 0, len(staticAssignments))
+	for _, sa := range staticAssignments {
+		candidates = append(candidates, poolutil.AdoptionCandidate{IP: sa.IP, MAC: sa.MAC, Hostname: sa.Hostname, Name: sa.Name})
+	}
+	candidate, ok, err := poolutil.FindAdoption(candidates, *req.Adoption)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRequestedIPConflict, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	inUse := make([]string, 0, len(addressesInUse)+len(req.Reserved))
+	for _, addr := range addressesInUse {
+		if !heldBy(addr, req) {
+			inUse = append(inUse, addr.Spec.Address)
+		}
+	}
+	for ip := range req.Reserved {
+		inUse = append(inUse, ip)
+	}
+	allocator, err := poolutil.NewAllocator(pool.PoolSpec(), inUse)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := netip.ParseAddr(candidate.IP)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid Unifi fixed IP %s of %s: %w", ErrRequestedIPConflict, candidate.IP, candidate.MAC, err)
+	}
+	if !allocator.Contains(addr) {
+		return nil, fmt.Errorf("%w: Unifi fixed IP %s of %s is not in configured subnets or is excluded", ErrRequestedIPConflict, candidate.IP, candidate.MAC)
+	}
+	if !allocator.IsFree(addr) {
+		return nil, fmt.Errorf("%w: Unifi fixed IP %s of %s is already assigned", ErrRequestedIPConflict, candidate.IP, candidate.MAC)
+	}
+
+	prefix, gateway := allocator.Metadata(addr)
+	return &IPAllocation{
+		IPAddress:  candidate.IP,
+		MacAddress: candidate.MAC,
+		Hostname:   candidate.Hostname,
+		UseFixedIP: true,
+		Prefix:     prefix,
+		Gateway:    gateway,
+		Source:     AllocationSourceAdopted,
 	}, nil
 }
 
-// allocateNextIP finds the next available IP using 3-level priority algorithm:
+// allocateNextIP finds the next available IP using 4-level priority algorithm:
 // 1. PreAllocations (static assignment or IP reuse)
-// 2. Annotation request (claim specifies desired IP)
-// 3. Dynamic allocation (iterate through subnets)
-func (c *Client) allocateNextIP(ctx context.Context, pool *v1beta2.UnifiIPPool, claim *ipamv1beta2.IPAddressClaim, network *unifi.Network, addressesInUse []ipamv1beta2.IPAddress) (string, int32, string, error) {
+// 2. Requested IP (claim annotation or reservation address)
+// 3. Sticky lease (address released by a consumer with the same sticky key)
+// 4. Dynamic allocation (free address picked by the pool's allocation strategy)
+// Candidates come from the pool's IPSet, which leaves out exclude ranges, gateways,
+// addresses in use and Unifi fixed IPs. Dynamic allocation also skips quarantined
+// and leased addresses.
+func (c *Client) allocateNextIP(ctx context.Context, pool v1beta2.GenericUnifiIPPool, req AllocationRequest, network *unifi.Network, addressesInUse []ipamv1beta2.IPAddress) (string, int32, string, AllocationSource, error) {
 	if pool == nil {
-		return "", 0, "", fmt.Errorf("pool is nil")
+		return "", 0, "", "", fmt.Errorf("pool is nil")
 	}
-	if len(pool.Spec.Subnets) == 0 {
-		return "", 0, "", fmt.Errorf("pool has no configured subnets")
+	spec := pool.PoolSpec()
+	if len(spec.Subnets) == 0 {
+		return "", 0, "", "", fmt.Errorf("pool has no configured subnets")
 	}
 
-	// Get default prefix for validation
-	defaultPrefix := int32(24) // fallback
-	if pool.Spec.Prefix != nil && *pool.Spec.Prefix > 0 {
-		defaultPrefix = *pool.Spec.Prefix
+	// Get Unifi static assignments, they are reserved regardless of the pool.
+	staticAssignments, err := c.GetStaticAssignments(ctx, network.ID)
+	if err != nil {
+		return "", 0, "", "", fmt.Errorf("failed to get Unifi static assignments: %w", err)
 	}
 
-	// PRIORITY 1: Check PreAllocations map
-	if pool.Spec.PreAllocations != nil && claim != nil {
-		if prealloc, exists := pool.Spec.PreAllocations[claim.Name]; exists {
-			// Validate preallocated IP is in configured subnets
-			if !poolutil.IPInSubnets(prealloc, pool.Spec.Subnets, defaultPrefix) {
-				return "", 0, "", fmt.Errorf("preallocated IP %s for claim %s is not in configured subnets", prealloc, claim.Name)
-			}
+	inUse := make([]string, 0, len(addressesInUse)+len(req.Reserved)+len(staticAssignments))
+	for _, addr := range addressesInUse {
+		inUse = append(inUse, addr.Spec.Address)
+	}
+	for ip := range req.Reserved {
+		inUse = append(inUse, ip)
+	}
+	for _, sa := range staticAssignments {
+		inUse = append(inUse, sa.IP)
+	}
 
-			// Check if preallocated IP is already assigned to a different claim
-			for _, addr := range addressesInUse {
-				if addr.Spec.Address == prealloc {
-					// Check if it's assigned to the same claim (reuse scenario)
-					if addr.Spec.ClaimRef.Name == claim.Name {
-						// Same claim - this is IP reuse, allow it
-						continue
-					}
-					return "", 0, "", fmt.Errorf("preallocated IP %s is already assigned to claim %s", prealloc, addr.Spec.ClaimRef.Name)
-				}
-			}
+	allocator, err := poolutil.NewAllocator(spec, inUse)
+	if err != nil {
+		return "", 0, "", "", err
+	}
 
-			// Check Unifi for conflicts
-			staticAssignments, err := c.GetStaticAssignments(ctx, network.ID)
-			if err != nil {
-				return "", 0, "", fmt.Errorf("failed to check Unifi static assignments: %w", err)
-			}
-			for _, sa := range staticAssignments {
-				if sa.IP == prealloc {
-					// Check if it's the same MAC (reuse scenario)
-					macAddress := generateMACForClaim(claim.Name)
-					if sa.MAC == macAddress {
-						// Same MAC - this is IP reuse from previous allocation
-						continue
-					}
-					return "", 0, "", fmt.Errorf("preallocated IP %s has Unifi conflict with MAC %s", prealloc, sa.MAC)
-				}
-			}
+	// Recently released and leased addresses are only handed out to their
+	// preallocation or sticky key.
+	now := time.Now()
+	quarantined := poolutil.QuarantinedAddresses(spec, pool.PoolStatus(), now)
+	leased := poolutil.LeasedAddresses(pool.PoolStatus(), now)
+	reserved := slices.Concat(inUse, quarantined, leased)
 
-			// Find which subnet contains this IP to get metadata
-			for _, subnet := range pool.Spec.Subnets {
-				prefix := poolutil.GetPrefix(subnet, defaultPrefix)
-				gateway := poolutil.GetGateway(subnet, pool.Spec.Gateway)
+	dynamic, err := poolutil.NewAllocator(spec, reserved)
+	if err != nil {
+		return "", 0, "", "", err
+	}
 
-				addr, err := netip.ParseAddr(prealloc)
-				if err != nil {
-					continue
-				}
+	// PRIORITY 1: Check PreAllocations map
+	if prealloc, exists := poolutil.PreAllocation(spec, req.Namespace, req.Name); exists {
+		addr, err := netip.ParseAddr(prealloc)
+		if err != nil {
+			return "", 0, "", "", fmt.Errorf("%w: invalid preallocated IP %s for %s: %w", ErrRequestedIPConflict, prealloc, req.Name, err)
+		}
 
-				// Check if IP is in this subnet
-				var contains bool
-				if subnet.CIDR != "" {
-					if subnetPrefix, err := netip.ParsePrefix(subnet.CIDR); err == nil {
-						contains = subnetPrefix.Contains(addr)
-					}
-				} else if subnet.Start != "" && subnet.End != "" {
-					if startIP, err := netip.ParseAddr(subnet.Start); err == nil {
-						if endIP, err := netip.ParseAddr(subnet.End); err == nil {
-							contains = addr.Compare(startIP) >= 0 && addr.Compare(endIP) <= 0
-						}
-					}
-				}
-				if contains {
-					return prealloc, prefix, gateway, nil
-				}
-			}
+		// Validate preallocated IP is allocatable from the pool
+		if !allocator.Contains(addr) {
+			return "", 0, "", "", fmt.Errorf("%w: preallocated IP %s for %s is not in configured subnets or is excluded", ErrRequestedIPConflict, prealloc, req.Name)
+		}
 
-			// If we reach here, IP is valid but couldn't determine subnet metadata
-			return prealloc, defaultPrefix, pool.Spec.Gateway, nil
+		// Check if preallocated IP is already assigned to a different claim
+		if holder := heldByOther(addressesInUse, prealloc, req); holder != "" {
+			return "", 0, "", "", fmt.Errorf("%w: preallocated IP %s is already assigned to %s", ErrRequestedIPConflict, prealloc, holder)
 		}
-	}
 
-	// PRIORITY 2: Check annotation for requested IP
-	if claim != nil && claim.Annotations != nil {
-		if requestedIP, exists := claim.Annotations["ipAddress"]; exists && requestedIP != "" {
-			// Validate requested IP (similar to preallocated IP validation)
-			if !poolutil.IPInSubnets(requestedIP, pool.Spec.Subnets, defaultPrefix) {
-				return "", 0, "", fmt.Errorf("requested IP %s is not in configured subnets", requestedIP)
+		// Check Unifi for conflicts, the same MAC is IP reuse from a previous allocation
+		for _, sa := range staticAssignments {
+			if sa.IP == prealloc && sa.MAC != req.MACAddress {
+				return "", 0, "", "", fmt.Errorf("%w: preallocated IP %s has Unifi conflict with MAC %s", ErrRequestedIPConflict, prealloc, sa.MAC)
 			}
+		}
 
-			// Check if already assigned
-			for _, addr := range addressesInUse {
-				if addr.Spec.Address == requestedIP {
-					return "", 0, "", fmt.Errorf("requested IP %s is already assigned", requestedIP)
-				}
-			}
+		prefix, gateway := allocator.Metadata(addr)
+		return prealloc, prefix, gateway, AllocationSourcePreAllocation, nil
+	}
 
-			// Check Unifi for conflicts
-			staticAssignments, err := c.GetStaticAssignments(ctx, network.ID)
-			if err != nil {
-				return "", 0, "", fmt.Errorf("failed to check Unifi static assignments: %w", err)
-			}
-			for _, sa := range staticAssignments {
-				if sa.IP == requestedIP {
-					return "", 0, "", fmt.Errorf("requested IP %s has Unifi conflict", requestedIP)
-				}
-			}
+	// PRIORITY 2: Check for a requested IP
+	if requestedIP := req.RequestedIP; requestedIP != "" {
+		addr, err := netip.ParseAddr(requestedIP)
+		if err != nil {
+			return "", 0, "", "", fmt.Errorf("%w: invalid requested IP %s: %w", ErrRequestedIPConflict, requestedIP, err)
+		}
 
-			// Find subnet metadata
-			for _, subnet := range pool.Spec.Subnets {
-				prefix := poolutil.GetPrefix(subnet, defaultPrefix)
-				gateway := poolutil.GetGateway(subnet, pool.Spec.Gateway)
+		if !allocator.Contains(addr) {
+			return "", 0, "", "", fmt.Errorf("%w: requested IP %s is not in configured subnets or is excluded", ErrRequestedIPConflict, requestedIP)
+		}
 
-				addr, err := netip.ParseAddr(requestedIP)
-				if err != nil {
-					continue
-				}
+		if slices.Contains(quarantined, requestedIP) {
+			return "", 0, "", "", fmt.Errorf("%w: requested IP %s was recently released and is quarantined", ErrRequestedIPConflict, requestedIP)
+		}
 
-				// Check if IP is in this subnet
-				var contains bool
-				if subnet.CIDR != "" {
-					if subnetPrefix, err := netip.ParsePrefix(subnet.CIDR); err == nil {
-						contains = subnetPrefix.Contains(addr)
-					}
-				} else if subnet.Start != "" && subnet.End != "" {
-					if startIP, err := netip.ParseAddr(subnet.Start); err == nil {
-						if endIP, err := netip.ParseAddr(subnet.End); err == nil {
-							contains = addr.Compare(startIP) >= 0 && addr.Compare(endIP) <= 0
-						}
-					}
-				}
-				if contains {
-					return requestedIP, prefix, gateway, nil
-				}
+		if !allocator.IsFree(addr) {
+			return "", 0, "", "", fmt.Errorf("%w: requested IP %s is already assigned", ErrRequestedIPConflict, requestedIP)
+		}
+
+		if slices.Contains(leased, requestedIP) {
+			leasedIP, err := poolutil.StickyLeaseAddress(pool.PoolStatus(), addressesInUse, req.StickyKey, now)
+			if err != nil {
+				return "", 0, "", "", err
 			}
+			if leasedIP != requestedIP {
+				return "", 0, "", "", fmt.Errorf("%w: requested IP %s is leased to another sticky key", ErrRequestedIPConflict, requestedIP)
+			}
+		}
 
-			// If we reach here, IP is valid but couldn't determine subnet metadata
-			return requestedIP, defaultPrefix, pool.Spec.Gateway, nil
+		if err := poolutil.CheckHeadroom(spec, poolutil.CountAddresses(dynamic.Free()), req.UseHeadroom); err != nil {
+			return "", 0, "", "", fmt.Errorf("requested IP %s: %w", requestedIP, err)
 		}
-	}
 
-	// PRIORITY 3: Dynamic allocation using iteration
-	// Build map of allocated IPs (from both CAPI and Unifi)
-	allocatedIPs := make(map[string]bool)
-	for _, addr := range addressesInUse {
-		allocatedIPs[addr.Spec.Address] = true
+		prefix, gateway := allocator.Metadata(addr)
+		return requestedIP, prefix, gateway, AllocationSourceRequested, nil
 	}
 
-	// Get Unifi static assignments
-	staticAssignments, err := c.GetStaticAssignments(ctx, network.ID)
+	// PRIORITY 3: Reuse the address leased to the sticky key, bypassing quarantine
+	// A lease still held by an address being deleted is waited for.
+	leasedIP, err := poolutil.StickyLeaseAddress(pool.PoolStatus(), addressesInUse, req.StickyKey, now)
 	if err != nil {
-		return "", 0, "", fmt.Errorf("failed to get Unifi static assignments: %w", err)
+		return "", 0, "", "", err
 	}
-	for _, sa := range staticAssignments {
-		allocatedIPs[sa.IP] = true
+	if leasedIP != "" {
+		addr, err := netip.ParseAddr(leasedIP)
+		if err == nil && allocator.Contains(addr) && allocator.IsFree(addr) {
+			prefix, gateway := allocator.Metadata(addr)
+			return leasedIP, prefix, gateway, AllocationSourceStickyLease, nil
+		}
 	}
 
-	// Iterate through all subnets
-	for _, subnet := range pool.Spec.Subnets {
-		prefix := poolutil.GetPrefix(subnet, defaultPrefix)
-		gateway := poolutil.GetGateway(subnet, pool.Spec.Gateway)
-
-		// Iterate through IPs in this subnet
-		index := 0
-		for {
-			ip, err := poolutil.GetIPAddress(subnet, defaultPrefix, index)
-			if err != nil {
-				// Out of range or error - try next subnet
-				break
-			}
-			index++
+	// PRIORITY 4: Dynamic allocation from the free set using the pool's strategy
+	if err := poolutil.CheckHeadroom(spec, poolutil.CountAddresses(dynamic.Free()), req.UseHeadroom); err != nil {
+		return "", 0, "", "", err
+	}
+	addr, err := dynamic.NextSpread(clusterAddresses(addressesInUse, req.ClusterName))
+	if err != nil {
+		return "", 0, "", "", err
+	}
 
-			ipStr := ip.String()
+	prefix, gateway := dynamic.Metadata(addr)
+	return addr.String(), prefix, gateway, AllocationSourceDynamic, nil
+}
 
-			// Skip gateway
-			if ipStr == gateway {
-				continue
-			}
+// clusterAddresses returns the addresses in use by the given cluster.
+func clusterAddresses(addressesInUse []ipamv1beta2.IPAddress, clusterName string) []string {
+	if clusterName == "" {
+		return nil
+	}
 
-			// Skip if already allocated
-			if allocatedIPs[ipStr] {
-				continue
-			}
+	var addresses []string
+	for _, addr := range addressesInUse {
+		if addr.Labels[clusterv1beta2.ClusterNameLabel] == clusterName {
+			addresses = append(addresses, addr.Spec.Address)
+		}
+	}
+	return addresses
+}
 
-			// Found free IP!
-			return ipStr, prefix, gateway, nil
+// heldByOther describes the claim or reservation other than the requester whose
+// address is ip, empty if there is none.
+func heldByOther(addressesInUse []ipamv1beta2.IPAddress, ip string, req AllocationRequest) string {
+	for _, addr := range addressesInUse {
+		if addr.Spec.Address != ip {
+			continue
 		}
+		if heldBy(addr, req) {
+			continue
+		}
+		return "claim " + addr.Namespace + "/" + addr.Spec.ClaimRef.Name
+	}
+	if reservation, ok := req.Reserved[ip]; ok {
+		return "reservation " + reservation
 	}
+	return ""
+}
 
-	return "", 0, "", fmt.Errorf("exhausted IP pool: no free IPs available")
+// heldBy reports whether the address belongs to the requester.
+func heldBy(addr ipamv1beta2.IPAddress, req AllocationRequest) bool {
+	return addr.Spec.ClaimRef.Name == req.Name && (req.Namespace == "" || addr.Namespace == req.Namespace)
 }
 
-// generateMACForClaim generates a deterministic MAC address for a claim name.
+// ClaimMACAddress generates the MAC address of an IPAddressClaim's Unifi fixed IP.
+// The namespace is part of the seed, so claims of a global pool with the same name
+// in different namespaces get different MACs.
+func ClaimMACAddress(namespace, name string) string {
+	return GenerateMACAddress(namespace + "/" + name)
+}
+
+// LegacyClaimMACAddress generates the MAC address claims of namespaced pools were
+// allocated under before the namespace became part of the seed.
+func LegacyClaimMACAddress(name string) string {
+	return GenerateMACAddress(name)
+}
+
+// GenerateMACAddress generates a deterministic MAC address for a consumer name.
 // Uses SHA256 to avoid collisions that would occur with simple length-based hashing.
-func generateMACForClaim(claimName string) string {
+func GenerateMACAddress(name string) string {
 	// Use SHA256 to generate a deterministic hash
-	h := sha256.Sum256([]byte(claimName))
+	h := sha256.Sum256([]byte(name))
 
 	// Use first 5 bytes from hash, with locally administered bit set
 	// 02:xx:xx:xx:xx:xx format ensures it's a locally administered unicast MAC
@@ -512,9 +826,11 @@ func generateMACForClaim(claimName string) string {
 // This helps avoid allocating IPs that are already in use by existing network devices.
 func (c *Client) getExistingClientIPs(ctx context.Context, networkID string) ([]string, error) {
 	// List all active clients on the site (this includes both wired and wireless clients)
-	clients, err := c.client.ListClientsActive(ctx, c.site)
+	start := time.Now()
+	clients, err := c.client.ListClientInfo(ctx, c.site)
+	c.observe(ctx, "ListClientsActive", start, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list active clients: %w", err)
+		return nil, fmt.Errorf("failed to list active clients: %w", &BackendError{Err: err})
 	}
 
 	// Collect IPs from clients - include both current IPs and fixed IP assignments
@@ -544,14 +860,39 @@ func (c *Client) getExistingClientIPs(ctx context.Context, networkID string) ([]
 // ReleaseIP releases an allocated IP address.
 func (c *Client) ReleaseIP(ctx context.Context, networkID, ipAddress, macAddress string) error {
 	// Delete the User object which releases the fixed IP assignment.
-	err := c.client.DeleteUserByMAC(ctx, c.site, macAddress)
+	if err := c.deleteUserByMAC(ctx, macAddress); err != nil {
+		// If the user is not found, that's acceptable - already released.
+		notFoundError := &unifi.NotFoundError{}
+		if errors.As(err, &notFoundError) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete user with MAC %s: %w", macAddress, &BackendError{Err: err})
+	}
+	return nil
+}
+
+// ClearFixedIP removes the fixed IP of the client with the MAC but keeps the client,
+// for clients the provider did not create.
+func (c *Client) ClearFixedIP(ctx context.Context, macAddress string) error {
+	start := time.Now()
+	user, err := c.client.GetClientByMAC(ctx, c.site, macAddress)
+	c.observe(ctx, "GetUserByMAC", start, err)
 	if err != nil {
 		// If the user is not found, that's acceptable - already released.
 		notFoundError := &unifi.NotFoundError{}
 		if errors.As(err, &notFoundError) {
 			return nil
 		}
-		return fmt.Errorf("failed to delete user with MAC %s: %w", macAddress, err)
+		return fmt.Errorf("failed to get user with MAC %s: %w", macAddress, &BackendError{Err: err})
+	}
+
+	if !user.UseFixedIP && user.FixedIP == "" {
+		return nil
+	}
+	user.UseFixedIP = false
+	user.FixedIP = ""
+	if err := c.updateUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to clear fixed IP of user with MAC %s: %w", macAddress, &BackendError{Err: err})
 	}
 	return nil
 }
@@ -561,15 +902,18 @@ type StaticAssignment struct {
 	IP       string
 	MAC      string
 	Hostname string
+	Name     string
 }
 
 // GetStaticAssignments retrieves all static DHCP assignments for a network.
 // This queries all Unifi User objects with fixed IPs in the specified network.
 func (c *Client) GetStaticAssignments(ctx context.Context, networkID string) ([]StaticAssignment, error) {
 	// List all users with fixed IP assignments
-	users, err := c.client.ListUser(ctx, c.site)
+	start := time.Now()
+	users, err := c.client.ListClient(ctx, c.site)
+	c.observe(ctx, "ListUser", start, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
+		return nil, fmt.Errorf("failed to list users: %w", &BackendError{Err: err})
 	}
 
 	assignments := make([]StaticAssignment, 0)
@@ -581,6 +925,7 @@ func (c *Client) GetStaticAssignments(ctx context.Context, networkID string) ([]
 				IP:       user.FixedIP,
 				MAC:      user.MAC,
 				Hostname: user.Hostname,
+				Name:     user.Name,
 			})
 		}
 	}
@@ -588,20 +933,21 @@ func (c *Client) GetStaticAssignments(ctx context.Context, networkID string) ([]
 	return assignments, nil
 }
 
-// CreateStaticAssignment creates a static DHCP assignment in Unifi.
-func (c *Client) CreateStaticAssignment(ctx context.Context, networkID, ip, macAddress, hostname string) error {
+// CreateStaticAssignment creates a static DHCP assignment in Unifi. An empty
+// userGroupID keeps the default user group, as for pools without a user group.
+func (c *Client) CreateStaticAssignment(ctx context.Context, networkID, userGroupID, ip, macAddress, hostname string) error {
 	// Create or update User object with fixed IP
-	user := &unifi.User{
-		MAC:        macAddress,
-		FixedIP:    ip,
-		Hostname:   hostname,
-		UseFixedIP: true,
-		NetworkID:  networkID,
+	user := &unifi.Client{
+		MAC:         macAddress,
+		FixedIP:     ip,
+		Hostname:    hostname,
+		UseFixedIP:  true,
+		NetworkID:   networkID,
+		UserGroupID: userGroupID,
 	}
 
-	_, err := c.client.CreateUser(ctx, c.site, user)
-	if err != nil {
-		return fmt.Errorf("failed to create static assignment: %w", err)
+	if _, err := c.createUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to create static assignment: %w", &BackendError{Err: err})
 	}
 
 	return nil
@@ -609,14 +955,160 @@ func (c *Client) CreateStaticAssignment(ctx context.Context, networkID, ip, macA
 
 // DeleteStaticAssignment removes a static DHCP assignment by MAC address.
 func (c *Client) DeleteStaticAssignment(ctx context.Context, networkID, macAddress string) error {
-	err := c.client.DeleteUserByMAC(ctx, c.site, macAddress)
-	if err != nil {
+	if err := c.deleteUserByMAC(ctx, macAddress); err != nil {
 		// If the user is not found, that's acceptable - already released.
 		notFoundError := &unifi.NotFoundError{}
 		if errors.As(err, &notFoundError) {
 			return nil
 		}
-		return fmt.Errorf("failed to delete static assignment: %w", err)
+		return fmt.Errorf("failed to delete static assignment: %w", &BackendError{Err: err})
+	}
+	return nil
+}
+
+// ExpectedReservation is the Unifi reservation backing an IPAddress.
+type ExpectedReservation struct {
+	Address   string
+	MAC       string
+	Hostname  string
+	ClaimName string
+	// UserGroupID is the Unifi user group of the client, any group if empty.
+	UserGroupID string
+	// Adopted reservations belong to a pre-existing Unifi client.
+	Adopted bool
+}
+
+// ExpectedReservations returns the Unifi reservations backing the addresses. The
+// reservations of claims are bound to the MAC recorded on the IPAddress, or to the
+// pre-existing client the address adopted. Clients created for claims are expected in
+// the pool's user group. Addresses being deleted are skipped, as their reservation is
+// being released, and so are addresses without a recorded MAC.
+func ExpectedReservations(addresses []ipamv1beta2.IPAddress, userGroupID string) []ExpectedReservation {
+	expected := make([]ExpectedReservation, 0, len(addresses))
+	for i := range addresses {
+		address := &addresses[i]
+		if !address.DeletionTimestamp.IsZero() || address.Spec.Address == "" {
+			continue
+		}
+
+		want := ExpectedReservation{
+			Address:   address.Spec.Address,
+			MAC:       poolutil.AddressMAC(address),
+			Hostname:    address.Spec.ClaimRef.Name,
+			ClaimName:   address.Spec.ClaimRef.Name,
+			UserGroupID: userGroupID,
+		}
+		if mac := address.Annotations[poolutil.AdoptedMACAnnotation]; mac != "" {
+			want.MAC = mac
+			want.UserGroupID = ""
+			want.Adopted = true
+		}
+		if want.MAC == "" {
+			continue
+		}
+		expected = append(expected, want)
+	}
+	return expected
+}
+
+// ReservationDrift is an expected reservation that differs from its Unifi client.
+type ReservationDrift struct {
+	ExpectedReservation
+	Reason v1beta2.ReservationMismatchReason
+	// Observed is the fixed IP, network ID, user group ID or conflicting MAC found in Unifi.
+	Observed string
+}
+
+// CheckReservations compares the Unifi clients holding the expected reservations on the
+// network with them and returns the ones that differ. A fixed IP held by another client
+// on the network is reported as a conflict.
+func (c *Client) CheckReservations(ctx context.Context, networkID string, expected []ExpectedReservation) ([]ReservationDrift, error) {
+	start := time.Now()
+	users, err := c.client.ListClient(ctx, c.site)
+	c.observe(ctx, "ListUser", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", &BackendError{Err: err})
+	}
+	return reservationDrifts(users, networkID, expected), nil
+}
+
+// reservationDrifts compares the Unifi clients with the expected reservations on the
+// network and returns the ones that differ.
+func reservationDrifts(users []unifi.Client, networkID string, expected []ExpectedReservation) []ReservationDrift {
+	usersByMAC := make(map[string]*unifi.Client, len(users))
+	usersByIP := make(map[string]*unifi.Client, len(users))
+	for i := range users {
+		usersByMAC[strings.ToLower(users[i].MAC)] = &users[i]
+		if users[i].UseFixedIP && users[i].NetworkID == networkID && users[i].FixedIP != "" {
+			usersByIP[users[i].FixedIP] = &users[i]
+		}
+	}
+
+	var drifts []ReservationDrift
+	for _, want := range expected {
+		reason, observed := compareReservation(usersByMAC[strings.ToLower(want.MAC)], want, networkID)
+		if holder := usersByIP[want.Address]; holder != nil && !strings.EqualFold(holder.MAC, want.MAC) {
+			reason, observed = v1beta2.ReservationIPConflict, holder.MAC
+		}
+		if reason != "" {
+			drifts = append(drifts, ReservationDrift{ExpectedReservation: want, Reason: reason, Observed: observed})
+		}
+	}
+	return drifts
+}
+
+// compareReservation returns how a Unifi client differs from the expected reservation,
+// or an empty reason if it matches.
+func compareReservation(user *unifi.Client, want ExpectedReservation, networkID string) (v1beta2.ReservationMismatchReason, string) {
+	switch {
+	case user == nil:
+		return v1beta2.ReservationMissing, ""
+	case !user.UseFixedIP:
+		return v1beta2.ReservationFixedIPDisabled, user.FixedIP
+	case user.NetworkID != networkID:
+		return v1beta2.ReservationNetworkChanged, user.NetworkID
+	case user.FixedIP != want.Address:
+		return v1beta2.ReservationIPChanged, user.FixedIP
+	case want.UserGroupID != "" && user.UserGroupID != want.UserGroupID:
+		return v1beta2.ReservationUserGroupChanged, user.UserGroupID
+	default:
+		return "", ""
+	}
+}
+
+// RepairReservation recreates a missing reservation or resets a changed one to the
+// expected fixed IP on the network and, if set, the user group.
+func (c *Client) RepairReservation(ctx context.Context, networkID, userGroupID string, drift ReservationDrift) error {
+	if drift.Reason == v1beta2.ReservationMissing {
+		user := &unifi.Client{
+			MAC:         drift.MAC,
+			FixedIP:     drift.Address,
+			Hostname:    drift.Hostname,
+			UseFixedIP:  true,
+			NetworkID:   networkID,
+			UserGroupID: userGroupID,
+		}
+		if _, err := c.createUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to recreate user with MAC %s: %w", drift.MAC, &BackendError{Err: err})
+		}
+		return nil
+	}
+
+	start := time.Now()
+	user, err := c.client.GetClientByMAC(ctx, c.site, drift.MAC)
+	c.observe(ctx, "GetUserByMAC", start, err)
+	if err != nil {
+		return fmt.Errorf("failed to get user with MAC %s: %w", drift.MAC, &BackendError{Err: err})
+	}
+
+	user.FixedIP = drift.Address
+	user.UseFixedIP = true
+	user.NetworkID = networkID
+	if userGroupID != "" {
+		user.UserGroupID = userGroupID
+	}
+	if err := c.updateUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user with MAC %s: %w", drift.MAC, &BackendError{Err: err})
 	}
 	return nil
 }
@@ -636,20 +1128,22 @@ func (c *Client) FindNetworkForSubnet(ctx context.Context, subnet string) (*unif
 	}
 
 	// List all networks
+	start := time.Now()
 	networks, err := c.client.ListNetwork(ctx, c.site)
+	c.observe(ctx, "ListNetwork", start, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list networks: %w", err)
+		return nil, fmt.Errorf("failed to list networks: %w", &BackendError{Err: err})
 	}
 
 	// Find a network whose subnet contains the configured subnet
 	for i := range networks {
 		network := &networks[i]
-		if network.IPSubnet == "" {
+		if deref(network.IPSubnet) == "" {
 			continue
 		}
 
 		// Parse network's subnet
-		networkPrefix, err := netip.ParsePrefix(network.IPSubnet)
+		networkPrefix, err := netip.ParsePrefix(*network.IPSubnet)
 		if err != nil {
 			continue
 		}
@@ -841,16 +1335,14 @@ func decrementIP(ip netip.Addr) netip.Addr {
 	return netip.AddrFrom4(octets)
 }
 
-// formatIPRange formats two IP addresses as a CIDR or range string.
-// If they form a valid CIDR block, returns CIDR notation, otherwise returns "start-end".
+// formatIPRange formats two IP addresses as a single IP, a CIDR if the range is
+// exactly one prefix, or a start-end range.
 func formatIPRange(start, end netip.Addr) string {
-	if !start.Is4() || !end.Is4() {
-		return "" // Only support IPv4
+	r := netipx.IPRangeFrom(start, end)
+	if !r.IsValid() {
+		return ""
 	}
-
-	// Try to express as CIDR if possible
-	// For simplicity, just return as IP range format
-	return fmt.Sprintf("%s-%s", start.String(), end.String())
+	return poolutil.FormatExcludeRange(r)
 }
 
 // collectDNSServers gathers non-empty DNS server addresses from network configuration.
@@ -870,3 +1362,12 @@ func collectDNSServers(network *unifi.Network) []string {
 	}
 	return dnsServers
 }
+
+// deref returns the value p points to, or the zero value if p is nil.
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
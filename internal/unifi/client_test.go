@@ -22,6 +22,12 @@ import (
 	"testing"
 
 	"github.com/ubiquiti-community/go-unifi/unifi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
 )
 
 func TestNewClient(t *testing.T) {
@@ -52,7 +58,7 @@ func TestNewClient(t *testing.T) {
 
 func TestClient_ValidateCredentials(t *testing.T) {
 	type fields struct {
-		client *unifi.Client
+		client *unifi.ApiClient
 		site   string
 	}
 	type args struct{}
@@ -79,7 +85,7 @@ func TestClient_ValidateCredentials(t *testing.T) {
 
 func TestClient_GetNetwork(t *testing.T) {
 	type fields struct {
-		client *unifi.Client
+		client *unifi.ApiClient
 		site   string
 	}
 	type args struct {
@@ -117,7 +123,7 @@ func TestClient_GetNetwork(t *testing.T) {
 /*
 func TestClient_GetOrAllocateIP(t *testing.T) {
 	type fields struct {
-		client *unifi.Client
+		client *unifi.ApiClient
 		site   string
 	}
 	type args struct {
@@ -158,7 +164,7 @@ func TestClient_GetOrAllocateIP(t *testing.T) {
 /*
 func TestClient_allocateNextIP(t *testing.T) {
 	type fields struct {
-		client *unifi.Client
+		client *unifi.ApiClient
 		site   string
 	}
 	type args struct {
@@ -203,7 +209,7 @@ func TestClient_allocateNextIP(t *testing.T) {
 
 func TestClient_ReleaseIP(t *testing.T) {
 	type fields struct {
-		client *unifi.Client
+		client *unifi.ApiClient
 		site   string
 	}
 	type args struct {
@@ -231,3 +237,224 @@ func TestClient_ReleaseIP(t *testing.T) {
 		})
 	}
 }
+
+func TestFindUserGroup(t *testing.T) {
+	groups := []unifi.ClientGroup{
+		{ID: "group-default", Name: "Default"},
+		{ID: "group-dev", Name: "dev-clusters"},
+		{ID: "group-dup-1", Name: "duplicate"},
+		{ID: "group-dup-2", Name: "duplicate"},
+	}
+
+	tests := []struct {
+		name     string
+		nameOrID string
+		wantID   string
+		wantErr  bool
+	}{
+		{
+			name:     "match by ID",
+			nameOrID: "group-dev",
+			wantID:   "group-dev",
+		},
+		{
+			name:     "match by name",
+			nameOrID: "dev-clusters",
+			wantID:   "group-dev",
+		},
+		{
+			name:     "ID of a duplicated name",
+			nameOrID: "group-dup-2",
+			wantID:   "group-dup-2",
+		},
+		{
+			name:     "ambiguous name",
+			nameOrID: "duplicate",
+			wantErr:  true,
+		},
+		{
+			name:     "not found",
+			nameOrID: "missing",
+			wantErr:  true,
+		},
+		{
+			name:     "empty",
+			nameOrID: "",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findUserGroup(groups, tt.nameOrID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("findUserGroup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.ID != tt.wantID {
+				t.Errorf("findUserGroup() = %v, want %v", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestCompareReservation(t *testing.T) {
+	want := ExpectedReservation{Address: "10.1.40.10", MAC: "02:00:00:00:00:01", UserGroupID: "group-1"}
+
+	tests := []struct {
+		name         string
+		user         *unifi.Client
+		wantReason   v1beta2.ReservationMismatchReason
+		wantObserved string
+	}{
+		{
+			name: "matching reservation",
+			user: &unifi.Client{MAC: want.MAC, FixedIP: "10.1.40.10", UseFixedIP: true, NetworkID: "net-1", UserGroupID: "group-1"},
+		},
+		{
+			name:       "missing client",
+			wantReason: v1beta2.ReservationMissing,
+		},
+		{
+			name:         "fixed IP disabled",
+			user:         &unifi.Client{MAC: want.MAC, FixedIP: "10.1.40.10", NetworkID: "net-1"},
+			wantReason:   v1beta2.ReservationFixedIPDisabled,
+			wantObserved: "10.1.40.10",
+		},
+		{
+			name:         "moved to another network",
+			user:         &unifi.Client{MAC: want.MAC, FixedIP: "10.1.50.10", UseFixedIP: true, NetworkID: "net-2"},
+			wantReason:   v1beta2.ReservationNetworkChanged,
+			wantObserved: "net-2",
+		},
+		{
+			name:         "different fixed IP",
+			user:         &unifi.Client{MAC: want.MAC, FixedIP: "10.1.40.99", UseFixedIP: true, NetworkID: "net-1"},
+			wantReason:   v1beta2.ReservationIPChanged,
+			wantObserved: "10.1.40.99",
+		},
+		{
+			name:         "different user group",
+			user:         &unifi.Client{MAC: want.MAC, FixedIP: "10.1.40.10", UseFixedIP: true, NetworkID: "net-1", UserGroupID: "group-2"},
+			wantReason:   v1beta2.ReservationUserGroupChanged,
+			wantObserved: "group-2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, observed := compareReservation(tt.user, want, "net-1")
+			if reason != tt.wantReason || observed != tt.wantObserved {
+				t.Errorf("compareReservation() = (%q, %q), want (%q, %q)", reason, observed, tt.wantReason, tt.wantObserved)
+			}
+		})
+	}
+}
+
+func TestReservationDrifts(t *testing.T) {
+	expected := []ExpectedReservation{
+		{Address: "10.1.40.10", MAC: "02:00:00:00:00:01"},
+		{Address: "10.1.40.11", MAC: "02:00:00:00:00:02"},
+		{Address: "10.1.40.12", MAC: "02:00:00:00:00:03"},
+	}
+	users := []unifi.Client{
+		{MAC: "02:00:00:00:00:01", FixedIP: "10.1.40.10", UseFixedIP: true, NetworkID: "net-1"},
+		{MAC: "aa:bb:cc:dd:ee:ff", FixedIP: "10.1.40.11", UseFixedIP: true, NetworkID: "net-1"},
+		{MAC: "02:00:00:00:00:03", FixedIP: "10.1.40.99", UseFixedIP: true, NetworkID: "net-1"},
+		{MAC: "11:22:33:44:55:66", FixedIP: "10.1.40.12", UseFixedIP: true, NetworkID: "net-2"},
+	}
+
+	got := reservationDrifts(users, "net-1", expected)
+	want := []ReservationDrift{
+		{ExpectedReservation: expected[1], Reason: v1beta2.ReservationIPConflict, Observed: "aa:bb:cc:dd:ee:ff"},
+		{ExpectedReservation: expected[2], Reason: v1beta2.ReservationIPChanged, Observed: "10.1.40.99"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reservationDrifts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExpectedReservations(t *testing.T) {
+	now := metav1.Now()
+	address := func(name, ip string) ipamv1beta2.IPAddress {
+		return ipamv1beta2.IPAddress{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: ipamv1beta2.IPAddressSpec{
+				ClaimRef: ipamv1beta2.IPAddressClaimReference{Name: name},
+				Address:  ip,
+			},
+		}
+	}
+
+	managed := address("web-0", "10.1.40.10")
+	poolutil.SetAddressMAC(&managed, ClaimMACAddress("default", "web-0"))
+	adopted := address("legacy-0", "10.1.40.20")
+	adopted.Annotations = map[string]string{poolutil.AdoptedMACAnnotation: "aa:bb:cc:dd:ee:ff"}
+	deleting := address("web-1", "10.1.40.11")
+	deleting.DeletionTimestamp = &now
+	deleting.Finalizers = []string{"ipam.cluster.x-k8s.io/ProtectAddress"}
+
+	unlabeled := address("web-2", "10.1.40.12")
+
+	got := ExpectedReservations([]ipamv1beta2.IPAddress{managed, adopted, deleting, unlabeled, address("pending", "")}, "group-1")
+	want := []ExpectedReservation{
+		{Address: "10.1.40.10", MAC: ClaimMACAddress("default", "web-0"), Hostname: "web-0", ClaimName: "web-0", UserGroupID: "group-1"},
+		{Address: "10.1.40.20", MAC: "aa:bb:cc:dd:ee:ff", Hostname: "legacy-0", ClaimName: "legacy-0", Adopted: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpectedReservations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHeldByOther(t *testing.T) {
+	addressesInUse := []ipamv1beta2.IPAddress{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "worker-0"},
+			Spec: ipamv1beta2.IPAddressSpec{
+				ClaimRef: ipamv1beta2.IPAddressClaimReference{Name: "worker-0"},
+				Address:  "10.1.40.10",
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		ip   string
+		req  AllocationRequest
+		want string
+	}{
+		{
+			name: "held by the requester",
+			ip:   "10.1.40.10",
+			req:  AllocationRequest{Name: "worker-0", Namespace: "team-a"},
+		},
+		{
+			name: "held by a claim of the same name in another namespace",
+			ip:   "10.1.40.10",
+			req:  AllocationRequest{Name: "worker-0", Namespace: "team-b"},
+			want: "claim team-a/worker-0",
+		},
+		{
+			name: "held by another claim",
+			ip:   "10.1.40.10",
+			req:  AllocationRequest{Name: "worker-1", Namespace: "team-a"},
+			want: "claim team-a/worker-0",
+		},
+		{
+			name: "held by a reservation",
+			ip:   "10.1.40.11",
+			req:  AllocationRequest{Name: "worker-1", Namespace: "team-a", Reserved: map[string]string{"10.1.40.11": "team-a/printer"}},
+			want: "reservation team-a/printer",
+		},
+		{
+			name: "free",
+			ip:   "10.1.40.11",
+			req:  AllocationRequest{Name: "worker-1", Namespace: "team-a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := heldByOther(addressesInUse, tt.ip, tt.req); got != tt.want {
+				t.Errorf("heldByOther() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
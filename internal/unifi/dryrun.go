@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unifi
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxPlannedActions bounds the writes kept per instance in dry-run mode.
+const MaxPlannedActions = 50
+
+// DefaultDryRunLog records the writes suppressed by all clients in dry-run mode.
+var DefaultDryRunLog = NewDryRunLog(MaxPlannedActions)
+
+// PlannedAction is a write to a Unifi controller suppressed in dry-run mode.
+type PlannedAction struct {
+	Time      time.Time
+	Operation string
+	MAC       string
+	IP        string
+	NetworkID string
+	Hostname  string
+}
+
+// DryRunLog keeps the most recent writes suppressed per UnifiInstance.
+type DryRunLog struct {
+	mu        sync.RWMutex
+	limit     int
+	instances map[string][]PlannedAction
+}
+
+// NewDryRunLog returns an empty DryRunLog keeping up to limit actions per instance.
+func NewDryRunLog(limit int) *DryRunLog {
+	return &DryRunLog{limit: limit, instances: map[string][]PlannedAction{}}
+}
+
+// Record appends a suppressed write to an instance, dropping the oldest beyond the limit.
+func (l *DryRunLog) Record(instance string, action PlannedAction) {
+	if instance == "" {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	actions := append(l.instances[instance], action)
+	if len(actions) > l.limit {
+		actions = actions[len(actions)-l.limit:]
+	}
+	l.instances[instance] = actions
+}
+
+// Actions returns the suppressed writes of an instance, oldest first.
+func (l *DryRunLog) Actions(instance string) []PlannedAction {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	actions := l.instances[instance]
+	if len(actions) == 0 {
+		return nil
+	}
+	return append([]PlannedAction(nil), actions...)
+}
+
+// Forget drops the suppressed writes of an instance.
+func (l *DryRunLog) Forget(instance string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.instances, instance)
+}
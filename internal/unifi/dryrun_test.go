@@ -0,0 +1,48 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unifi
+
+import (
+	"testing"
+)
+
+func TestDryRunLog(t *testing.T) {
+	log := NewDryRunLog(2)
+	log.Record("", PlannedAction{Operation: "CreateUser"})
+	log.Record("default/unifi", PlannedAction{Operation: "CreateUser", MAC: "02:00:00:00:00:01"})
+	log.Record("default/unifi", PlannedAction{Operation: "UpdateUser", MAC: "02:00:00:00:00:02"})
+	log.Record("default/unifi", PlannedAction{Operation: "DeleteUserByMAC", MAC: "02:00:00:00:00:03"})
+
+	actions := log.Actions("default/unifi")
+	if len(actions) != 2 || actions[0].Operation != "UpdateUser" || actions[1].Operation != "DeleteUserByMAC" {
+		t.Fatalf("Actions() = %+v, want the two most recent actions oldest first", actions)
+	}
+
+	actions[0].Operation = "changed"
+	if got := log.Actions("default/unifi")[0].Operation; got != "UpdateUser" {
+		t.Errorf("Actions() returned the log's own slice, first operation is now %q", got)
+	}
+
+	if got := log.Actions(""); got != nil {
+		t.Errorf("Actions(\"\") = %+v, want nil", got)
+	}
+
+	log.Forget("default/unifi")
+	if got := log.Actions("default/unifi"); got != nil {
+		t.Errorf("Actions() after Forget() = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unifi
+
+import "errors"
+
+var (
+	// ErrRequestedIPConflict is returned when a preallocated or requested IP cannot
+	// be assigned to the consumer, e.g. because it is taken or outside the pool.
+	ErrRequestedIPConflict = errors.New("requested IP conflict")
+
+	// ErrNetworkNotFound is returned when the pool's network does not exist in Unifi.
+	ErrNetworkNotFound = errors.New("network not found")
+
+	// ErrPoolNotReady is returned when the pool lacks configuration the pool
+	// controller has not resolved yet, such as its network or user group.
+	ErrPoolNotReady = errors.New("pool is not ready")
+)
+
+// BackendError is returned when a call to the Unifi controller fails, e.g. because
+// it is unreachable or rejects the request. Callers can retry with backoff.
+type BackendError struct {
+	Err error
+}
+
+func (e *BackendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BackendError) Unwrap() error {
+	return e.Err
+}
+
+// IsBackendError reports whether err was caused by a failed call to the Unifi controller.
+func IsBackendError(err error) bool {
+	var backendErr *BackendError
+	return errors.As(err, &backendErr)
+}
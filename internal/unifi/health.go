@@ -0,0 +1,151 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unifi
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ubiquiti-community/go-unifi/unifi"
+)
+
+// DefaultHealthTracker records the outcome of the API calls of all clients.
+var DefaultHealthTracker = NewHealthTracker()
+
+// InstanceHealth is the outcome of the latest API calls to a UnifiInstance.
+type InstanceHealth struct {
+	// Instance is the namespace/name of the UnifiInstance.
+	Instance string `json:"instance"`
+
+	// LastSuccess is when a call last succeeded.
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+
+	// LastFailure is when a call last failed.
+	LastFailure *time.Time `json:"lastFailure,omitempty"`
+
+	// LastError is the error of the last failed call.
+	LastError string `json:"lastError,omitempty"`
+
+	// ConsecutiveFailures counts the calls that failed since the last success.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+}
+
+// Healthy reports whether the latest call to the instance succeeded.
+func (h InstanceHealth) Healthy() bool {
+	return h.ConsecutiveFailures == 0
+}
+
+// HealthTracker keeps the health of the UnifiInstances the provider talks to.
+type HealthTracker struct {
+	mu        sync.RWMutex
+	instances map[string]*InstanceHealth
+}
+
+// NewHealthTracker returns an empty HealthTracker.
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{instances: map[string]*InstanceHealth{}}
+}
+
+// Record records the outcome of a call to an instance at now.
+func (t *HealthTracker) Record(instance string, err error, now time.Time) {
+	if instance == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health, ok := t.instances[instance]
+	if !ok {
+		health = &InstanceHealth{Instance: instance}
+		t.instances[instance] = health
+	}
+	if err == nil {
+		health.LastSuccess = &now
+		health.ConsecutiveFailures = 0
+		return
+	}
+	health.LastFailure = &now
+	health.LastError = err.Error()
+	health.ConsecutiveFailures++
+}
+
+// responseStatus matches the HTTP status go-unifi adds to the errors of non-200 responses.
+var responseStatus = regexp.MustCompile(`\((\d{3})[^)]*\) for [A-Z]+ `)
+
+// unreachableError returns err if it shows the instance is unreachable or
+// failing, i.e. a transport error or a 5xx response, and nil for the errors the
+// controller answered on purpose, such as rejected credentials or requests.
+func unreachableError(err error) error {
+	if err == nil {
+		return nil
+	}
+	notFoundError := &unifi.NotFoundError{}
+	loginRequiredError := &unifi.LoginRequiredError{}
+	if errors.As(err, &notFoundError) || errors.As(err, &loginRequiredError) {
+		return nil
+	}
+	if status := responseStatus.FindStringSubmatch(err.Error()); status != nil {
+		if status[1] >= "500" {
+			return err
+		}
+		return nil
+	}
+	apiError := &unifi.APIError{}
+	if errors.As(err, &apiError) {
+		return nil
+	}
+	return err
+}
+
+// Forget drops a deleted instance.
+func (t *HealthTracker) Forget(instance string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.instances, instance)
+}
+
+// Instances returns the health of all instances ordered by name.
+func (t *HealthTracker) Instances() []InstanceHealth {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	instances := make([]InstanceHealth, 0, len(t.instances))
+	for _, health := range t.instances {
+		instances = append(instances, *health)
+	}
+	slices.SortFunc(instances, func(a, b InstanceHealth) int {
+		return strings.Compare(a.Instance, b.Instance)
+	})
+	return instances
+}
+
+// Get returns the health of an instance and whether any call to it was recorded.
+func (t *HealthTracker) Get(instance string) (InstanceHealth, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	health, ok := t.instances[instance]
+	if !ok {
+		return InstanceHealth{}, false
+	}
+	return *health, true
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unifi
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ubiquiti-community/go-unifi/unifi"
+)
+
+func TestHealthTracker(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		outcomes     []error
+		wantHealthy  bool
+		wantFailures int
+		wantSuccess  bool
+		wantError    string
+	}{
+		{
+			name:        "successful call",
+			outcomes:    []error{nil},
+			wantHealthy: true,
+			wantSuccess: true,
+		},
+		{
+			name:         "consecutive failures",
+			outcomes:     []error{nil, errors.New("timeout"), errors.New("refused")},
+			wantFailures: 2,
+			wantSuccess:  true,
+			wantError:    "refused",
+		},
+		{
+			name:        "recovered",
+			outcomes:    []error{errors.New("timeout"), nil},
+			wantHealthy: true,
+			wantSuccess: true,
+			wantError:   "timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewHealthTracker()
+			for i, err := range tt.outcomes {
+				tracker.Record("default/unifi", err, now.Add(time.Duration(i)*time.Minute))
+			}
+
+			health, ok := tracker.Get("default/unifi")
+			if !ok {
+				t.Fatal("instance not tracked")
+			}
+			if health.Healthy() != tt.wantHealthy {
+				t.Errorf("Healthy() = %v, want %v", health.Healthy(), tt.wantHealthy)
+			}
+			if health.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("ConsecutiveFailures = %d, want %d", health.ConsecutiveFailures, tt.wantFailures)
+			}
+			if (health.LastSuccess != nil) != tt.wantSuccess {
+				t.Errorf("LastSuccess = %v, want set %v", health.LastSuccess, tt.wantSuccess)
+			}
+			if health.LastError != tt.wantError {
+				t.Errorf("LastError = %q, want %q", health.LastError, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestHealthTracker_Instances(t *testing.T) {
+	now := time.Now()
+	tracker := NewHealthTracker()
+	tracker.Record("ns-b/unifi", nil, now)
+	tracker.Record("ns-a/unifi", errors.New("timeout"), now)
+	tracker.Record("", nil, now)
+
+	instances := tracker.Instances()
+	if len(instances) != 2 || instances[0].Instance != "ns-a/unifi" || instances[1].Instance != "ns-b/unifi" {
+		t.Fatalf("Instances() = %v, want ns-a/unifi and ns-b/unifi", instances)
+	}
+
+	tracker.Forget("ns-a/unifi")
+	if _, ok := tracker.Get("ns-a/unifi"); ok {
+		t.Error("forgotten instance is still tracked")
+	}
+}
+
+func TestUnreachableError(t *testing.T) {
+	apiError := func(status string) error {
+		return fmt.Errorf("%w (%s) for POST https://unifi/api/s/default/rest/user\npayload: {}",
+			&unifi.APIError{RC: "error", Message: "api.err.Invalid"}, status)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "success", err: nil, want: false},
+		{name: "transport error", err: errors.New("dial tcp 10.0.0.1:443: connect: connection refused"), want: true},
+		{name: "server error", err: apiError("502 Bad Gateway"), want: true},
+		{name: "rejected request", err: apiError("400 Bad Request"), want: false},
+		{name: "rejected credentials", err: &unifi.LoginRequiredError{APIKey: true}, want: false},
+		{name: "not found", err: fmt.Errorf("get user: %w", &unifi.NotFoundError{}), want: false},
+		{name: "api error", err: &unifi.APIError{RC: "error", Message: "api.err.MacUsed"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unreachableError(tt.err) != nil; got != tt.want {
+				t.Errorf("unreachableError(%v) = %v, want unreachable %v", tt.err, unreachableError(tt.err), tt.want)
+			}
+		})
+	}
+}
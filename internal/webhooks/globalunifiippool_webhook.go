@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/internal/poolutil"
+)
+
+// GlobalUnifiIPPoolWebhook implements validating webhooks for GlobalUnifiIPPool.
+// Pool configuration is validated the same way as for UnifiIPPool.
+type GlobalUnifiIPPoolWebhook struct {
+	Client client.Client
+}
+
+// SetupWebhookWithManager registers the webhook with the controller manager.
+func (w *GlobalUnifiIPPoolWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	w.Client = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr, &v1beta2.GlobalUnifiIPPool{}).
+		WithValidator(w).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-ipam-cluster-x-k8s-io-v1beta2-globalunifiippool,mutating=false,failurePolicy=fail,sideEffects=None,groups=ipam.cluster.x-k8s.io,resources=globalunifiippools,verbs=create;update;delete,versions=v1beta2,name=vglobalunifiippool.kb.io,admissionReviewVersions=v1
+
+// ValidateCreate implements admission.Validator.
+func (w *GlobalUnifiIPPoolWebhook) ValidateCreate(ctx context.Context, pool *v1beta2.GlobalUnifiIPPool) (admission.Warnings, error) {
+	if err := w.validate(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return w.poolWebhook().validateOverlaps(ctx, pool)
+}
+
+// ValidateUpdate implements admission.Validator.
+func (w *GlobalUnifiIPPoolWebhook) ValidateUpdate(ctx context.Context, oldPool, newPool *v1beta2.GlobalUnifiIPPool) (admission.Warnings, error) {
+	if err := w.validate(ctx, newPool); err != nil {
+		return nil, err
+	}
+
+	var warnings admission.Warnings
+	if newPool.DeletionTimestamp.IsZero() && overlapInputsChanged(oldPool, newPool) {
+		var err error
+		if warnings, err = w.poolWebhook().validateOverlaps(ctx, newPool); err != nil {
+			return warnings, err
+		}
+	}
+
+	// Check if allocated IPs would be orphaned by the update.
+	return warnings, w.poolWebhook().validateUpdate(ctx, oldPool, newPool)
+}
+
+// ValidateDelete implements admission.Validator.
+func (w *GlobalUnifiIPPoolWebhook) ValidateDelete(ctx context.Context, pool *v1beta2.GlobalUnifiIPPool) (admission.Warnings, error) {
+	// Allow deletion if skip annotation is set.
+	if _, ok := pool.Annotations[skipValidateDeleteWebhookAnnotation]; ok {
+		return nil, nil
+	}
+
+	// Check if there are allocated IPAddresses in any namespace.
+	addresses, err := poolutil.ListAddressesInUse(ctx, w.Client, "", pool.Name, v1beta2.GlobalUnifiIPPoolKind, v1beta2.GroupVersion.Group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list allocated addresses: %w", err)
+	}
+
+	if len(addresses) > 0 {
+		return nil, field.Forbidden(
+			field.NewPath("metadata"),
+			fmt.Sprintf("cannot delete GlobalUnifiIPPool with %d allocated IP address(es). Delete IPAddress resources first or add annotation %s=true to bypass this check", len(addresses), skipValidateDeleteWebhookAnnotation),
+		)
+	}
+
+	return nil, nil
+}
+
+// validate performs common validation for create and update.
+func (w *GlobalUnifiIPPoolWebhook) validate(ctx context.Context, pool *v1beta2.GlobalUnifiIPPool) error {
+	if allErrs := validateGlobalPoolScope(&pool.Spec); len(allErrs) > 0 {
+		return allErrs.ToAggregate()
+	}
+
+	return w.poolWebhook().validate(ctx, pool)
+}
+
+// poolWebhook returns the UnifiIPPool webhook used for the shared pool validation.
+func (w *GlobalUnifiIPPoolWebhook) poolWebhook() *UnifiIPPoolWebhook {
+	return &UnifiIPPoolWebhook{Client: w.Client}
+}
+
+// validateGlobalPoolScope validates the fields that only exist on cluster-scoped pools.
+func validateGlobalPoolScope(spec *v1beta2.GlobalUnifiIPPoolSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// A cluster-scoped pool has no namespace to default the instance namespace from.
+	if spec.InstanceRef.Namespace == "" {
+		allErrs = append(allErrs, field.Required(
+			field.NewPath("spec", "instanceRef", "namespace"),
+			"instanceRef.namespace is required for cluster-scoped pools",
+		))
+	}
+
+	for i, namespace := range spec.AllowedNamespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "allowedNamespaces").Index(i), namespace, msg))
+		}
+	}
+
+	if spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "namespaceSelector"), spec.NamespaceSelector, err.Error()))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
+)
+
+func Test_validateGlobalPoolScope(t *testing.T) {
+	instanceRef := corev1.ObjectReference{Name: "unifi", Namespace: "unifi-system"}
+	tests := []struct {
+		name     string
+		spec     v1beta2.GlobalUnifiIPPoolSpec
+		wantErrs int
+	}{
+		{
+			name: "open pool",
+			spec: v1beta2.GlobalUnifiIPPoolSpec{
+				UnifiIPPoolSpec: v1beta2.UnifiIPPoolSpec{InstanceRef: instanceRef},
+			},
+		},
+		{
+			name: "missing instance namespace",
+			spec: v1beta2.GlobalUnifiIPPoolSpec{
+				UnifiIPPoolSpec: v1beta2.UnifiIPPoolSpec{InstanceRef: corev1.ObjectReference{Name: "unifi"}},
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "invalid allowed namespace",
+			spec: v1beta2.GlobalUnifiIPPoolSpec{
+				UnifiIPPoolSpec:   v1beta2.UnifiIPPoolSpec{InstanceRef: instanceRef},
+				AllowedNamespaces: []string{"team-a", "Team_B"},
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "invalid selector",
+			spec: v1beta2.GlobalUnifiIPPoolSpec{
+				UnifiIPPoolSpec: v1beta2.UnifiIPPoolSpec{InstanceRef: instanceRef},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "tenant", Operator: "Bogus"},
+					},
+				},
+			},
+			wantErrs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateGlobalPoolScope(&tt.spec); len(got) != tt.wantErrs {
+				t.Errorf("validateGlobalPoolScope() = %v, want %d errors", got, tt.wantErrs)
+			}
+		})
+	}
+}
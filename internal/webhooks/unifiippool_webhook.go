@@ -18,11 +18,13 @@ package webhooks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/netip"
 
 	"go4.org/netipx"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,6 +38,10 @@ import (
 
 const (
 	skipValidateDeleteWebhookAnnotation = "ipam.cluster.x-k8s.io/skip-validate-delete-webhook"
+
+	// allowOverlapAnnotation turns overlaps with other pools on the same Unifi site
+	// into admission warnings instead of rejecting the pool.
+	allowOverlapAnnotation = "ipam.cluster.x-k8s.io/allow-overlap"
 )
 
 // UnifiIPPoolWebhook implements validating and defaulting webhooks for UnifiIPPool.
@@ -46,8 +52,7 @@ type UnifiIPPoolWebhook struct {
 // SetupWebhookWithManager registers the webhook with the controller manager.
 func (w *UnifiIPPoolWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	w.Client = mgr.GetClient()
-	return ctrl.NewWebhookManagedBy(mgr).
-		For(&v1beta2.UnifiIPPool{}).
+	return ctrl.NewWebhookManagedBy(mgr, &v1beta2.UnifiIPPool{}).
 		WithValidator(w).
 		WithDefaulter(w).
 		Complete()
@@ -55,13 +60,8 @@ func (w *UnifiIPPoolWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 // +kubebuilder:webhook:path=/mutate-ipam-cluster-x-k8s-io-v1alpha1-unifiippool,mutating=true,failurePolicy=fail,sideEffects=None,groups=ipam.cluster.x-k8s.io,resources=unifiippools,verbs=create;update,versions=v1alpha1,name=munifiippool.kb.io,admissionReviewVersions=v1
 
-// Default implements webhook.Defaulter.
-func (w *UnifiIPPoolWebhook) Default(ctx context.Context, obj runtime.Object) error {
-	pool, ok := obj.(*v1beta2.UnifiIPPool)
-	if !ok {
-		return fmt.Errorf("expected UnifiIPPool, got %T", obj)
-	}
-
+// Default implements admission.Defaulter.
+func (w *UnifiIPPoolWebhook) Default(ctx context.Context, pool *v1beta2.UnifiIPPool) error {
 	// Set default namespace for InstanceRef if not specified.
 	if pool.Spec.InstanceRef.Namespace == "" {
 		pool.Spec.InstanceRef.Namespace = pool.Namespace
@@ -72,44 +72,36 @@ func (w *UnifiIPPoolWebhook) Default(ctx context.Context, obj runtime.Object) er
 
 // +kubebuilder:webhook:path=/validate-ipam-cluster-x-k8s-io-v1alpha1-unifiippool,mutating=false,failurePolicy=fail,sideEffects=None,groups=ipam.cluster.x-k8s.io,resources=unifiippools,verbs=create;update;delete,versions=v1alpha1,name=vunifiippool.kb.io,admissionReviewVersions=v1
 
-// ValidateCreate implements webhook.CustomValidator.
-func (w *UnifiIPPoolWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	pool, ok := obj.(*v1beta2.UnifiIPPool)
-	if !ok {
-		return nil, fmt.Errorf("expected UnifiIPPool, got %T", obj)
+// ValidateCreate implements admission.Validator.
+func (w *UnifiIPPoolWebhook) ValidateCreate(ctx context.Context, pool *v1beta2.UnifiIPPool) (admission.Warnings, error) {
+	if err := w.validate(ctx, pool); err != nil {
+		return nil, err
 	}
 
-	return nil, w.validate(ctx, pool)
+	return w.validateOverlaps(ctx, pool)
 }
 
-// ValidateUpdate implements webhook.CustomValidator.
-func (w *UnifiIPPoolWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
-	oldPool, ok := oldObj.(*v1beta2.UnifiIPPool)
-	if !ok {
-		return nil, fmt.Errorf("expected UnifiIPPool, got %T", oldObj)
-	}
-
-	newPool, ok := newObj.(*v1beta2.UnifiIPPool)
-	if !ok {
-		return nil, fmt.Errorf("expected UnifiIPPool, got %T", newObj)
-	}
-
+// ValidateUpdate implements admission.Validator.
+func (w *UnifiIPPoolWebhook) ValidateUpdate(ctx context.Context, oldPool, newPool *v1beta2.UnifiIPPool) (admission.Warnings, error) {
 	// Validate the new pool.
 	if err := w.validate(ctx, newPool); err != nil {
 		return nil, err
 	}
 
+	var warnings admission.Warnings
+	if newPool.DeletionTimestamp.IsZero() && overlapInputsChanged(oldPool, newPool) {
+		var err error
+		if warnings, err = w.validateOverlaps(ctx, newPool); err != nil {
+			return warnings, err
+		}
+	}
+
 	// Check if allocated IPs would be orphaned by the update.
-	return nil, w.validateUpdate(ctx, oldPool, newPool)
+	return warnings, w.validateUpdate(ctx, oldPool, newPool)
 }
 
-// ValidateDelete implements webhook.CustomValidator.
-func (w *UnifiIPPoolWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	pool, ok := obj.(*v1beta2.UnifiIPPool)
-	if !ok {
-		return nil, fmt.Errorf("expected UnifiIPPool, got %T", obj)
-	}
-
+// ValidateDelete implements admission.Validator.
+func (w *UnifiIPPoolWebhook) ValidateDelete(ctx context.Context, pool *v1beta2.UnifiIPPool) (admission.Warnings, error) {
 	// Allow deletion if skip annotation is set.
 	if _, ok := pool.Annotations[skipValidateDeleteWebhookAnnotation]; ok {
 		return nil, nil
@@ -128,52 +120,77 @@ func (w *UnifiIPPoolWebhook) ValidateDelete(ctx context.Context, obj runtime.Obj
 		)
 	}
 
+	// Check if there are UnifiIPReservations holding addresses.
+	reservations, err := poolutil.ListReservationsInUse(ctx, w.Client, pool.Namespace, pool.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+
+	if len(reservations) > 0 {
+		return nil, field.Forbidden(
+			field.NewPath("metadata"),
+			fmt.Sprintf("cannot delete UnifiIPPool with %d UnifiIPReservation(s). Delete the reservations first or add annotation %s=true to bypass this check", len(reservations), skipValidateDeleteWebhookAnnotation),
+		)
+	}
+
 	return nil, nil
 }
 
 // validate performs common validation for create and update.
-func (w *UnifiIPPoolWebhook) validate(ctx context.Context, pool *v1beta2.UnifiIPPool) error {
+func (w *UnifiIPPoolWebhook) validate(ctx context.Context, pool v1beta2.GenericUnifiIPPool) error {
+	spec := pool.PoolSpec()
+
 	var allErrs field.ErrorList
 
 	// NetworkID is now optional (can be auto-discovered)
 	// No validation needed
 
 	// Validate InstanceRef.
-	if pool.Spec.InstanceRef.Name == "" {
+	if spec.InstanceRef.Name == "" {
 		allErrs = append(allErrs, field.Required(field.NewPath("spec", "instanceRef", "name"), "instanceRef.name is required"))
 	}
 
 	// Validate that referenced UnifiInstance exists.
-	instanceNamespace := pool.Spec.InstanceRef.Namespace
+	instanceNamespace := spec.InstanceRef.Namespace
 	if instanceNamespace == "" {
-		instanceNamespace = pool.Namespace
+		instanceNamespace = pool.GetNamespace()
 	}
 
 	instance := &v1beta2.UnifiInstance{}
 	instanceKey := client.ObjectKey{
-		Name:      pool.Spec.InstanceRef.Name,
+		Name:      spec.InstanceRef.Name,
 		Namespace: instanceNamespace,
 	}
 	if err := w.Client.Get(ctx, instanceKey, instance); err != nil {
 		if client.IgnoreNotFound(err) == nil {
-			allErrs = append(allErrs, field.NotFound(field.NewPath("spec", "instanceRef"), pool.Spec.InstanceRef.Name))
+			allErrs = append(allErrs, field.NotFound(field.NewPath("spec", "instanceRef"), spec.InstanceRef.Name))
 		} else {
 			allErrs = append(allErrs, field.InternalError(field.NewPath("spec", "instanceRef"), err))
 		}
 	}
 
 	// Validate subnets.
-	if len(pool.Spec.Subnets) == 0 {
+	if len(spec.Subnets) == 0 {
 		allErrs = append(allErrs, field.Required(field.NewPath("spec", "subnets"), "at least one subnet is required"))
 	}
 
-	for i, subnet := range pool.Spec.Subnets {
+	for i, subnet := range spec.Subnets {
 		subnetPath := field.NewPath("spec", "subnets").Index(i)
 		allErrs = append(allErrs, validateSubnet(&subnet, subnetPath)...)
 	}
 
+	// Subnets of the same pool must not hand out the same addresses twice.
+	if len(allErrs) == 0 {
+		allErrs = append(allErrs, validateSubnetOverlaps(spec)...)
+	}
+
 	// Validate PreAllocations
-	allErrs = append(allErrs, validatePreAllocations(pool)...)
+	allErrs = append(allErrs, validatePreAllocations(spec)...)
+
+	allErrs = append(allErrs, validateQuotas(spec)...)
+	allErrs = append(allErrs, validateHeadroom(spec)...)
+	allErrs = append(allErrs, validateThresholds(spec)...)
+	allErrs = append(allErrs, validateSyncInterval(spec)...)
 
 	if len(allErrs) > 0 {
 		return allErrs.ToAggregate()
@@ -182,40 +199,181 @@ func (w *UnifiIPPoolWebhook) validate(ctx context.Context, pool *v1beta2.UnifiIP
 	return nil
 }
 
+// validateQuotas checks the label selectors of the selector quotas.
+func validateQuotas(spec *v1beta2.UnifiIPPoolSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.Quotas == nil {
+		return allErrs
+	}
+
+	for i, quota := range spec.Quotas.Selectors {
+		fldPath := field.NewPath("spec", "quotas", "selectors").Index(i).Child("selector")
+		if _, err := metav1.LabelSelectorAsSelector(&quota.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, quota.Selector, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateHeadroom checks the label selector of the headroom claims.
+func validateHeadroom(spec *v1beta2.UnifiIPPoolSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.Headroom == nil {
+		return allErrs
+	}
+
+	fldPath := field.NewPath("spec", "headroom", "selector")
+	if _, err := metav1.LabelSelectorAsSelector(&spec.Headroom.Selector); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, spec.Headroom.Selector, err.Error()))
+	}
+
+	return allErrs
+}
+
+// validateThresholds rejects a warning threshold above the critical threshold.
+func validateThresholds(spec *v1beta2.UnifiIPPoolSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	thresholds := spec.UtilizationThresholds
+	if thresholds == nil || thresholds.Warning == nil || thresholds.Critical == nil {
+		return allErrs
+	}
+
+	if *thresholds.Warning > *thresholds.Critical {
+		fldPath := field.NewPath("spec", "utilizationThresholds", "warning")
+		allErrs = append(allErrs, field.Invalid(fldPath, *thresholds.Warning,
+			fmt.Sprintf("must not exceed the critical threshold %d", *thresholds.Critical)))
+	}
+
+	return allErrs
+}
+
+// validateSyncInterval rejects sync intervals shorter than poolutil.MinSyncInterval.
+func validateSyncInterval(spec *v1beta2.UnifiIPPoolSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.SyncInterval != nil && spec.SyncInterval.Duration < poolutil.MinSyncInterval {
+		fldPath := field.NewPath("spec", "syncInterval")
+		allErrs = append(allErrs, field.Invalid(fldPath, spec.SyncInterval.Duration.String(),
+			fmt.Sprintf("must be at least %s", poolutil.MinSyncInterval)))
+	}
+
+	return allErrs
+}
+
+// validateSubnetOverlaps rejects subnets of one pool that share allocatable addresses.
+func validateSubnetOverlaps(spec *v1beta2.UnifiIPPoolSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	overlaps, err := poolutil.FindSubnetOverlaps(spec)
+	if err != nil {
+		return append(allErrs, field.Invalid(field.NewPath("spec", "subnets"), spec.Subnets, err.Error()))
+	}
+
+	for _, overlap := range overlaps {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("spec", "subnets").Index(overlap.Second),
+			spec.Subnets[overlap.Second],
+			fmt.Sprintf("overlaps with subnet %d: %s", overlap.First, poolutil.FormatRanges(overlap.Ranges)),
+		))
+	}
+
+	return allErrs
+}
+
+// validateOverlaps rejects pools sharing addresses with other pools on the same Unifi
+// controller and site, since addresses in use are tracked per pool and would be
+// allocated twice. With the allow-overlap annotation the overlaps are only warned about.
+func (w *UnifiIPPoolWebhook) validateOverlaps(ctx context.Context, pool v1beta2.GenericUnifiIPPool) (admission.Warnings, error) {
+	overlaps, err := poolutil.FindPoolOverlaps(ctx, w.Client, pool)
+	if errors.Is(err, poolutil.ErrInstanceNotFound) {
+		// The pool controller reports overlaps once the instance exists.
+		return admission.Warnings{fmt.Sprintf("overlaps with other pools were not checked: %v", err)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for overlapping pools: %w", err)
+	}
+
+	if len(overlaps) == 0 {
+		return nil, nil
+	}
+
+	if pool.GetAnnotations()[allowOverlapAnnotation] == "true" {
+		warnings := make(admission.Warnings, 0, len(overlaps))
+		for _, overlap := range overlaps {
+			warnings = append(warnings, fmt.Sprintf("addresses overlap with %s", overlap))
+		}
+		return warnings, nil
+	}
+
+	var allErrs field.ErrorList
+	for _, overlap := range overlaps {
+		allErrs = append(allErrs, field.Forbidden(
+			field.NewPath("spec", "subnets"),
+			fmt.Sprintf("addresses overlap with %s. Add annotation %s=true to allow the overlap", overlap, allowOverlapAnnotation),
+		))
+	}
+
+	return nil, allErrs.ToAggregate()
+}
+
+// overlapInputsChanged reports whether an update changes what the pool overlaps with:
+// its subnets, its Unifi instance or network, or whether overlaps are allowed.
+func overlapInputsChanged(oldPool, newPool v1beta2.GenericUnifiIPPool) bool {
+	oldSpec, newSpec := oldPool.PoolSpec(), newPool.PoolSpec()
+	return !equality.Semantic.DeepEqual(oldSpec.Subnets, newSpec.Subnets) ||
+		oldSpec.InstanceRef != newSpec.InstanceRef ||
+		oldSpec.NetworkID != newSpec.NetworkID ||
+		oldPool.GetAnnotations()[allowOverlapAnnotation] != newPool.GetAnnotations()[allowOverlapAnnotation]
+}
+
 // validatePreAllocations checks PreAllocations map for issues.
-func validatePreAllocations(pool *v1beta2.UnifiIPPool) field.ErrorList {
+func validatePreAllocations(spec *v1beta2.UnifiIPPoolSpec) field.ErrorList {
 	var allErrs field.ErrorList
 
-	if len(pool.Spec.PreAllocations) == 0 {
+	if len(spec.PreAllocations) == 0 {
 		return allErrs
 	}
 
 	// Get default prefix for IPInSubnets check
 	defaultPrefix := int32(24)
-	if pool.Spec.Prefix != nil {
-		defaultPrefix = *pool.Spec.Prefix
+	if spec.Prefix != nil {
+		defaultPrefix = *spec.Prefix
 	}
 
+	// Addresses in exclude ranges and gateways are never handed out.
+	// Invalid subnets are reported by validateSubnet.
+	allocatable, _ := poolutil.PoolIPSet(spec)
+
 	// Track seen IPs to detect duplicates
 	seenIPs := make(map[string]string) // IP -> claim name
 
-	for claimName, ipStr := range pool.Spec.PreAllocations {
+	for claimName, ipStr := range spec.PreAllocations {
 		preAllocPath := field.NewPath("spec", "preAllocations").Key(claimName)
 
 		// Validate IP format
-		_, err := netip.ParseAddr(ipStr)
+		ip, err := netip.ParseAddr(ipStr)
 		if err != nil {
 			allErrs = append(allErrs, field.Invalid(preAllocPath, ipStr, fmt.Sprintf("invalid IP address: %v", err)))
 			continue
 		}
 
 		// Check if IP is in configured subnets
-		if !poolutil.IPInSubnets(ipStr, pool.Spec.Subnets, defaultPrefix) {
+		if !poolutil.IPInSubnets(ipStr, spec.Subnets, defaultPrefix) {
 			allErrs = append(allErrs, field.Invalid(
 				preAllocPath,
 				ipStr,
 				fmt.Sprintf("preallocated IP %s is not within any configured subnet", ipStr),
 			))
+		} else if allocatable != nil && !allocatable.Contains(ip) {
+			allErrs = append(allErrs, field.Invalid(
+				preAllocPath,
+				ipStr,
+				fmt.Sprintf("preallocated IP %s is excluded from the pool or is a gateway", ipStr),
+			))
 		}
 
 		// Check for duplicate IPs
@@ -260,7 +418,7 @@ func validateSubnet(subnet *v1beta2.SubnetSpec, fldPath *field.Path) field.Error
 
 		allErrs = append(allErrs, validatePrefix(subnet, cidr, fldPath)...)
 		allErrs = append(allErrs, validateGatewayInCIDR(subnet, cidr, fldPath)...)
-		allErrs = append(allErrs, validateExcludeRanges(subnet, cidr, fldPath)...)
+		allErrs = append(allErrs, validateExcludeRanges(subnet, netipx.RangeOfPrefix(cidr.Masked()), fldPath)...)
 	} else {
 		// Using Start/End range notation
 		if subnet.Start == "" {
@@ -294,6 +452,10 @@ func validateSubnet(subnet *v1beta2.SubnetSpec, fldPath *field.Path) field.Error
 				if subnet.Gateway != "" {
 					allErrs = append(allErrs, validateGatewayInRange(subnet, startIP, endIP, fldPath)...)
 				}
+
+				if subnetRange := netipx.IPRangeFrom(startIP, endIP); subnetRange.IsValid() {
+					allErrs = append(allErrs, validateExcludeRanges(subnet, subnetRange, fldPath)...)
+				}
 			}
 		}
 	}
@@ -362,50 +524,40 @@ func validateGatewayInRange(subnet *v1beta2.SubnetSpec, start, end netip.Addr, f
 	return allErrs
 }
 
-func validateExcludeRanges(subnet *v1beta2.SubnetSpec, cidr netip.Prefix, fldPath *field.Path) field.ErrorList {
+func validateExcludeRanges(subnet *v1beta2.SubnetSpec, subnetRange netipx.IPRange, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
 	for j, excludeRange := range subnet.ExcludeRanges {
 		excludePath := fldPath.Child("excludeRanges").Index(j)
-		allErrs = append(allErrs, validateExcludeRange(excludeRange, cidr, subnet.CIDR, excludePath)...)
+		allErrs = append(allErrs, validateExcludeRange(excludeRange, subnetRange, excludePath)...)
 	}
 
 	return allErrs
 }
 
-func validateExcludeRange(excludeRange string, cidr netip.Prefix, cidrStr string, fldPath *field.Path) field.ErrorList {
+// validateExcludeRange checks that an IP, CIDR or start-end exclude range parses and
+// lies within the subnet. CIDRs only need to overlap the subnet, so a wider prefix
+// can be used to exclude the subnet's edges.
+func validateExcludeRange(excludeRange string, subnetRange netipx.IPRange, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
-	// Try parsing as CIDR.
-	if prefix, err := netip.ParsePrefix(excludeRange); err == nil {
-		if !cidr.Overlaps(prefix) {
-			allErrs = append(allErrs, field.Invalid(
-				fldPath,
-				excludeRange,
-				fmt.Sprintf("exclude range %s is not within CIDR %s", excludeRange, cidrStr),
-			))
-		}
-		return allErrs
+	r, err := poolutil.ParseExcludeRange(excludeRange)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, excludeRange, err.Error()))
 	}
 
-	// Try parsing as single IP.
-	if ip, err := netip.ParseAddr(excludeRange); err == nil {
-		if !cidr.Contains(ip) {
-			allErrs = append(allErrs, field.Invalid(
-				fldPath,
-				excludeRange,
-				fmt.Sprintf("exclude IP %s is not within CIDR %s", excludeRange, cidrStr),
-			))
-		}
-		return allErrs
+	within := subnetRange.Contains(r.From()) && subnetRange.Contains(r.To())
+	if _, err := netip.ParsePrefix(excludeRange); err == nil {
+		within = subnetRange.Overlaps(r)
 	}
 
-	// If neither CIDR nor IP, it's invalid.
-	allErrs = append(allErrs, field.Invalid(
-		fldPath,
-		excludeRange,
-		"exclude range must be a valid IP address or CIDR",
-	))
+	if !within {
+		allErrs = append(allErrs, field.Invalid(
+			fldPath,
+			excludeRange,
+			fmt.Sprintf("exclude range %s is not within subnet %s", excludeRange, poolutil.FormatExcludeRange(subnetRange)),
+		))
+	}
 
 	return allErrs
 }
@@ -424,8 +576,8 @@ func validateDNSServers(subnet *v1beta2.SubnetSpec, fldPath *field.Path) field.E
 }
 
 // validateUpdate checks if the update would orphan allocated IPs.
-func (w *UnifiIPPoolWebhook) validateUpdate(ctx context.Context, oldPool, newPool *v1beta2.UnifiIPPool) error {
-	addresses, err := poolutil.ListAddressesInUse(ctx, w.Client, oldPool.Namespace, oldPool.Name, "UnifiIPPool", "ipam.cluster.x-k8s.io")
+func (w *UnifiIPPoolWebhook) validateUpdate(ctx context.Context, oldPool, newPool v1beta2.GenericUnifiIPPool) error {
+	addresses, err := poolutil.ListAddressesInUse(ctx, w.Client, oldPool.GetNamespace(), oldPool.GetName(), oldPool.PoolKind(), "ipam.cluster.x-k8s.io")
 	if err != nil {
 		return fmt.Errorf("failed to list allocated addresses: %w", err)
 	}
@@ -450,11 +602,11 @@ func (w *UnifiIPPoolWebhook) validateUpdate(ctx context.Context, oldPool, newPoo
 	return nil
 }
 
-func buildNewPoolIPSet(newPool *v1beta2.UnifiIPPool) (*netipx.IPSet, error) {
+func buildNewPoolIPSet(newPool v1beta2.GenericUnifiIPPool) (*netipx.IPSet, error) {
 	var newIPSet *netipx.IPSet
-	if len(newPool.Spec.Subnets) > 0 {
+	if len(newPool.PoolSpec().Subnets) > 0 {
 		var err error
-		newIPSet, err = poolutil.PoolSpecToIPSet(&newPool.Spec.Subnets[0])
+		newIPSet, err = poolutil.PoolIPSet(newPool.PoolSpec())
 		if err != nil {
 			return nil, fmt.Errorf("failed to build new pool IPSet: %w", err)
 		}
@@ -18,10 +18,14 @@ package webhooks
 
 import (
 	"context"
+	"net/netip"
 	"reflect"
 	"testing"
+	"time"
 
-	"k8s.io/apimachinery/pkg/runtime"
+	"go4.org/netipx"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -62,7 +66,7 @@ func TestUnifiIPPoolWebhook_Default(t *testing.T) {
 		Client client.Client
 	}
 	type args struct {
-		obj runtime.Object
+		obj *v1beta2.UnifiIPPool
 	}
 	tests := []struct {
 		name    string
@@ -89,7 +93,7 @@ func TestUnifiIPPoolWebhook_ValidateCreate(t *testing.T) {
 		Client client.Client
 	}
 	type args struct {
-		obj runtime.Object
+		obj *v1beta2.UnifiIPPool
 	}
 	tests := []struct {
 		name    string
@@ -122,8 +126,8 @@ func TestUnifiIPPoolWebhook_ValidateUpdate(t *testing.T) {
 		Client client.Client
 	}
 	type args struct {
-		oldObj runtime.Object
-		newObj runtime.Object
+		oldObj *v1beta2.UnifiIPPool
+		newObj *v1beta2.UnifiIPPool
 	}
 	tests := []struct {
 		name    string
@@ -156,7 +160,7 @@ func TestUnifiIPPoolWebhook_ValidateDelete(t *testing.T) {
 		Client client.Client
 	}
 	type args struct {
-		obj runtime.Object
+		obj *v1beta2.UnifiIPPool
 	}
 	tests := []struct {
 		name    string
@@ -259,3 +263,240 @@ func TestUnifiIPPoolWebhook_validateUpdate(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateSubnetOverlaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		subnets  []v1beta2.SubnetSpec
+		wantErrs int
+	}{
+		{
+			name:    "single subnet",
+			subnets: []v1beta2.SubnetSpec{{CIDR: "10.0.0.0/24"}},
+		},
+		{
+			name: "disjoint ranges in one CIDR",
+			subnets: []v1beta2.SubnetSpec{
+				{Start: "10.0.0.10", End: "10.0.0.19"},
+				{Start: "10.0.0.20", End: "10.0.0.29"},
+			},
+		},
+		{
+			name: "overlapping ranges",
+			subnets: []v1beta2.SubnetSpec{
+				{Start: "10.0.0.10", End: "10.0.0.20"},
+				{Start: "10.0.0.15", End: "10.0.0.25"},
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "third subnet overlaps both",
+			subnets: []v1beta2.SubnetSpec{
+				{Start: "10.0.0.10", End: "10.0.0.19"},
+				{Start: "10.0.0.20", End: "10.0.0.29"},
+				{CIDR: "10.0.0.0/24"},
+			},
+			wantErrs: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateSubnetOverlaps(&v1beta2.UnifiIPPoolSpec{Subnets: tt.subnets})
+			if len(got) != tt.wantErrs {
+				t.Errorf("validateSubnetOverlaps() = %v, want %d errors", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func Test_validateExcludeRange(t *testing.T) {
+	cidrSubnet := netipx.RangeOfPrefix(netip.MustParsePrefix("10.0.0.0/24"))
+	rangeSubnet := netipx.IPRangeFrom(netip.MustParseAddr("10.0.0.10"), netip.MustParseAddr("10.0.0.50"))
+	tests := []struct {
+		name         string
+		excludeRange string
+		subnet       netipx.IPRange
+		wantErrs     int
+	}{
+		{name: "IP in CIDR subnet", excludeRange: "10.0.0.5", subnet: cidrSubnet},
+		{name: "IP outside CIDR subnet", excludeRange: "10.0.1.5", subnet: cidrSubnet, wantErrs: 1},
+		{name: "CIDR overlapping subnet", excludeRange: "10.0.0.0/16", subnet: cidrSubnet},
+		{name: "CIDR outside subnet", excludeRange: "10.0.1.0/24", subnet: cidrSubnet, wantErrs: 1},
+		{name: "range in CIDR subnet", excludeRange: "10.0.0.1-10.0.0.99", subnet: cidrSubnet},
+		{name: "range crossing CIDR subnet", excludeRange: "10.0.0.200-10.0.1.10", subnet: cidrSubnet, wantErrs: 1},
+		{name: "range in start-end subnet", excludeRange: "10.0.0.10-10.0.0.20", subnet: rangeSubnet},
+		{name: "IP outside start-end subnet", excludeRange: "10.0.0.51", subnet: rangeSubnet, wantErrs: 1},
+		{name: "reversed range", excludeRange: "10.0.0.20-10.0.0.10", subnet: cidrSubnet, wantErrs: 1},
+		{name: "garbage", excludeRange: "10.0.0.x", subnet: cidrSubnet, wantErrs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateExcludeRange(tt.excludeRange, tt.subnet, field.NewPath("excludeRanges").Index(0))
+			if len(got) != tt.wantErrs {
+				t.Errorf("validateExcludeRange() = %v, want %d errors", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func Test_validateQuotas(t *testing.T) {
+	tests := []struct {
+		name     string
+		quotas   *v1beta2.QuotaSpec
+		wantErrs int
+	}{
+		{name: "no quotas"},
+		{
+			name: "valid selector",
+			quotas: &v1beta2.QuotaSpec{Selectors: []v1beta2.SelectorQuota{{
+				Name:     "workers",
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"role": "worker"}},
+				Max:      10,
+			}}},
+		},
+		{
+			name: "invalid selector operator",
+			quotas: &v1beta2.QuotaSpec{Selectors: []v1beta2.SelectorQuota{{
+				Name: "workers",
+				Selector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "role", Operator: "Matches", Values: []string{"worker"}},
+				}},
+				Max: 10,
+			}}},
+			wantErrs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateQuotas(&v1beta2.UnifiIPPoolSpec{Quotas: tt.quotas})
+			if len(got) != tt.wantErrs {
+				t.Errorf("validateQuotas() = %v, want %d errors", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func Test_validateHeadroom(t *testing.T) {
+	tests := []struct {
+		name     string
+		headroom *v1beta2.HeadroomSpec
+		wantErrs int
+	}{
+		{name: "no headroom"},
+		{
+			name: "valid selector",
+			headroom: &v1beta2.HeadroomSpec{
+				Reserved: 3,
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"cluster.x-k8s.io/control-plane": ""}},
+			},
+		},
+		{
+			name: "invalid selector",
+			headroom: &v1beta2.HeadroomSpec{
+				Reserved: 3,
+				Selector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "cluster.x-k8s.io/control-plane", Operator: metav1.LabelSelectorOpIn},
+				}},
+			},
+			wantErrs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateHeadroom(&v1beta2.UnifiIPPoolSpec{Headroom: tt.headroom})
+			if len(got) != tt.wantErrs {
+				t.Errorf("validateHeadroom() = %v, want %d errors", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func Test_validateThresholds(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	tests := []struct {
+		name       string
+		thresholds *v1beta2.UtilizationThresholds
+		wantErrs   int
+	}{
+		{name: "no thresholds"},
+		{name: "warning only", thresholds: &v1beta2.UtilizationThresholds{Warning: int32Ptr(95)}},
+		{name: "warning below critical", thresholds: &v1beta2.UtilizationThresholds{Warning: int32Ptr(60), Critical: int32Ptr(75)}},
+		{name: "equal thresholds", thresholds: &v1beta2.UtilizationThresholds{Warning: int32Ptr(75), Critical: int32Ptr(75)}},
+		{
+			name:       "warning above critical",
+			thresholds: &v1beta2.UtilizationThresholds{Warning: int32Ptr(90), Critical: int32Ptr(75)},
+			wantErrs:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateThresholds(&v1beta2.UnifiIPPoolSpec{UtilizationThresholds: tt.thresholds})
+			if len(got) != tt.wantErrs {
+				t.Errorf("validateThresholds() = %v, want %d errors", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func Test_validateSyncInterval(t *testing.T) {
+	tests := []struct {
+		name         string
+		syncInterval *metav1.Duration
+		wantErrs     int
+	}{
+		{name: "no sync interval"},
+		{name: "minimum interval", syncInterval: &metav1.Duration{Duration: time.Minute}},
+		{name: "interval too short", syncInterval: &metav1.Duration{Duration: 10 * time.Second}, wantErrs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateSyncInterval(&v1beta2.UnifiIPPoolSpec{SyncInterval: tt.syncInterval})
+			if len(got) != tt.wantErrs {
+				t.Errorf("validateSyncInterval() = %v, want %d errors", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func Test_overlapInputsChanged(t *testing.T) {
+	base := &v1beta2.UnifiIPPool{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pool"},
+		Spec: v1beta2.UnifiIPPoolSpec{
+			InstanceRef: corev1.ObjectReference{Name: "unifi", Namespace: "default"},
+			Subnets:     []v1beta2.SubnetSpec{{CIDR: "10.1.40.0/24"}},
+		},
+	}
+	tests := []struct {
+		name   string
+		modify func(pool *v1beta2.UnifiIPPool)
+		want   bool
+	}{
+		{name: "unchanged", modify: func(*v1beta2.UnifiIPPool) {}},
+		{name: "other fields", modify: func(pool *v1beta2.UnifiIPPool) {
+			pool.Spec.PreAllocations = map[string]string{"web-0": "10.1.40.10"}
+			pool.Finalizers = []string{"example.com/finalizer"}
+		}},
+		{name: "subnets", want: true, modify: func(pool *v1beta2.UnifiIPPool) {
+			pool.Spec.Subnets = []v1beta2.SubnetSpec{{CIDR: "10.1.41.0/24"}}
+		}},
+		{name: "instance", want: true, modify: func(pool *v1beta2.UnifiIPPool) {
+			pool.Spec.InstanceRef.Name = "other"
+		}},
+		{name: "network", want: true, modify: func(pool *v1beta2.UnifiIPPool) {
+			pool.Spec.NetworkID = "net-a"
+		}},
+		{name: "allow-overlap annotation", want: true, modify: func(pool *v1beta2.UnifiIPPool) {
+			pool.Annotations = map[string]string{allowOverlapAnnotation: "true"}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := base.DeepCopy()
+			tt.modify(updated)
+			if got := overlapInputsChanged(base, updated); got != tt.want {
+				t.Errorf("overlapInputsChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipamutil
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// Reasons of the IPAddressClaim's Ready condition in addition to the ones defined by Cluster API.
+const (
+	// RequestedIPConflictReason is used when the address requested or preallocated for a
+	// claim cannot be assigned to it.
+	RequestedIPConflictReason = "RequestedIPConflict"
+
+	// BackendUnavailableReason is used when the IPAM backend cannot be reached.
+	BackendUnavailableReason = "BackendUnavailable"
+)
+
+// ClaimError is returned by a ClaimHandler to explain why an address could not be
+// allocated. The ClaimReconciler reports Reason and the error message in the claim's
+// Ready condition. With a RequeueAfter the claim is retried after that delay,
+// otherwise the error is returned and the claim is retried with exponential backoff.
+type ClaimError struct {
+	Reason       string
+	RequeueAfter time.Duration
+	Err          error
+}
+
+// NewClaimError returns a ClaimError for the given Ready reason.
+func NewClaimError(reason string, requeueAfter time.Duration, err error) *ClaimError {
+	return &ClaimError{Reason: reason, RequeueAfter: requeueAfter, Err: err}
+}
+
+func (e *ClaimError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClaimError) Unwrap() error {
+	return e.Err
+}
+
+// setReadyCondition sets the Ready condition of the claim.
+func setReadyCondition(claim *ipamv1beta2.IPAddressClaim, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&claim.Status.Conditions, metav1.Condition{
+		Type:               ipamv1beta2.IPAddressClaimReadyCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: claim.Generation,
+	})
+}
+
+// claimErrorReason returns the Ready reason and requeue delay for an allocation error.
+// Errors that are not a ClaimError are reported as AllocationFailed.
+func claimErrorReason(err error) (string, time.Duration) {
+	var claimErr *ClaimError
+	if errors.As(err, &claimErr) {
+		return claimErr.Reason, claimErr.RequeueAfter
+	}
+	return ipamv1beta2.IPAddressClaimReadyAllocationFailedReason, 0
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipamutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+func TestClaimReconciler_handleAllocationError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantReason string
+		wantResult ctrl.Result
+		wantErr    bool
+	}{
+		{
+			name:       "claim error with backoff is requeued",
+			err:        errors.Wrap(NewClaimError(ipamv1beta2.IPAddressClaimReadyPoolExhaustedReason, time.Minute, fmt.Errorf("no free IPs")), "failed to create or patch address"),
+			wantReason: ipamv1beta2.IPAddressClaimReadyPoolExhaustedReason,
+			wantResult: ctrl.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:       "claim error without backoff is returned",
+			err:        NewClaimError(BackendUnavailableReason, 0, fmt.Errorf("connection refused")),
+			wantReason: BackendUnavailableReason,
+			wantErr:    true,
+		},
+		{
+			name:       "other errors are reported as allocation failures",
+			err:        fmt.Errorf("boom"),
+			wantReason: ipamv1beta2.IPAddressClaimReadyAllocationFailedReason,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ClaimReconciler{}
+			claim := &ipamv1beta2.IPAddressClaim{}
+
+			got, err := r.handleAllocationError(context.Background(), claim, tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handleAllocationError() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantResult {
+				t.Errorf("handleAllocationError() = %v, want %v", got, tt.wantResult)
+			}
+
+			condition := meta.FindStatusCondition(claim.Status.Conditions, ipamv1beta2.IPAddressClaimReadyCondition)
+			if condition == nil {
+				t.Fatal("Ready condition not set")
+			}
+			if condition.Status != metav1.ConditionFalse || condition.Reason != tt.wantReason {
+				t.Errorf("Ready condition = %s/%s, want False/%s", condition.Status, condition.Reason, tt.wantReason)
+			}
+			if condition.Message != tt.err.Error() {
+				t.Errorf("Ready condition message = %q, want %q", condition.Message, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestClaimReconciler_handlePoolFetchError(t *testing.T) {
+	r := &ClaimReconciler{}
+	claim := &ipamv1beta2.IPAddressClaim{}
+	err := NewClaimError("NamespaceNotAllowed", 0, fmt.Errorf("namespace team-b is not allowed"))
+
+	got, gotErr := r.handlePoolFetchError(context.Background(), claim, nil, err, nil)
+	if gotErr != nil {
+		t.Fatalf("handlePoolFetchError() error = %v, want the claim error to be terminal", gotErr)
+	}
+	if got != (ctrl.Result{}) {
+		t.Errorf("handlePoolFetchError() = %v, want no requeue", got)
+	}
+
+	condition := meta.FindStatusCondition(claim.Status.Conditions, ipamv1beta2.IPAddressClaimReadyCondition)
+	if condition == nil {
+		t.Fatal("Ready condition not set")
+	}
+	if condition.Status != metav1.ConditionFalse || condition.Reason != "NamespaceNotAllowed" {
+		t.Errorf("Ready condition = %s/%s, want False/NamespaceNotAllowed", condition.Status, condition.Reason)
+	}
+}
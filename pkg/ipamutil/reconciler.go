@@ -22,11 +22,14 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,6 +45,8 @@ import (
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+
+	"github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/pkg/tracing"
 )
 
 const (
@@ -60,6 +65,10 @@ type ClaimReconciler struct {
 	WatchFilterValue string
 
 	Adapter ProviderAdapter
+
+	// Recorder records allocation events on claims and addresses, if set. Releases are
+	// recorded by the handler, which knows what it released.
+	Recorder events.EventRecorder
 }
 
 // ProviderAdapter is an interface that must be implemented by the IPAM provider.
@@ -73,7 +82,9 @@ type ProviderAdapter interface {
 
 // ClaimHandler knows how to allocate and release IP addresses for a specific provider.
 type ClaimHandler interface {
-	// FetchPool is called to fetch the pool referenced by the claim.
+	// FetchPool is called to fetch the pool referenced by the claim. A ClaimError it
+	// returns is reported in the claim's Ready condition and only retried after its
+	// RequeueAfter, if set, or when the claim or pool changes.
 	FetchPool(ctx context.Context) (client.Object, *ctrl.Result, error)
 	// EnsureAddress is called to make sure that the IPAddress.Spec is correct and the address is allocated.
 	EnsureAddress(ctx context.Context, address *ipamv1beta2.IPAddress) (*ctrl.Result, error)
@@ -81,6 +92,14 @@ type ClaimHandler interface {
 	ReleaseAddress(ctx context.Context) (*ctrl.Result, error)
 }
 
+// AddressObserver is implemented by ClaimHandlers that act on the IPAddress once it has
+// been stored, e.g. to record events on it. Without it the ClaimReconciler records a
+// generic AddressCreated event.
+type AddressObserver interface {
+	// AddressCreated is called after the IPAddress allocated for the claim was created.
+	AddressCreated(ctx context.Context, address *ipamv1beta2.IPAddress)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClaimReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	if r.Adapter == nil {
@@ -124,6 +143,10 @@ func (r *ClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ct
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	ctx = tracing.WithAttributes(ctx, tracing.ClaimAttributes(claim)...)
+	ctx, span := tracing.Start(ctx, "ClaimReconciler.Reconcile")
+	defer func() { tracing.End(span, reterr) }()
+
 	if res, err := r.checkClusterPaused(ctx, claim); err != nil || res != nil {
 		return unwrapResult(res), err
 	}
@@ -186,7 +209,9 @@ func (r *ClaimReconciler) checkClusterPaused(ctx context.Context, claim *ipamv1b
 func (r *ClaimReconciler) reconcileNormal(ctx context.Context, claim *ipamv1beta2.IPAddressClaim, handler ClaimHandler) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
-	pool, res, err := handler.FetchPool(ctx)
+	fetchCtx, span := tracing.Start(ctx, "FetchPool")
+	pool, res, err := handler.FetchPool(fetchCtx)
+	tracing.End(span, err)
 	if err != nil || res != nil {
 		return r.handlePoolFetchError(ctx, claim, handler, err, res)
 	}
@@ -194,6 +219,7 @@ func (r *ClaimReconciler) reconcileNormal(ctx context.Context, claim *ipamv1beta
 	if pool == nil {
 		err := fmt.Errorf("pool is nil")
 		log.Error(err, "pool error")
+		setReadyCondition(claim, metav1.ConditionFalse, ipamv1beta2.IPAddressClaimReadyPoolNotReadyReason, "referenced pool could not be fetched")
 		return ctrl.Result{}, errors.Wrap(err, "reconciliation failed")
 	}
 
@@ -206,12 +232,20 @@ func (r *ClaimReconciler) reconcileNormal(ctx context.Context, claim *ipamv1beta
 
 	operationResult, err := r.createOrPatchAddress(ctx, &address, claim, pool, handler)
 	if err != nil {
-		return ctrl.Result{}, err
+		return r.handleAllocationError(ctx, claim, err)
 	}
 
 	if operationResult != controllerutil.OperationResultNone {
 		log.Info("IPAddress successfully created or patched", "operation", operationResult)
 	}
+	if operationResult == controllerutil.OperationResultCreated {
+		if observer, ok := handler.(AddressObserver); ok {
+			observer.AddressCreated(ctx, &address)
+		} else {
+			r.recordEvent(&address, claim, corev1.EventTypeNormal, "AddressCreated", "Allocate",
+				"Created with address %s for claim %s", address.Spec.Address, claim.Name)
+		}
+	}
 
 	if err := r.waitForAddressInCache(ctx, &address); err != nil {
 		log.Info("Address is not yet visible in cache, requeueing", "error", err)
@@ -223,18 +257,50 @@ func (r *ClaimReconciler) reconcileNormal(ctx context.Context, claim *ipamv1beta
 	}
 
 	claim.Status.AddressRef = ipamv1beta2.IPAddressReference{Name: address.Name}
+	setReadyCondition(claim, metav1.ConditionTrue, clusterv1beta2.ReadyReason, "")
 	return ctrl.Result{}, nil
 }
 
+// handleAllocationError reports a failed allocation in the claim's Ready condition
+// and backs off according to the failure reason. Handlers record the events of the
+// ClaimErrors they return, other errors get a generic warning event.
+func (r *ClaimReconciler) handleAllocationError(ctx context.Context, claim *ipamv1beta2.IPAddressClaim, err error) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	reason, requeueAfter := claimErrorReason(err)
+	setReadyCondition(claim, metav1.ConditionFalse, reason, err.Error())
+	var claimErr *ClaimError
+	if !errors.As(err, &claimErr) {
+		r.recordEvent(claim, nil, corev1.EventTypeWarning, reason, "Allocate", "Failed to allocate address: %s", err.Error())
+	}
+
+	if requeueAfter > 0 {
+		log.Info("Failed to allocate address, requeueing", "reason", reason, "requeueAfter", requeueAfter, "error", err.Error())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	return ctrl.Result{}, err
+}
+
 func (r *ClaimReconciler) handlePoolFetchError(ctx context.Context, claim *ipamv1beta2.IPAddressClaim, handler ClaimHandler, err error, res *ctrl.Result) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
+	var claimErr *ClaimError
+	if errors.As(err, &claimErr) {
+		log.Info("the referenced pool can not be used", "reason", claimErr.Reason, "error", err.Error())
+		setReadyCondition(claim, metav1.ConditionFalse, claimErr.Reason, err.Error())
+		if !claim.DeletionTimestamp.IsZero() {
+			return r.reconcileDelete(ctx, claim, handler)
+		}
+		return ctrl.Result{RequeueAfter: claimErr.RequeueAfter}, nil
+	}
+
 	if !apierrors.IsNotFound(err) {
 		return unwrapResult(res), errors.Wrap(err, "failed to fetch pool")
 	}
 
 	err = fmt.Errorf("pool not found: %w", err)
 	log.Error(err, "the referenced pool could not be found")
+	setReadyCondition(claim, metav1.ConditionFalse, ipamv1beta2.IPAddressClaimReadyPoolNotReadyReason, err.Error())
 
 	if !claim.DeletionTimestamp.IsZero() {
 		return r.reconcileDelete(ctx, claim, handler)
@@ -247,8 +313,11 @@ func (r *ClaimReconciler) createOrPatchAddress(ctx context.Context, address *ipa
 	var res *ctrl.Result
 
 	operationResult, err := controllerutil.CreateOrPatch(ctx, r.Client, address, func() error {
+		ensureCtx, span := tracing.Start(ctx, "EnsureAddress")
 		var err error
-		if res, err = handler.EnsureAddress(ctx, address); err != nil {
+		res, err = handler.EnsureAddress(ensureCtx, address)
+		tracing.End(span, err)
+		if err != nil {
 			return err
 		}
 
@@ -295,7 +364,10 @@ func (r *ClaimReconciler) waitForAddressInCache(ctx context.Context, address *ip
 }
 
 func (r *ClaimReconciler) reconcileDelete(ctx context.Context, claim *ipamv1beta2.IPAddressClaim, handler ClaimHandler) (ctrl.Result, error) {
-	if res, err := handler.ReleaseAddress(ctx); err != nil {
+	releaseCtx, span := tracing.Start(ctx, "ReleaseAddress")
+	res, err := handler.ReleaseAddress(releaseCtx)
+	tracing.End(span, err)
+	if err != nil {
 		return unwrapResult(res), fmt.Errorf("release address: %w", err)
 	}
 
@@ -307,6 +379,14 @@ func (r *ClaimReconciler) reconcileDelete(ctx context.Context, claim *ipamv1beta
 	return ctrl.Result{}, nil
 }
 
+// recordEvent emits an event if the reconciler has a recorder.
+func (r *ClaimReconciler) recordEvent(regarding, related runtime.Object, eventtype, reason, action, note string, args ...any) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(regarding, related, eventtype, reason, action, note, args...)
+}
+
 func (r *ClaimReconciler) deleteIPAddress(ctx context.Context, claim *ipamv1beta2.IPAddressClaim) error {
 	address := &ipamv1beta2.IPAddress{}
 	namespacedName := types.NamespacedName{
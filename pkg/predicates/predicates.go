@@ -43,22 +43,24 @@ func ResourceTransitionedToUnpaused() predicate.Predicate {
 func PoolNoLongerEmpty() predicate.Predicate {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			oldPool, oldOK := e.ObjectOld.(*v1beta2.UnifiIPPool)
-			newPool, newOK := e.ObjectNew.(*v1beta2.UnifiIPPool)
+			oldPool, oldOK := e.ObjectOld.(v1beta2.GenericUnifiIPPool)
+			newPool, newOK := e.ObjectNew.(v1beta2.GenericUnifiIPPool)
 
 			if !oldOK || !newOK {
 				return false
 			}
 
-			if oldPool.Status.Addresses == nil || newPool.Status.Addresses == nil {
+			oldAddresses := oldPool.PoolStatus().Addresses
+			newAddresses := newPool.PoolStatus().Addresses
+			if oldAddresses == nil || newAddresses == nil {
 				return false
 			}
-			if oldPool.Status.Addresses.Free == nil || newPool.Status.Addresses.Free == nil {
+			if oldAddresses.Free == nil || newAddresses.Free == nil {
 				return false
 			}
 
 			// Trigger if old had 0 free and new has > 0 free.
-			return *oldPool.Status.Addresses.Free == 0 && *newPool.Status.Addresses.Free > 0
+			return *oldAddresses.Free == 0 && *newAddresses.Free > 0
 		},
 	}
 }
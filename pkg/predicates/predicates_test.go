@@ -20,7 +20,11 @@ import (
 	"reflect"
 	"testing"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	v1beta2 "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi/api/v1beta2"
 )
 
 func TestResourceTransitionedToUnpaused(t *testing.T) {
@@ -70,3 +74,41 @@ func TestResourceNotPaused(t *testing.T) {
 		})
 	}
 }
+
+func TestPoolNoLongerEmpty_Update(t *testing.T) {
+	free := func(n int32) v1beta2.UnifiIPPoolStatus {
+		return v1beta2.UnifiIPPoolStatus{Addresses: &v1beta2.IPAddressStatusSummary{Free: &n}}
+	}
+	tests := []struct {
+		name   string
+		oldObj client.Object
+		newObj client.Object
+		want   bool
+	}{
+		{
+			name:   "namespaced pool freed",
+			oldObj: &v1beta2.UnifiIPPool{Status: free(0)},
+			newObj: &v1beta2.UnifiIPPool{Status: free(1)},
+			want:   true,
+		},
+		{
+			name:   "global pool freed",
+			oldObj: &v1beta2.GlobalUnifiIPPool{Status: free(0)},
+			newObj: &v1beta2.GlobalUnifiIPPool{Status: free(3)},
+			want:   true,
+		},
+		{
+			name:   "global pool still full",
+			oldObj: &v1beta2.GlobalUnifiIPPool{Status: free(0)},
+			newObj: &v1beta2.GlobalUnifiIPPool{Status: free(0)},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PoolNoLongerEmpty().Update(event.UpdateEvent{ObjectOld: tt.oldObj, ObjectNew: tt.newObj}); got != tt.want {
+				t.Errorf("PoolNoLongerEmpty().Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing provides OpenTelemetry tracing for the provider. Spans are recorded
+// with the global tracer provider, which does nothing until Setup installs an exporter.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// TracerName is the instrumentation name of the provider's spans.
+const TracerName = "github.com/ubiquiti-community/cluster-api-ipam-provider-unifi"
+
+// Attribute keys recorded on spans.
+const (
+	ClaimNameKey      = attribute.Key("ipam.claim.name")
+	ClaimNamespaceKey = attribute.Key("ipam.claim.namespace")
+	PoolKindKey       = attribute.Key("ipam.pool.kind")
+	PoolNamespaceKey  = attribute.Key("ipam.pool.namespace")
+	PoolNameKey       = attribute.Key("ipam.pool.name")
+	ClusterNameKey    = attribute.Key("capi.cluster.name")
+	UnifiOperationKey = attribute.Key("unifi.operation")
+	UnifiInstanceKey  = attribute.Key("unifi.instance")
+)
+
+// Options configure the export of spans.
+type Options struct {
+	// Endpoint is the host:port of the OTLP gRPC collector. Tracing is disabled if empty.
+	Endpoint string
+
+	// Insecure disables TLS towards the collector.
+	Insecure bool
+
+	// SampleRatio is the fraction of new traces that are sampled (0-1).
+	SampleRatio float64
+
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+}
+
+// Setup installs a global tracer provider exporting spans to the OTLP collector at
+// opts.Endpoint and returns a function flushing and stopping it. Without an endpoint
+// tracing stays disabled and the returned function does nothing.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	provider := NewTracerProvider(exporter, opts)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider returns a tracer provider batching spans to exporter, sampling
+// opts.SampleRatio of new traces and following the sampling decision of parent spans.
+func NewTracerProvider(exporter sdktrace.SpanExporter, opts Options) *sdktrace.TracerProvider {
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = "cluster-api-ipam-provider-unifi"
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+}
+
+type attributesKey struct{}
+
+// WithAttributes returns a context whose spans started with Start carry attrs in
+// addition to the attributes already in ctx.
+func WithAttributes(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	existing, _ := ctx.Value(attributesKey{}).([]attribute.KeyValue)
+	merged := make([]attribute.KeyValue, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attributesKey{}, merged)
+}
+
+// Start starts a span with the attributes of ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if attrs, ok := ctx.Value(attributesKey{}).([]attribute.KeyValue); ok {
+		opts = append(opts, trace.WithAttributes(attrs...))
+	}
+	return otel.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// End ends the span, recording err as its error status.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// RecordCall records a finished call to an external system started at start as a
+// client span, so instrumenting a call only needs its start time and outcome.
+func RecordCall(ctx context.Context, name string, start time.Time, err error, attrs ...attribute.KeyValue) {
+	_, span := Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(time.Now()))
+}
+
+// ClaimAttributes returns the attributes identifying a claim, its pool and cluster.
+func ClaimAttributes(claim *ipamv1beta2.IPAddressClaim) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		ClaimNamespaceKey.String(claim.Namespace),
+		ClaimNameKey.String(claim.Name),
+		PoolKindKey.String(claim.Spec.PoolRef.Kind),
+		PoolNameKey.String(claim.Spec.PoolRef.Name),
+	}
+	if cluster := claim.Labels[clusterv1beta2.ClusterNameLabel]; cluster != "" {
+		attrs = append(attrs, ClusterNameKey.String(cluster))
+	}
+	return attrs
+}
+
+// PoolAttributes returns the attributes identifying a pool.
+func PoolAttributes(kind, namespace, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		PoolKindKey.String(kind),
+		PoolNamespaceKey.String(namespace),
+		PoolNameKey.String(name),
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ipamv1beta2 "sigs.k8s.io/cluster-api/api/ipam/v1beta2"
+)
+
+// setupExporter installs a tracer provider recording spans in memory for the test.
+func setupExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func attributeValue(attrs []attribute.KeyValue, key attribute.Key) (string, bool) {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func TestStart(t *testing.T) {
+	exporter := setupExporter(t)
+
+	ctx := WithAttributes(context.Background(), ClaimNameKey.String("claim-1"))
+	ctx = WithAttributes(ctx, PoolNameKey.String("pool-1"))
+
+	ctx, parent := Start(ctx, "parent")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	childSpan, parentSpan := spans[0], spans[1]
+
+	if childSpan.Parent.SpanID() != parentSpan.SpanContext.SpanID() {
+		t.Errorf("child span is not a child of the parent span")
+	}
+	for _, span := range spans {
+		if got, _ := attributeValue(span.Attributes, ClaimNameKey); got != "claim-1" {
+			t.Errorf("span %s claim = %q, want claim-1", span.Name, got)
+		}
+		if got, _ := attributeValue(span.Attributes, PoolNameKey); got != "pool-1" {
+			t.Errorf("span %s pool = %q, want pool-1", span.Name, got)
+		}
+	}
+	if childSpan.Status.Code != codes.Error {
+		t.Errorf("child status = %v, want %v", childSpan.Status.Code, codes.Error)
+	}
+	if parentSpan.Status.Code != codes.Unset {
+		t.Errorf("parent status = %v, want %v", parentSpan.Status.Code, codes.Unset)
+	}
+}
+
+func TestRecordCall(t *testing.T) {
+	exporter := setupExporter(t)
+
+	start := time.Now().Add(-time.Second)
+	RecordCall(context.Background(), "unifi.ListUser", start, nil, UnifiOperationKey.String("ListUser"))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if !span.StartTime.Equal(start) {
+		t.Errorf("start time = %v, want %v", span.StartTime, start)
+	}
+	if span.EndTime.Sub(span.StartTime) < time.Second {
+		t.Errorf("duration = %v, want at least 1s", span.EndTime.Sub(span.StartTime))
+	}
+	if got, _ := attributeValue(span.Attributes, UnifiOperationKey); got != "ListUser" {
+		t.Errorf("operation = %q, want ListUser", got)
+	}
+}
+
+func TestClaimAttributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		wantCluster string
+		hasCluster  bool
+	}{
+		{name: "claim of a cluster", labels: map[string]string{clusterv1beta2.ClusterNameLabel: "prod"}, wantCluster: "prod", hasCluster: true},
+		{name: "claim without cluster"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &ipamv1beta2.IPAddressClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claim-1", Labels: tt.labels},
+				Spec: ipamv1beta2.IPAddressClaimSpec{
+					PoolRef: ipamv1beta2.IPPoolReference{Kind: "UnifiIPPool", Name: "pool-1"},
+				},
+			}
+			attrs := ClaimAttributes(claim)
+
+			if got, _ := attributeValue(attrs, PoolNameKey); got != "pool-1" {
+				t.Errorf("pool = %q, want pool-1", got)
+			}
+			got, ok := attributeValue(attrs, ClusterNameKey)
+			if ok != tt.hasCluster || got != tt.wantCluster {
+				t.Errorf("cluster = %q (%v), want %q (%v)", got, ok, tt.wantCluster, tt.hasCluster)
+			}
+		})
+	}
+}
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}